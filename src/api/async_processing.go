@@ -441,6 +441,7 @@ type PlayerFilter struct {
 	MinTransferValue int64
 	MaxTransferValue int64
 	MaxSalary        int64
+	Expression       *PlayerFilterExpression // Compiled ?filter= expression, nil when unset
 }
 
 // FilterResult holds filtered player and whether it matched
@@ -490,8 +491,8 @@ func (f *AsyncPlayerFilter) FilterPlayersAsync(ctx context.Context, players []Pl
 		return f.filterPlayersSync(players, filter)
 	}
 
-	// Process in chunks
-	resultCh := make(chan []Player, (len(players)/f.chunkSize)+1)
+	// Process in chunks, each writing its own slot so the result keeps the players' order
+	chunkResults := make([][]Player, (len(players)+f.chunkSize-1)/f.chunkSize)
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, f.workerCount)
 
@@ -505,7 +506,7 @@ func (f *AsyncPlayerFilter) FilterPlayersAsync(ctx context.Context, players []Pl
 		chunk := players[i:end]
 
 		wg.Add(1)
-		go func(playerChunk []Player) {
+		go func(index int, playerChunk []Player) {
 			defer wg.Done()
 
 			// Acquire semaphore
@@ -513,19 +514,14 @@ func (f *AsyncPlayerFilter) FilterPlayersAsync(ctx context.Context, players []Pl
 			defer func() { <-semaphore }()
 
 			// Filter this chunk
-			filtered := f.filterPlayersSync(playerChunk, filter)
-			resultCh <- filtered
-		}(chunk)
+			chunkResults[index] = f.filterPlayersSync(playerChunk, filter)
+		}(i/f.chunkSize, chunk)
 	}
-
-	go func() {
-		wg.Wait()
-		close(resultCh)
-	}()
+	wg.Wait()
 
 	// Collect results
 	var result []Player
-	for chunk := range resultCh {
+	for _, chunk := range chunkResults {
 		result = append(result, chunk...)
 	}
 
@@ -587,14 +583,15 @@ func (f *AsyncPlayerFilter) matchesFilter(player *Player, filter PlayerFilter) b
 	}
 
 	// Transfer value filter
-	if filter.MinTransferValue > 0 && player.TransferValueAmount < filter.MinTransferValue {
+	transferValue := playerTransferValueEstimate(player)
+	if filter.MinTransferValue > 0 && transferValue < filter.MinTransferValue {
 		return false
 	}
-	if filter.MaxTransferValue > 0 && player.TransferValueAmount > filter.MaxTransferValue {
+	if filter.MaxTransferValue > 0 && transferValue > filter.MaxTransferValue {
 		return false
 	}
 
-	// Salary filter
+	// Salary filter (wages are stored weekly)
 	if filter.MaxSalary > 0 && player.WageAmount > filter.MaxSalary {
 		return false
 	}
@@ -613,6 +610,10 @@ func (f *AsyncPlayerFilter) matchesFilter(player *Player, filter PlayerFilter) b
 		}
 	}
 
+	if filter.Expression != nil && !filter.Expression.Matches(player) {
+		return false
+	}
+
 	return true
 }
 
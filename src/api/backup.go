@@ -0,0 +1,552 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "api/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Layout of a backup archive: a gzipped tar holding one JSON file per stored record, the
+// duplicate-upload mappings and, last, a manifest with the checksum of every other file.
+const (
+	backupFormatVersion         = 1
+	backupManifestPath          = "manifest.json"
+	backupDuplicateMappingsPath = "duplicate-mappings.json"
+
+	// maxBackupArchiveSize bounds archives uploaded to the restore endpoint.
+	maxBackupArchiveSize = 8 << 30
+)
+
+// Kinds of archive entries
+const (
+	backupKindDataset           = "dataset"
+	backupKindCache             = "cache"
+	backupKindSave              = "save"
+	backupKindDuplicateMappings = "duplicate-mappings"
+)
+
+// BackupEntry describes one file of a backup archive.
+type BackupEntry struct {
+	Path        string `json:"path"`
+	Kind        string `json:"kind"`
+	DatasetID   string `json:"datasetId,omitempty"`
+	PlayerCount int    `json:"playerCount"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// BackupManifest is the last file of a backup archive and lists every other file.
+type BackupManifest struct {
+	FormatVersion     int           `json:"formatVersion"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	Datasets          int           `json:"datasets"`
+	CacheEntries      int           `json:"cacheEntries"`
+	Saves             int           `json:"saves"`
+	DuplicateMappings int           `json:"duplicateMappings"`
+	Entries           []BackupEntry `json:"entries"`
+}
+
+// backupKind classifies a stored record by its ID prefix.
+func backupKind(datasetID string) string {
+	switch {
+	case strings.HasPrefix(datasetID, cacheDatasetPrefix):
+		return backupKindCache
+	case strings.HasPrefix(datasetID, saveRecordPrefix):
+		return backupKindSave
+	default:
+		return backupKindDataset
+	}
+}
+
+// backupWriter streams files into a backup archive and records them for the manifest.
+type backupWriter struct {
+	gzip     *gzip.Writer
+	tar      *tar.Writer
+	modTime  time.Time
+	manifest BackupManifest
+}
+
+func (b *backupWriter) writeFile(path string, body []byte) error {
+	header := &tar.Header{Name: path, Mode: 0o644, Size: int64(len(body)), ModTime: b.modTime}
+	if err := b.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive header for %s: %w", path, err)
+	}
+	if _, err := b.tar.Write(body); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %w", path, err)
+	}
+	return nil
+}
+
+func (b *backupWriter) writeEntry(entry BackupEntry, body []byte) error {
+	sum := sha256.Sum256(body)
+	entry.Size = int64(len(body))
+	entry.SHA256 = hex.EncodeToString(sum[:])
+	if err := b.writeFile(entry.Path, body); err != nil {
+		return err
+	}
+	b.manifest.Entries = append(b.manifest.Entries, entry)
+	return nil
+}
+
+// writeBackup streams every record on source, including cache entries and save records, into
+// a backup archive on w. Protobuf-encoded datasets are written as JSON so the archive can be
+// restored to any backend. mappings are the live duplicate-upload mappings; mappings for
+// datasets whose metadata records a file hash are added when missing.
+func writeBackup(w io.Writer, source StorageInterface, mappings map[string]string) (BackupManifest, error) {
+	gzipWriter := gzip.NewWriter(w)
+	b := &backupWriter{
+		gzip:     gzipWriter,
+		tar:      tar.NewWriter(gzipWriter),
+		modTime:  time.Now().UTC().Truncate(time.Second),
+		manifest: BackupManifest{FormatVersion: backupFormatVersion, Entries: make([]BackupEntry, 0)},
+	}
+	b.manifest.CreatedAt = b.modTime
+
+	datasetIDs, err := source.List()
+	if err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to list datasets: %w", err)
+	}
+	sort.Strings(datasetIDs)
+
+	archivedMappings := make(map[string]string)
+	archived := make(map[string]bool, len(datasetIDs))
+	for _, datasetID := range datasetIDs {
+		data, _, err := readStoredDataset(source, datasetID)
+		if errors.Is(err, apperrors.ErrDatasetNotFound) {
+			continue // Deleted since it was listed
+		}
+		if err != nil {
+			return BackupManifest{}, fmt.Errorf("failed to read dataset %s: %w", datasetID, err)
+		}
+		body, err := json.Marshal(data)
+		if err != nil {
+			return BackupManifest{}, fmt.Errorf("failed to encode dataset %s: %w", datasetID, err)
+		}
+
+		kind := backupKind(datasetID)
+		entry := BackupEntry{
+			Path:        kind + "s/" + url.PathEscape(datasetID) + ".json",
+			Kind:        kind,
+			DatasetID:   datasetID,
+			PlayerCount: len(data.Players),
+		}
+		if err := b.writeEntry(entry, body); err != nil {
+			return BackupManifest{}, err
+		}
+		archived[datasetID] = true
+
+		switch kind {
+		case backupKindCache:
+			b.manifest.CacheEntries++
+		case backupKindSave:
+			b.manifest.Saves++
+		default:
+			b.manifest.Datasets++
+			if data.Metadata != nil && data.Metadata.FileHash != "" {
+				archivedMappings[data.Metadata.FileHash] = datasetID
+			}
+		}
+	}
+
+	// Mappings are only useful for datasets the archive restores
+	for fileHash, datasetID := range mappings {
+		if archived[datasetID] {
+			archivedMappings[fileHash] = datasetID
+		}
+	}
+	body, err := json.Marshal(archivedMappings)
+	if err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to encode duplicate mappings: %w", err)
+	}
+	if err := b.writeEntry(BackupEntry{Path: backupDuplicateMappingsPath, Kind: backupKindDuplicateMappings}, body); err != nil {
+		return BackupManifest{}, err
+	}
+	b.manifest.DuplicateMappings = len(archivedMappings)
+
+	body, err = json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := b.writeFile(backupManifestPath, body); err != nil {
+		return BackupManifest{}, err
+	}
+	if err := b.tar.Close(); err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := b.gzip.Close(); err != nil {
+		return BackupManifest{}, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return b.manifest, nil
+}
+
+// walkBackup calls visit for every file of the backup archive at path.
+func walkBackup(path string, visit func(header *tar.Header, body io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := visit(header, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// verifyBackup reads the whole archive at path and checks every file listed in the manifest
+// against its checksum, so a damaged archive is rejected before anything is restored.
+func verifyBackup(path string) (BackupManifest, error) {
+	var manifest *BackupManifest
+	checksums := make(map[string]string)
+	err := walkBackup(path, func(header *tar.Header, body io.Reader) error {
+		if header.Name == backupManifestPath {
+			manifest = &BackupManifest{}
+			if err := json.NewDecoder(body).Decode(manifest); err != nil {
+				return fmt.Errorf("failed to decode backup manifest: %w", err)
+			}
+			return nil
+		}
+		hash := sha256.New()
+		if _, err := io.Copy(hash, body); err != nil {
+			return fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		checksums[header.Name] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return BackupManifest{}, err
+	}
+
+	if manifest == nil {
+		return BackupManifest{}, apperrors.ErrBackupManifestMissing
+	}
+	if manifest.FormatVersion != backupFormatVersion {
+		return BackupManifest{}, apperrors.WrapErrUnsupportedBackupVersion(manifest.FormatVersion)
+	}
+	for _, entry := range manifest.Entries {
+		checksum, found := checksums[entry.Path]
+		if !found {
+			return BackupManifest{}, apperrors.WrapErrBackupEntryMissing(entry.Path)
+		}
+		if checksum != entry.SHA256 {
+			return BackupManifest{}, apperrors.WrapErrBackupChecksumMismatch(entry.Path)
+		}
+	}
+	return *manifest, nil
+}
+
+// restoreBackup verifies the archive at path, then passes every record it holds to store and
+// returns the archived duplicate-upload mappings.
+func restoreBackup(path string, store func(datasetID string, data DatasetData) error) (BackupManifest, map[string]string, error) {
+	manifest, err := verifyBackup(path)
+	if err != nil {
+		return BackupManifest{}, nil, err
+	}
+	entries := make(map[string]BackupEntry, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		entries[entry.Path] = entry
+	}
+
+	mappings := make(map[string]string)
+	err = walkBackup(path, func(header *tar.Header, body io.Reader) error {
+		entry, listed := entries[header.Name]
+		if !listed {
+			return nil // The manifest, or files a later format added
+		}
+		if entry.Kind == backupKindDuplicateMappings {
+			if err := json.NewDecoder(body).Decode(&mappings); err != nil {
+				return fmt.Errorf("failed to decode duplicate mappings: %w", err)
+			}
+			return nil
+		}
+
+		var data DatasetData
+		if err := json.NewDecoder(body).Decode(&data); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", entry.Path, err)
+		}
+		if data.Players == nil {
+			data.Players = []Player{}
+		}
+		if err := store(entry.DatasetID, data); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.DatasetID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return BackupManifest{}, nil, err
+	}
+	return manifest, mappings, nil
+}
+
+// snapshotDuplicateMappings copies the live duplicate-upload mappings.
+func snapshotDuplicateMappings() map[string]string {
+	hashMapMutex.RLock()
+	defer hashMapMutex.RUnlock()
+	mappings := make(map[string]string, len(fileHashToDatasetMap))
+	for fileHash, datasetID := range fileHashToDatasetMap {
+		mappings[fileHash] = datasetID
+	}
+	return mappings
+}
+
+// restoreDatasetRecord stores a restored record on the running server, replacing any copy it
+// holds in memory.
+func restoreDatasetRecord(datasetID string, data DatasetData) error {
+	if err := storeDatasetData(datasetID, data); err != nil {
+		return err
+	}
+	storeMutex.Lock()
+	delete(playerDataStore, datasetID)
+	storeMutex.Unlock()
+	if strings.HasPrefix(datasetID, saveRecordPrefix) {
+		resetDatasetSaveIndex()
+	}
+	return nil
+}
+
+// authorizeAdmin checks the bearer token of an admin request against ADMIN_TOKEN. Admin
+// endpoints are disabled when ADMIN_TOKEN is not set.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token := os.Getenv("ADMIN_TOKEN")
+	if token == "" {
+		http.Error(w, "Admin endpoints are disabled; set ADMIN_TOKEN to enable them", http.StatusForbidden)
+		return false
+	}
+	provided, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// backupHandler streams a backup archive of the running server:
+//
+//	GET /api/admin/backup
+func backupHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.admin.backup")
+	defer span.End()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	// Archives of large deployments take longer than the server's write timeout
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("fmdash-backup-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	manifest, err := writeBackup(w, storage, snapshotDuplicateMappings())
+	if err != nil {
+		// The archive is already streaming, so the client sees a truncated download
+		logError(ctx, "Backup failed", "error", err)
+		RecordError(ctx, err, "Backup failed")
+		return
+	}
+
+	SetSpanAttributes(ctx,
+		attribute.Int("backup.datasets", manifest.Datasets),
+		attribute.Int("backup.cache_entries", manifest.CacheEntries),
+		attribute.Int("backup.saves", manifest.Saves),
+	)
+	logInfo(ctx, "Backup completed", "datasets", manifest.Datasets, "cache_entries", manifest.CacheEntries, "saves", manifest.Saves)
+}
+
+// restoreHandler restores a backup archive sent as the request body into the running server.
+// Records in the archive replace stored records with the same ID; other records are kept.
+//
+//	POST /api/admin/restore
+func restoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.admin.restore")
+	defer span.End()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	// The archive is read twice, once to verify it and once to restore it
+	spool, err := os.CreateTemp("", "fmdash-restore-*.tar.gz")
+	if err != nil {
+		logError(ctx, "Failed to create restore spool file", "error", err)
+		http.Error(w, "Failed to receive archive", http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(spool.Name())
+	_, err = io.Copy(spool, http.MaxBytesReader(w, r.Body, maxBackupArchiveSize))
+	if closeErr := spool.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		http.Error(w, "Failed to receive archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	manifest, mappings, err := restoreBackup(spool.Name(), restoreDatasetRecord)
+	if err != nil {
+		logWarn(ctx, "Restore failed", "error", err)
+		RecordError(ctx, err, "Restore failed")
+		http.Error(w, "Restore failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for fileHash, datasetID := range mappings {
+		storeDuplicateMapping(fileHash, datasetID)
+	}
+	manifest.Entries = nil
+
+	SetSpanAttributes(ctx,
+		attribute.Int("restore.datasets", manifest.Datasets),
+		attribute.Int("restore.cache_entries", manifest.CacheEntries),
+		attribute.Int("restore.saves", manifest.Saves),
+	)
+	logInfo(ctx, "Restore completed", "datasets", manifest.Datasets, "cache_entries", manifest.CacheEntries, "saves", manifest.Saves)
+	writeDatasetsResponse(ctx, w, r, manifest)
+}
+
+// runBackupCommand implements "api backup", writing a backup archive of a storage backend:
+//
+//	api backup -from local:./datasets -out backup.tar.gz
+//
+// The server's in-memory duplicate mappings are not available to the command; mappings are
+// rebuilt from the file hashes in dataset metadata instead.
+func runBackupCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	from := flags.String("from", "", "storage to back up: local:DIR, hybrid:DIR, sqlite:PATH or s3:BUCKET")
+	out := flags.String("out", "", "archive file to write, or - for standard output")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *from == "" || *out == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: api backup -from STORAGE -out FILE")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	source, closeSource, err := openMigrationStorage(*from)
+	if err != nil {
+		fmt.Fprintf(stderr, "backup: %v\n", err)
+		return 2
+	}
+	defer closeSource()
+
+	archive, report := stdout, stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(stderr, "backup: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		archive = file
+	} else {
+		report = stderr
+	}
+
+	manifest, err := writeBackup(archive, source, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "backup: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(report, "Backed up %d datasets, %d cache entries, %d saves and %d duplicate mappings from %s\n",
+		manifest.Datasets, manifest.CacheEntries, manifest.Saves, manifest.DuplicateMappings, *from)
+	return 0
+}
+
+// runRestoreCommand implements "api restore", restoring a backup archive into a storage backend:
+//
+//	api restore -archive backup.tar.gz -to sqlite:./datasets/fmdash.db [-protobuf]
+//
+// Duplicate mappings live in the server's memory, so the command cannot restore them; restore
+// through the admin endpoint to keep duplicate upload detection for the archived datasets.
+func runRestoreCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	archive := flags.String("archive", "", "backup archive to restore")
+	to := flags.String("to", "", "storage to restore into: local:DIR, hybrid:DIR, sqlite:PATH or s3:BUCKET")
+	protobuf := flags.Bool("protobuf", false, "store datasets protobuf-encoded instead of as JSON-gzip")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *archive == "" || *to == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: api restore -archive FILE -to STORAGE [-protobuf]")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	destination, closeDestination, err := openMigrationStorage(*to)
+	if err != nil {
+		fmt.Fprintf(stderr, "restore: %v\n", err)
+		return 2
+	}
+	defer closeDestination()
+
+	writer := destination
+	if *protobuf {
+		if _, sqlDestination := destination.(*SQLStorage); sqlDestination {
+			fmt.Fprintln(stderr, "restore: -protobuf does not apply to sqlite storage, which stores players as rows")
+			return 2
+		}
+		writer = CreateProtobufStorage(destination)
+	}
+
+	manifest, mappings, err := restoreBackup(*archive, writer.Store)
+	if err != nil {
+		fmt.Fprintf(stderr, "restore: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Restored %d datasets, %d cache entries and %d saves to %s\n",
+		manifest.Datasets, manifest.CacheEntries, manifest.Saves, *to)
+	if len(mappings) > 0 {
+		fmt.Fprintf(stdout, "Skipped %d duplicate mappings, which only the admin restore endpoint can restore\n", len(mappings))
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apperrors "api/errors"
+)
+
+// writeTestArchive writes a backup of source to a file and returns its path.
+func writeTestArchive(t *testing.T, source StorageInterface, mappings map[string]string) (string, BackupManifest) {
+	t.Helper()
+	var archive bytes.Buffer
+	manifest, err := writeBackup(&archive, source, mappings)
+	if err != nil {
+		t.Fatalf("writeBackup failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := os.WriteFile(path, archive.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return path, manifest
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	source, _ := createMigrationSource(t)
+	metadata := &DatasetMetadata{Name: "Export", FileHash: "hash-of-export", UploadedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	if err := source.Store("backup-uploaded", DatasetData{Players: sqlTestPlayers()[:1], CurrencySymbol: "$", Metadata: metadata}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	if err := source.Store(saveRecordPrefix+"backup", DatasetData{Players: []Player{}, CacheData: `{"id":"backup"}`}); err != nil {
+		t.Fatalf("Failed to store save record: %v", err)
+	}
+
+	path, manifest := writeTestArchive(t, source, map[string]string{"hash-of-json": "migrate-json", "hash-of-deleted": "deleted"})
+	if manifest.Datasets != 3 || manifest.CacheEntries != 1 || manifest.Saves != 1 || manifest.DuplicateMappings != 2 {
+		t.Errorf("Unexpected manifest counts: %+v", manifest)
+	}
+
+	destination := createTestSQLStorage(t)
+	restored, mappings, err := restoreBackup(path, destination.Store)
+	if err != nil {
+		t.Fatalf("restoreBackup failed: %v", err)
+	}
+	if len(restored.Entries) != len(manifest.Entries) {
+		t.Errorf("Restored manifest lists %d entries, want %d", len(restored.Entries), len(manifest.Entries))
+	}
+	if mappings["hash-of-json"] != "migrate-json" || mappings["hash-of-export"] != "backup-uploaded" || mappings["hash-of-deleted"] != "" {
+		t.Errorf("Unexpected duplicate mappings: %v", mappings)
+	}
+
+	for _, datasetID := range []string{"migrate-json", "migrate-protobuf", "cache_migrate", "backup-uploaded", saveRecordPrefix + "backup"} {
+		want, _, err := readStoredDataset(source, datasetID)
+		if err != nil {
+			t.Fatalf("Failed to read source %s: %v", datasetID, err)
+		}
+		got, err := destination.Retrieve(datasetID)
+		if err != nil {
+			t.Errorf("%s was not restored: %v", datasetID, err)
+			continue
+		}
+		if datasetFingerprint(&got) != datasetFingerprint(&want) {
+			t.Errorf("%s changed in the round trip", datasetID)
+		}
+	}
+}
+
+func TestRestoreRejectsDamagedArchive(t *testing.T) {
+	source, _ := createMigrationSource(t)
+	path, _ := writeTestArchive(t, source, nil)
+
+	// Rewrite the archive with one dataset altered but the manifest unchanged
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	var damaged bytes.Buffer
+	gzipWriter := gzip.NewWriter(&damaged)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		body, _ := io.ReadAll(tarReader)
+		if header.Name == "datasets/migrate-json.json" {
+			body = bytes.Replace(body, []byte("Keeper"), []byte("Kieper"), 1)
+		}
+		header.Size = int64(len(body))
+		_ = tarWriter.WriteHeader(header)
+		_, _ = tarWriter.Write(body)
+	}
+	file.Close()
+	_ = tarWriter.Close()
+	_ = gzipWriter.Close()
+	if err := os.WriteFile(path, damaged.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write damaged archive: %v", err)
+	}
+
+	stored := 0
+	_, _, err = restoreBackup(path, func(string, DatasetData) error { stored++; return nil })
+	if !errors.Is(err, apperrors.ErrBackupChecksumMismatch) {
+		t.Errorf("Expected ErrBackupChecksumMismatch, got %v", err)
+	}
+	if stored != 0 {
+		t.Errorf("Expected nothing restored from a damaged archive, got %d records", stored)
+	}
+}
+
+func TestAdminBackupEndpoints(t *testing.T) {
+	InitStore()
+
+	t.Setenv("ADMIN_TOKEN", "")
+	w := httptest.NewRecorder()
+	backupHandler(w, httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d without ADMIN_TOKEN, got %d", http.StatusForbidden, w.Code)
+	}
+
+	t.Setenv("ADMIN_TOKEN", "secret")
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	backupHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a wrong token, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	if err := storeDatasetData("backup-endpoint-test", DatasetData{Players: sqlTestPlayers(), CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	t.Cleanup(func() { _ = DeleteDataset("backup-endpoint-test") })
+	storeDuplicateMapping("backup-endpoint-hash", "backup-endpoint-test")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	backupHandler(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("Expected a gzip archive, got status %d: %s", w.Code, w.Body.String())
+	}
+	archive := w.Body.Bytes()
+
+	if err := DeleteDataset("backup-endpoint-test"); err != nil {
+		t.Fatalf("Failed to delete dataset: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/restore", bytes.NewReader(archive))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	restoreHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if players, _, found := GetPlayerData("backup-endpoint-test"); !found || len(players) != 3 {
+		t.Errorf("Dataset was not restored: found %v with %d players", found, len(players))
+	}
+	if datasetID, found := checkForDuplicateUpload("backup-endpoint-hash"); !found || datasetID != "backup-endpoint-test" {
+		t.Errorf("Duplicate mapping was not restored: %q, %v", datasetID, found)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/restore", bytes.NewReader([]byte("not an archive")))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	restoreHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a bad archive, got %d", http.StatusBadRequest, w.Code)
+	}
+}
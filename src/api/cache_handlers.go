@@ -482,6 +482,7 @@ func handleSearchCache(w http.ResponseWriter, r *http.Request, cacheKey string)
 // PercentilesCacheKey represents the cache key for percentiles calculation
 type PercentilesCacheKey struct {
 	DatasetID      string `json:"datasetId"`
+	PlayerUID      int64  `json:"playerUid"`
 	PlayerName     string `json:"playerName"`
 	DivisionFilter string `json:"divisionFilter"`
 	TargetDivision string `json:"targetDivision"`
@@ -498,8 +499,8 @@ type PercentilesCacheData struct {
 }
 
 // generatePercentilesCacheKey generates a cache key for percentiles calculation
-func generatePercentilesCacheKey(ctx context.Context, datasetID, playerName, divisionFilter, targetDivision string, players []Player) string {
-	logDebug(ctx, "Generating percentiles cache key", "dataset_id", datasetID, "player_name", playerName, "player_count", len(players))
+func generatePercentilesCacheKey(ctx context.Context, datasetID string, player *Player, divisionFilter, targetDivision string, players []Player) string {
+	logDebug(ctx, "Generating percentiles cache key", "dataset_id", datasetID, "player_uid", player.UID, "player_count", len(players))
 	start := time.Now()
 
 	// Create a simple hash based on key parameters and dataset state
@@ -519,8 +520,9 @@ func generatePercentilesCacheKey(ctx context.Context, datasetID, playerName, div
 	}
 
 	// Simple hash function
-	cacheInput := fmt.Sprintf("%s:%s:%s:%s:%d:%s",
-		datasetID, playerName, divisionFilter, targetDivision, playerCount, samplePlayerData)
+	// The UID identifies the player; the name only tells apart exports without UIDs
+	cacheInput := fmt.Sprintf("%s:%d:%s:%s:%s:%d:%s",
+		datasetID, player.UID, player.Name, divisionFilter, targetDivision, playerCount, samplePlayerData)
 
 	hash := 0
 	for i := 0; i < len(cacheInput); i++ {
@@ -536,7 +538,7 @@ func generatePercentilesCacheKey(ctx context.Context, datasetID, playerName, div
 }
 
 // savePercentilesToCache saves percentiles calculation to cache
-func savePercentilesToCache(ctx context.Context, cacheKey, datasetID, playerName, divisionFilter, targetDivision string, players []Player, percentiles map[string]map[string]float64) {
+func savePercentilesToCache(ctx context.Context, cacheKey, datasetID string, player *Player, divisionFilter, targetDivision string, players []Player, percentiles map[string]map[string]float64) {
 	logInfo(ctx, "Starting percentiles cache save", "cache_key", cacheKey, "dataset_id", datasetID, "player_count", len(players))
 	start := time.Now()
 
@@ -545,7 +547,8 @@ func savePercentilesToCache(ctx context.Context, cacheKey, datasetID, playerName
 		GeneratedAt: time.Now(),
 		CacheKey: PercentilesCacheKey{
 			DatasetID:      datasetID,
-			PlayerName:     playerName,
+			PlayerUID:      player.UID,
+			PlayerName:     player.Name,
 			DivisionFilter: divisionFilter,
 			TargetDivision: targetDivision,
 			PlayerCount:    len(players),
@@ -582,7 +585,7 @@ func savePercentilesToCache(ctx context.Context, cacheKey, datasetID, playerName
 }
 
 // loadPercentilesFromCache loads percentiles calculation from cache
-func loadPercentilesFromCache(ctx context.Context, cacheKey, datasetID, playerName, divisionFilter, targetDivision string, players []Player) (map[string]map[string]float64, bool) {
+func loadPercentilesFromCache(ctx context.Context, cacheKey, datasetID string, player *Player, divisionFilter, targetDivision string, players []Player) (map[string]map[string]float64, bool) {
 	logInfo(ctx, "Starting percentiles cache load", "cache_key", cacheKey, "dataset_id", datasetID, "player_count", len(players))
 	start := time.Now()
 
@@ -613,7 +616,8 @@ func loadPercentilesFromCache(ctx context.Context, cacheKey, datasetID, playerNa
 	}
 
 	if cacheData.CacheKey.DatasetID != datasetID ||
-		cacheData.CacheKey.PlayerName != playerName ||
+		cacheData.CacheKey.PlayerUID != player.UID ||
+		cacheData.CacheKey.PlayerName != player.Name ||
 		cacheData.CacheKey.DivisionFilter != divisionFilter ||
 		cacheData.CacheKey.TargetDivision != targetDivision {
 		logDebug(ctx, "Percentiles cache key mismatch, recalculating", "cache_key", cacheKey)
@@ -194,27 +194,30 @@ var DetailedPositionGroupsForPercentiles = map[string][]string{
 	"Strikers":                        {"ST"},
 }
 
-// loadJSONWeights attempts to load weights from a JSON file.
-// If loading fails, it falls back to the provided default weights.
-func loadJSONWeights(filePath string, defaultWeights map[string]map[string]int) (map[string]map[string]int, error) {
+// validateConfigFilePath rejects configuration paths that are absolute, contain
+// traversal sequences or fall outside the allowed directories.
+func validateConfigFilePath(filePath string) error {
 	// Validate file path to prevent directory traversal
 	if strings.Contains(filePath, "..") || filepath.IsAbs(filePath) {
-		LogWarn("Invalid file path %s: contains path traversal or is absolute. Using default weights.", filePath)
-		return deepCopyWeights(defaultWeights), apperrors.ErrFilenamePathTraversal
+		return apperrors.ErrFilenamePathTraversal
 	}
 
 	// Additional validation: ensure path is within allowed directories
 	allowedDirs := []string{"public"}
-	pathIsAllowed := false
 	for _, allowedDir := range allowedDirs {
 		if strings.HasPrefix(filePath, allowedDir+"/") || filePath == allowedDir {
-			pathIsAllowed = true
-			break
+			return nil
 		}
 	}
-	if !pathIsAllowed {
-		LogWarn("Invalid file path %s: not in allowed directories. Using default weights.", filePath)
-		return deepCopyWeights(defaultWeights), apperrors.ErrFilenamePathTraversal
+	return apperrors.ErrFilenamePathTraversal
+}
+
+// loadJSONWeights attempts to load weights from a JSON file.
+// If loading fails, it falls back to the provided default weights.
+func loadJSONWeights(filePath string, defaultWeights map[string]map[string]int) (map[string]map[string]int, error) {
+	if err := validateConfigFilePath(filePath); err != nil {
+		LogWarn("Invalid file path %s: %v. Using default weights.", filePath, err)
+		return deepCopyWeights(defaultWeights), err
 	}
 
 	//nolint:gosec // filePath is validated above to be within allowed directories
@@ -338,7 +341,43 @@ func initializeConfigAsync() {
 		}
 	}()
 
-	// Wait for both file loads to complete
+	// Load localized header aliases asynchronously. The file is optional, so a failure
+	// only disables translation and does not count as a configuration error.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		loadedAliases, err := loadHeaderAliases(headerAliasesFile)
+		if err != nil {
+			return
+		}
+		setHeaderAliases(loadedAliases)
+	}()
+
+	// Load the FM export profiles asynchronously. Without them exports are still parsed,
+	// but version-specific columns are not mapped and every upload reports an unknown version.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		loadedProfiles, err := loadExportProfiles(exportProfilesFile)
+		if err != nil {
+			return
+		}
+		setExportProfiles(loadedProfiles)
+	}()
+
+	// Load the currency rate table asynchronously. Like the aliases it is optional; without
+	// it datasets still record their base currency but conversion requests are rejected.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		loadedRates, err := loadCurrencyRates(currencyRatesFile)
+		if err != nil {
+			return
+		}
+		setCurrencyRates(loadedRates)
+	}()
+
+	// Wait for all file loads to complete
 	wg.Wait()
 
 	// Precompute role weights after both loads complete
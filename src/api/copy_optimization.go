@@ -122,6 +122,21 @@ func (cow *CopyOnWritePlayer) deepCopyPlayer(original *Player) *Player {
 	player.BestRoleOverall = original.BestRoleOverall
 	player.TransferValueAmount = original.TransferValueAmount
 	player.WageAmount = original.WageAmount
+	player.WagePeriod = original.WagePeriod
+	player.TransferValueMin = original.TransferValueMin
+	player.TransferValueMax = original.TransferValueMax
+	player.TransferValueEstimate = original.TransferValueEstimate
+	player.AgeYears = original.AgeYears
+	player.HeightCm = original.HeightCm
+	player.WeightKg = original.WeightKg
+	player.PreferredFoot = original.PreferredFoot
+	player.LeftFoot = original.LeftFoot
+	player.RightFoot = original.RightFoot
+	player.ContractExpiry = original.ContractExpiry
+	player.DateOfBirth = original.DateOfBirth
+	player.OverallMin = original.OverallMin
+	player.OverallMax = original.OverallMax
+	player.RatingConfidence = original.RatingConfidence
 
 	// Copy maps efficiently
 	if original.Attributes != nil {
@@ -138,6 +153,11 @@ func (cow *CopyOnWritePlayer) deepCopyPlayer(original *Player) *Player {
 		}
 	}
 
+	player.AttributeMin = copyIntMap(original.AttributeMin)
+	player.AttributeMax = copyIntMap(original.AttributeMax)
+	player.StatMin = copyIntMap(original.StatMin)
+	player.StatMax = copyIntMap(original.StatMax)
+
 	if original.PerformanceStatsNumeric != nil {
 		player.PerformanceStatsNumeric = make(map[string]float64, len(original.PerformanceStatsNumeric))
 		for k, v := range original.PerformanceStatsNumeric {
@@ -180,6 +200,18 @@ func (cow *CopyOnWritePlayer) deepCopyPlayer(original *Player) *Player {
 	return player
 }
 
+// copyIntMap copies a map of ints, keeping nil maps nil so omitempty fields stay omitted.
+func copyIntMap(original map[string]int) map[string]int {
+	if original == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(original))
+	for k, v := range original {
+		copied[k] = v
+	}
+	return copied
+}
+
 // Object pool for Player structs to reduce GC pressure
 var playerPool = sync.Pool{
 	New: func() interface{} {
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	apperrors "api/errors"
+)
+
+// UploadFormat identifies the tabular export format of an uploaded player file.
+type UploadFormat int
+
+const (
+	// UploadFormatHTML is the native Football Manager "Print Screen" HTML export.
+	UploadFormatHTML UploadFormat = iota
+	// UploadFormatCSV is a comma (or semicolon) separated export, e.g. from a spreadsheet.
+	UploadFormatCSV
+	// UploadFormatTSV is a tab separated export.
+	UploadFormatTSV
+)
+
+// formatSniffLength is how many leading bytes are inspected when the extension is inconclusive.
+const formatSniffLength = 4096
+
+// utf8BOM is stripped from the start of CSV/TSV files saved by spreadsheet tools.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// String returns the lower-case name of the format, used in logs and metrics.
+func (f UploadFormat) String() string {
+	switch f {
+	case UploadFormatCSV:
+		return "csv"
+	case UploadFormatTSV:
+		return "tsv"
+	default:
+		return "html"
+	}
+}
+
+// detectUploadFormat determines the export format from the filename extension,
+// falling back to sniffing the first bytes of the content for unknown extensions.
+func detectUploadFormat(filename string, head []byte) UploadFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".html", ".htm":
+		return UploadFormatHTML
+	case ".csv":
+		return UploadFormatCSV
+	case ".tsv", ".tab":
+		return UploadFormatTSV
+	}
+
+	if len(head) > formatSniffLength {
+		head = head[:formatSniffLength]
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(head, utf8BOM))
+	if len(trimmed) == 0 || trimmed[0] == '<' {
+		return UploadFormatHTML
+	}
+
+	firstLine := trimmed
+	if idx := bytes.IndexByte(trimmed, '\n'); idx >= 0 {
+		firstLine = trimmed[:idx]
+	}
+	if bytes.Count(firstLine, []byte{'\t'}) > 0 {
+		return UploadFormatTSV
+	}
+	if bytes.Count(firstLine, []byte{','}) > 0 || bytes.Count(firstLine, []byte{';'}) > 0 {
+		return UploadFormatCSV
+	}
+
+	return UploadFormatHTML
+}
+
+// detectCSVDelimiter picks the field separator for a delimited export by counting
+// candidate separators in its header line. Spreadsheets in many European locales
+// write ';' instead of ',' because ',' is the decimal separator.
+func detectCSVDelimiter(format UploadFormat, headerLine []byte) rune {
+	if format == UploadFormatTSV {
+		return '\t'
+	}
+
+	if idx := bytes.IndexByte(headerLine, '\n'); idx >= 0 {
+		headerLine = headerLine[:idx]
+	}
+	if bytes.Count(headerLine, []byte{';'}) > bytes.Count(headerLine, []byte{','}) {
+		return ';'
+	}
+	return ','
+}
+
+// parsePlayerTable dispatches the upload stream to the parser for the detected format.
+// Every parser shares the same contract: it fills headersSnapshot with the headers as
+// they appear in the file, starts the PlayerParserWorker pool with the headers resolved
+// through resolveExportHeaders once they are known, and closes rowCellsChan when done.
+func parsePlayerTable(format UploadFormat, file io.Reader, headersSnapshot *[]string, rowCellsChan chan []string, numWorkers int, resultsChan chan<- PlayerParseResult, wg *sync.WaitGroup) error {
+	switch format {
+	case UploadFormatCSV, UploadFormatTSV:
+		return ParseCSVPlayerTable(file, format, headersSnapshot, rowCellsChan, numWorkers, resultsChan, wg)
+	default:
+		return ParseHTMLPlayerTable(file, headersSnapshot, rowCellsChan, numWorkers, resultsChan, wg)
+	}
+}
+
+// ParseCSVPlayerTable reads a CSV or TSV export, treating the first non-empty record
+// as the header row, and sends every following record to the PlayerParserWorker pool.
+// Headers are kept positionally (including blank ones) so cells stay aligned.
+func ParseCSVPlayerTable(file io.Reader, format UploadFormat, headersSnapshot *[]string, rowCellsChan chan []string, numWorkers int, resultsChan chan<- PlayerParseResult, wg *sync.WaitGroup) (processingError error) {
+	bufferedReader := bufio.NewReaderSize(file, optimalChunkSize)
+
+	// Drop a UTF-8 byte order mark so the first header matches "Name" exactly
+	if head, err := bufferedReader.Peek(len(utf8BOM)); err == nil && bytes.Equal(head, utf8BOM) {
+		if _, err := bufferedReader.Discard(len(utf8BOM)); err != nil {
+			close(rowCellsChan)
+			return apperrors.WrapErrParsingCSV(err)
+		}
+	}
+
+	// Short files return io.EOF alongside the partial data, which is all we need here
+	headerPeek, _ := bufferedReader.Peek(formatSniffLength)
+	reader := csv.NewReader(bufferedReader)
+	reader.Comma = detectCSVDelimiter(format, headerPeek)
+	reader.FieldsPerRecord = -1 // Spreadsheet exports frequently have ragged trailing columns
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true // Safe: sendRowWithBackpressure copies cells before sending
+
+	workersStarted := false
+
+recordLoop:
+	for {
+		record, err := reader.Read()
+		switch {
+		case err == io.EOF:
+			break recordLoop
+		case err != nil:
+			log.Printf("CSV parsing error occurred during parsing")
+			processingError = apperrors.WrapErrParsingCSV(err)
+			break recordLoop
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		if !workersStarted {
+			headers := make([]string, len(record))
+			for i, header := range record {
+				headers[i] = strings.TrimSpace(header)
+			}
+			*headersSnapshot = headers
+			workerHeaders, exportProfile := resolveExportHeaders(headers)
+			LogDebug("Headers found (%s header row, profile %s), launching %d workers with %d headers", format, exportProfile.Key, numWorkers, len(workerHeaders))
+			wg.Add(numWorkers)
+			for i := 0; i < numWorkers; i++ {
+				go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, workerHeaders)
+			}
+			workersStarted = true
+			continue
+		}
+
+		sendRowWithBackpressure(rowCellsChan, record, 5*time.Second)
+	}
+
+	close(rowCellsChan)
+
+	if !workersStarted && processingError == nil {
+		log.Printf("Warning: No workers were started during %s parsing, no header row found", format)
+		processingError = apperrors.ErrNoTableHeadersFound
+	}
+
+	return processingError
+}
+
+// isBlankRecord reports whether every field of a CSV record is empty or whitespace.
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDetectUploadFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		head     string
+		expected UploadFormat
+	}{
+		{name: "html extension", filename: "squad.html", head: "<html>", expected: UploadFormatHTML},
+		{name: "htm extension", filename: "squad.HTM", head: "", expected: UploadFormatHTML},
+		{name: "csv extension", filename: "squad.csv", head: "Name,Age", expected: UploadFormatCSV},
+		{name: "tsv extension", filename: "squad.tsv", head: "Name\tAge", expected: UploadFormatTSV},
+		{name: "sniff html", filename: "export.txt", head: "  <!DOCTYPE html><table>", expected: UploadFormatHTML},
+		{name: "sniff tsv", filename: "export.txt", head: "Name\tAge\tClub\nA\t20\tB", expected: UploadFormatTSV},
+		{name: "sniff csv", filename: "export", head: "Name,Age,Club\n", expected: UploadFormatCSV},
+		{name: "sniff semicolon csv", filename: "export", head: "\xEF\xBB\xBFName;Age;Club\n", expected: UploadFormatCSV},
+		{name: "empty defaults to html", filename: "", head: "", expected: UploadFormatHTML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectUploadFormat(tt.filename, []byte(tt.head)); got != tt.expected {
+				t.Errorf("detectUploadFormat(%q) = %v; want %v", tt.filename, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   UploadFormat
+		header   string
+		expected rune
+	}{
+		{name: "comma", format: UploadFormatCSV, header: "Name,Age,Club", expected: ','},
+		{name: "semicolon", format: UploadFormatCSV, header: "Name;Age;Transfer Value\n\"A\";20;\"£1,5M\"", expected: ';'},
+		{name: "tab", format: UploadFormatTSV, header: "Name,Age", expected: '\t'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCSVDelimiter(tt.format, []byte(tt.header)); got != tt.expected {
+				t.Errorf("detectCSVDelimiter() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// parseTableForTest runs a parser through the same channel/worker wiring as uploadHandler.
+func parseTableForTest(t *testing.T, format UploadFormat, content string) ([]Player, []string, error) {
+	t.Helper()
+
+	rowCellsChan := make(chan []string, 10)
+	resultsChan := make(chan PlayerParseResult, 10)
+	var wg sync.WaitGroup
+	var headers []string
+	var players []Player
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range resultsChan {
+			if result.Err == nil {
+				players = append(players, result.Player)
+			}
+		}
+	}()
+
+	err := parsePlayerTable(format, strings.NewReader(content), &headers, rowCellsChan, 2, resultsChan, &wg)
+	if len(headers) > 0 {
+		wg.Wait()
+	}
+	close(resultsChan)
+	<-done
+
+	return players, headers, err
+}
+
+func TestParseCSVPlayerTableMatchesHTML(t *testing.T) {
+	html := `<table><tr><th>Name</th><th>Age</th><th>Club</th><th>Position</th><th>Transfer Value</th><th>Fin</th><th>Mins</th></tr>
+<tr><td>Test Striker</td><td>23</td><td>Test FC</td><td>ST (C)</td><td>£1.5M</td><td>15</td><td>1,980</td></tr></table>`
+	csvContent := "\xEF\xBB\xBFName,Age,Club,Position,Transfer Value,Fin,Mins\n\n\"Test Striker\",23,Test FC,ST (C),£1.5M,15,\"1,980\"\n"
+	tsvContent := "Name\tAge\tClub\tPosition\tTransfer Value\tFin\tMins\nTest Striker\t23\tTest FC\tST (C)\t£1.5M\t15\t1,980\n"
+
+	htmlPlayers, _, err := parseTableForTest(t, UploadFormatHTML, html)
+	if err != nil || len(htmlPlayers) != 1 {
+		t.Fatalf("HTML parse returned %d players, err %v", len(htmlPlayers), err)
+	}
+	expected := htmlPlayers[0]
+
+	for _, tc := range []struct {
+		name    string
+		format  UploadFormat
+		content string
+	}{
+		{name: "csv", format: UploadFormatCSV, content: csvContent},
+		{name: "tsv", format: UploadFormatTSV, content: tsvContent},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			players, headers, err := parseTableForTest(t, tc.format, tc.content)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(headers) != 7 || headers[0] != "Name" {
+				t.Fatalf("unexpected headers: %v", headers)
+			}
+			if len(players) != 1 {
+				t.Fatalf("expected 1 player, got %d", len(players))
+			}
+
+			got := players[0]
+			if got.Name != expected.Name || got.Age != expected.Age || got.Club != expected.Club {
+				t.Errorf("identity fields differ: got %q/%q/%q, want %q/%q/%q",
+					got.Name, got.Age, got.Club, expected.Name, expected.Age, expected.Club)
+			}
+			if got.TransferValueAmount != expected.TransferValueAmount {
+				t.Errorf("TransferValueAmount = %d; want %d", got.TransferValueAmount, expected.TransferValueAmount)
+			}
+			if got.NumericAttributes["Fin"] != 15 {
+				t.Errorf("NumericAttributes[Fin] = %d; want 15", got.NumericAttributes["Fin"])
+			}
+			if got.PerformanceStatsNumeric["Mins"] != expected.PerformanceStatsNumeric["Mins"] {
+				t.Errorf("Mins = %v; want %v", got.PerformanceStatsNumeric["Mins"], expected.PerformanceStatsNumeric["Mins"])
+			}
+			if strings.Join(got.ShortPositions, ",") != strings.Join(expected.ShortPositions, ",") {
+				t.Errorf("ShortPositions = %v; want %v", got.ShortPositions, expected.ShortPositions)
+			}
+		})
+	}
+}
+
+func TestParseCSVPlayerTableNoHeaders(t *testing.T) {
+	_, headers, err := parseTableForTest(t, UploadFormatCSV, "\n ,  ,\n")
+	if err == nil {
+		t.Fatal("expected an error for a file without a header row")
+	}
+	if len(headers) != 0 {
+		t.Errorf("expected no headers, got %v", headers)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	apperrors "api/errors"
+)
+
+// currencyRatesFile is the local exchange rate table used to convert monetary amounts between
+// currencies. Rates are only ever read from disk; nothing is fetched over the network.
+var currencyRatesFile = filepath.Join("public", "currency_rates.json")
+
+// CurrencyRateTable holds exchange rates relative to a base currency, and the mapping from the
+// symbols FM displays to ISO currency codes.
+type CurrencyRateTable struct {
+	Base    string             `json:"base"`
+	Rates   map[string]float64 `json:"rates"`   // Units of each currency per one unit of Base
+	Symbols map[string]string  `json:"symbols"` // Display symbol -> ISO code
+}
+
+// defaultCurrencySymbols lets datasets record a base currency even when the rate table is missing.
+var defaultCurrencySymbols = map[string]string{
+	"€": "EUR", "£": "GBP", "$": "USD", "¥": "JPY", "₹": "INR", "₽": "RUB", "₺": "TRY", "₩": "KRW",
+	"R$": "BRL", "CHF": "CHF", "A$": "AUD", "CA$": "CAD", "Mex$": "MXN", "kr": "SEK", "zł": "PLN", "R": "ZAR",
+}
+
+var (
+	currencyRates   = CurrencyRateTable{Symbols: defaultCurrencySymbols}
+	muCurrencyRates sync.RWMutex
+)
+
+// loadCurrencyRates reads the rate table from a JSON file. Codes are upper-cased and rates
+// that are not positive are dropped.
+func loadCurrencyRates(filePath string) (CurrencyRateTable, error) {
+	if err := validateConfigFilePath(filePath); err != nil {
+		LogWarn("Invalid file path %s: %v. Currency conversion will be unavailable.", filePath, err)
+		return CurrencyRateTable{}, err
+	}
+
+	//nolint:gosec // filePath is validated above to be within allowed directories
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		LogWarn("Could not read %s: %v. Currency conversion will be unavailable.", filePath, err)
+		return CurrencyRateTable{}, err
+	}
+
+	var table CurrencyRateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		LogWarn("Could not unmarshal %s: %v. Currency conversion will be unavailable.", filePath, err)
+		return CurrencyRateTable{}, err
+	}
+
+	rates := make(map[string]float64, len(table.Rates))
+	for code, rate := range table.Rates {
+		if rate > 0 {
+			rates[strings.ToUpper(code)] = rate
+		}
+	}
+	symbols := make(map[string]string, len(table.Symbols))
+	for symbol, code := range table.Symbols {
+		symbols[symbol] = strings.ToUpper(code)
+	}
+	table.Base = strings.ToUpper(table.Base)
+	table.Rates = rates
+	table.Symbols = symbols
+
+	LogDebug("Successfully loaded %d currency rates from %s (base %s).", len(rates), filePath, table.Base)
+	return table, nil
+}
+
+// setCurrencyRates replaces the active rate table. A table without symbols keeps the defaults.
+func setCurrencyRates(table CurrencyRateTable) {
+	if len(table.Symbols) == 0 {
+		table.Symbols = defaultCurrencySymbols
+	}
+	muCurrencyRates.Lock()
+	defer muCurrencyRates.Unlock()
+	currencyRates = table
+}
+
+// currencyCodeForSymbol returns the ISO code for a dataset's currency symbol, or "" if unknown.
+func currencyCodeForSymbol(symbol string) string {
+	muCurrencyRates.RLock()
+	defer muCurrencyRates.RUnlock()
+	return currencyRates.Symbols[strings.TrimSpace(symbol)]
+}
+
+// currencySymbolForCode returns the display symbol for an ISO code. When several symbols map to
+// the same code the shortest wins, so "$" is preferred over "US$". Unmapped codes use the code.
+func currencySymbolForCode(code string) string {
+	muCurrencyRates.RLock()
+	defer muCurrencyRates.RUnlock()
+
+	var candidates []string
+	for symbol, symbolCode := range currencyRates.Symbols {
+		if symbolCode == code {
+			candidates = append(candidates, symbol)
+		}
+	}
+	if len(candidates) == 0 {
+		return code
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) < len(candidates[j])
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0]
+}
+
+// resolveCurrencyCode accepts an ISO code ("eur") or a symbol ("€") and returns the ISO code
+// if the rate table has a rate for it.
+func resolveCurrencyCode(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", false
+	}
+
+	muCurrencyRates.RLock()
+	defer muCurrencyRates.RUnlock()
+
+	code := strings.ToUpper(value)
+	if symbolCode, ok := currencyRates.Symbols[value]; ok {
+		code = symbolCode
+	}
+	if _, ok := currencyRates.Rates[code]; !ok {
+		return "", false
+	}
+	return code, true
+}
+
+// convertCurrencyAmount converts amount between two ISO codes via the table's base currency,
+// rounding to the nearest whole unit. It reports false if either code has no rate.
+func convertCurrencyAmount(amount int64, from, to string) (int64, bool) {
+	muCurrencyRates.RLock()
+	fromRate, fromOk := currencyRates.Rates[from]
+	toRate, toOk := currencyRates.Rates[to]
+	muCurrencyRates.RUnlock()
+
+	if !fromOk || !toOk {
+		return 0, false
+	}
+	if from == to || amount == 0 {
+		return amount, true
+	}
+	return int64(math.Round(float64(amount) / fromRate * toRate)), true
+}
+
+// convertPlayersCurrency returns copies of players with every monetary amount, and the
+// transfer value and wage display strings, converted from one ISO code to another.
+// The input slice is not modified, so cached players can be passed directly.
+func convertPlayersCurrency(players []Player, from, to string) ([]Player, bool) {
+	if _, ok := convertCurrencyAmount(0, from, to); !ok {
+		return nil, false
+	}
+	converted := make([]Player, len(players))
+	copy(converted, players)
+	if from == to {
+		return converted, true
+	}
+
+	symbol := currencySymbolForCode(to)
+	convert := func(amount int64) int64 {
+		result, _ := convertCurrencyAmount(amount, from, to)
+		return result
+	}
+	for i := range converted {
+		player := &converted[i]
+		player.TransferValueAmount = convert(player.TransferValueAmount)
+		player.TransferValueMin = convert(player.TransferValueMin)
+		player.TransferValueMax = convert(player.TransferValueMax)
+		player.TransferValueEstimate = convert(player.TransferValueEstimate)
+		player.WageAmount = convert(player.WageAmount)
+
+		if strings.ContainsAny(player.TransferValue, "0123456789") {
+			if player.TransferValueMin != player.TransferValueMax {
+				player.TransferValue = formatCurrencyAmount(symbol, player.TransferValueMin) + " - " + formatCurrencyAmount(symbol, player.TransferValueMax)
+			} else {
+				player.TransferValue = formatCurrencyAmount(symbol, player.TransferValueAmount)
+			}
+		}
+		// Wages are stored weekly, so the converted display is weekly too
+		if strings.ContainsAny(player.Wage, "0123456789") {
+			player.Wage = formatCurrencyAmount(symbol, player.WageAmount) + " p/w"
+		}
+	}
+	return converted, true
+}
+
+// formatCurrencyAmount renders an amount the way FM abbreviates it, e.g. "€4.5M" or "€12K".
+func formatCurrencyAmount(symbol string, amount int64) string {
+	switch {
+	case amount >= 999_500: // Would otherwise round to "1000K"
+		return symbol + strconv.FormatFloat(math.Round(float64(amount)/100_000)/10, 'f', -1, 64) + "M"
+	case amount >= 1_000:
+		return fmt.Sprintf("%s%dK", symbol, int64(math.Round(float64(amount)/1_000)))
+	}
+	return fmt.Sprintf("%s%d", symbol, amount)
+}
+
+// datasetBaseCurrency returns the ISO code a dataset's amounts are in: the base currency
+// recorded when it was stored, or the code of its symbol for datasets stored without one.
+func datasetBaseCurrency(datasetID, datasetSymbol string) string {
+	if details, found := cachedDatasetDetails(datasetID); found && details.BaseCurrency != "" {
+		return details.BaseCurrency
+	}
+	return currencyCodeForSymbol(datasetSymbol)
+}
+
+// resolveRequestCurrency validates a request's currency parameter against a dataset's base
+// currency and returns the dataset's base code and the requested target code. datasetSymbol
+// names the dataset's currency in errors.
+func resolveRequestCurrency(datasetSymbol, baseCurrency, requested string) (base, target string, err error) {
+	target, ok := resolveCurrencyCode(requested)
+	if !ok {
+		return "", "", apperrors.WrapErrUnsupportedCurrency(requested)
+	}
+	base, ok = resolveCurrencyCode(baseCurrency)
+	if !ok {
+		return "", "", apperrors.WrapErrDatasetCurrencyUnknown(datasetSymbol)
+	}
+	return base, target, nil
+}
+
+// convertPlayersForRequest applies a request's currency parameter to a dataset's players,
+// whose amounts are in baseCurrency, and returns them with the symbol their amounts are now
+// in. An empty parameter returns the players unchanged.
+func convertPlayersForRequest(players []Player, datasetSymbol, baseCurrency, requested string) ([]Player, string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return players, datasetSymbol, nil
+	}
+	base, target, err := resolveRequestCurrency(datasetSymbol, baseCurrency, requested)
+	if err != nil {
+		return nil, "", err
+	}
+	converted, _ := convertPlayersCurrency(players, base, target)
+	return converted, currencySymbolForCode(target), nil
+}
+
+// convertBargainResultsCurrency converts the players in bargain hunter results. Value scores
+// are ratios and stay as calculated in the dataset's currency.
+func convertBargainResultsCurrency(results []BargainHunterResponse, base, target string) []BargainHunterResponse {
+	players := make([]Player, len(results))
+	for i := range results {
+		players[i] = results[i].Player
+	}
+	converted, ok := convertPlayersCurrency(players, base, target)
+	if !ok {
+		return results
+	}
+	convertedResults := make([]BargainHunterResponse, len(results))
+	for i := range results {
+		convertedResults[i] = BargainHunterResponse{Player: converted[i], ValueScore: results[i].ValueScore}
+	}
+	return convertedResults
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+// withTestCurrencyRates installs a small rate table for the duration of a test.
+func withTestCurrencyRates(t *testing.T) {
+	t.Helper()
+	muCurrencyRates.RLock()
+	previous := currencyRates
+	muCurrencyRates.RUnlock()
+
+	setCurrencyRates(CurrencyRateTable{
+		Base:    "EUR",
+		Rates:   map[string]float64{"EUR": 1.0, "GBP": 0.8, "USD": 1.25},
+		Symbols: map[string]string{"€": "EUR", "£": "GBP", "$": "USD", "US$": "USD"},
+	})
+	t.Cleanup(func() { setCurrencyRates(previous) })
+}
+
+func TestLoadCurrencyRates(t *testing.T) {
+	table, err := loadCurrencyRates(currencyRatesFile)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", currencyRatesFile, err)
+	}
+	if table.Rates[table.Base] != 1.0 {
+		t.Errorf("Base currency %s should have a rate of 1, got %v", table.Base, table.Rates[table.Base])
+	}
+	for symbol, code := range table.Symbols {
+		if _, ok := table.Rates[code]; !ok {
+			t.Errorf("Symbol %q maps to %s, which has no rate", symbol, code)
+		}
+	}
+
+	if _, err := loadCurrencyRates("/etc/currency_rates.json"); err == nil {
+		t.Error("Expected paths outside public/ to be rejected")
+	}
+}
+
+func TestConvertCurrencyAmount(t *testing.T) {
+	withTestCurrencyRates(t)
+
+	tests := []struct {
+		amount   int64
+		from, to string
+		want     int64
+		wantOk   bool
+	}{
+		{1000000, "GBP", "EUR", 1250000, true},
+		{1000000, "GBP", "USD", 1562500, true},
+		{1250000, "EUR", "GBP", 1000000, true},
+		{500, "EUR", "EUR", 500, true},
+		{1000, "GBP", "JPY", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := convertCurrencyAmount(tt.amount, tt.from, tt.to)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("convertCurrencyAmount(%d, %s, %s) = %d, %v, want %d, %v", tt.amount, tt.from, tt.to, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestResolveCurrencyCode(t *testing.T) {
+	withTestCurrencyRates(t)
+
+	for input, want := range map[string]string{"eur": "EUR", "€": "EUR", "US$": "USD", " GBP ": "GBP"} {
+		if got, ok := resolveCurrencyCode(input); !ok || got != want {
+			t.Errorf("resolveCurrencyCode(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+	if _, ok := resolveCurrencyCode("JPY"); ok {
+		t.Error("Expected a code without a rate to be rejected")
+	}
+	if got := currencySymbolForCode("USD"); got != "$" {
+		t.Errorf("currencySymbolForCode(USD) = %q, want the shortest symbol", got)
+	}
+}
+
+func TestConvertPlayersCurrency(t *testing.T) {
+	withTestCurrencyRates(t)
+
+	players := []Player{
+		{Name: "Ranged", TransferValue: "£1.2M - £3.6M", TransferValueAmount: 3600000, TransferValueMin: 1200000,
+			TransferValueMax: 3600000, TransferValueEstimate: 2400000, Wage: "£40K p/m", WageAmount: 9231},
+		{Name: "Not For Sale", TransferValue: "Not for Sale", Wage: "-"},
+	}
+
+	converted, ok := convertPlayersCurrency(players, "GBP", "EUR")
+	if !ok {
+		t.Fatal("Expected GBP to EUR conversion to succeed")
+	}
+
+	ranged := converted[0]
+	if ranged.TransferValueMin != 1500000 || ranged.TransferValueMax != 4500000 || ranged.TransferValueEstimate != 3000000 {
+		t.Errorf("Unexpected converted range: %+v", ranged)
+	}
+	if ranged.WageAmount != 11539 {
+		t.Errorf("WageAmount = %d, want 11539", ranged.WageAmount)
+	}
+	if ranged.TransferValue != "€1.5M - €4.5M" || ranged.Wage != "€12K p/w" {
+		t.Errorf("Unexpected converted display: %q, %q", ranged.TransferValue, ranged.Wage)
+	}
+	if converted[1].TransferValue != "Not for Sale" || converted[1].Wage != "-" {
+		t.Errorf("Displays without amounts should be kept, got %q, %q", converted[1].TransferValue, converted[1].Wage)
+	}
+	if players[0].TransferValueEstimate != 2400000 || players[0].TransferValue != "£1.2M - £3.6M" {
+		t.Error("Input players should not be modified")
+	}
+}
+
+func TestConvertPlayersForRequestErrors(t *testing.T) {
+	withTestCurrencyRates(t)
+
+	if _, _, err := convertPlayersForRequest(nil, "£", "GBP", "XYZ"); !errors.Is(err, apperrors.ErrUnsupportedCurrency) {
+		t.Errorf("Expected ErrUnsupportedCurrency, got %v", err)
+	}
+	if _, _, err := convertPlayersForRequest(nil, "₩", "", "EUR"); !errors.Is(err, apperrors.ErrDatasetCurrencyUnknown) {
+		t.Errorf("Expected ErrDatasetCurrencyUnknown, got %v", err)
+	}
+	players := []Player{{Name: "Unchanged", TransferValueAmount: 100}}
+	if got, symbol, err := convertPlayersForRequest(players, "£", "GBP", ""); err != nil || symbol != "£" || got[0].TransferValueAmount != 100 {
+		t.Errorf("Expected no conversion without a currency parameter, got %v %q %v", got, symbol, err)
+	}
+}
+
+func TestPlayersEndpointCurrencyParameter(t *testing.T) {
+	InitStore()
+	withTestCurrencyRates(t)
+
+	datasetID := "currency-conversion-test"
+	SetPlayerData(datasetID, []Player{
+		{UID: 1, Name: "Cheap", TransferValue: "£800K", TransferValueAmount: 800000, TransferValueMin: 800000,
+			TransferValueMax: 800000, TransferValueEstimate: 800000, Wage: "£8K p/w", WageAmount: 8000},
+		{UID: 2, Name: "Expensive", TransferValue: "£4M", TransferValueAmount: 4000000, TransferValueMin: 4000000,
+			TransferValueMax: 4000000, TransferValueEstimate: 4000000, Wage: "£40K p/w", WageAmount: 40000},
+	}, "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	// The filter is read in euros: £800K is €1M and passes, £4M is €5M and does not
+	req := httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?currency=EUR&maxTransferValue=2000000", nil)
+	w := httptest.NewRecorder()
+	playerDataHandler(w, req.WithContext(context.Background()))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PlayerDataWithCurrency
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.CurrencySymbol != "€" || response.BaseCurrency != "GBP" {
+		t.Errorf("Expected € amounts from a GBP dataset, got %q from %q", response.CurrencySymbol, response.BaseCurrency)
+	}
+	if len(response.Players) != 1 || response.Players[0].Name != "Cheap" {
+		t.Fatalf("Expected only the cheap player, got %+v", response.Players)
+	}
+	if response.Players[0].TransferValueAmount != 1000000 || response.Players[0].WageAmount != 10000 {
+		t.Errorf("Amounts were not converted: %+v", response.Players[0])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?currency=XYZ", nil)
+	w = httptest.NewRecorder()
+	playerDataHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unsupported currency, got %d", w.Code)
+	}
+}
+
+func TestPlayersEndpointUsesStoredBaseCurrency(t *testing.T) {
+	InitStore()
+	withTestCurrencyRates(t)
+
+	// The symbol reads as dollars today, but the dataset recorded pounds when it was stored
+	datasetID := "stored-base-currency-test"
+	if err := storeDatasetData(datasetID, DatasetData{
+		Players:        []Player{{UID: 1, Name: "Recorded", TransferValue: "$800K", TransferValueAmount: 800000}},
+		CurrencySymbol: "$",
+		BaseCurrency:   "GBP",
+	}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	w := httptest.NewRecorder()
+	playerDataHandler(w, httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?currency=EUR", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PlayerDataWithCurrency
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.BaseCurrency != "GBP" || len(response.Players) != 1 || response.Players[0].TransferValueAmount != 1000000 {
+		t.Errorf("Expected £800K converted from GBP to €1M, got %q %+v", response.BaseCurrency, response.Players)
+	}
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	apperrors "api/errors"
+)
+
+// maxMergeReportConflicts caps how many individual conflicts are echoed back to the uploader.
+const maxMergeReportConflicts = 50
+
+// maxMergeUploadFiles caps how many views of a save can be merged in one request. Each file
+// is still held to the single-upload limit; the request body may carry up to this many.
+const maxMergeUploadFiles = 8
+
+// MergeReport describes how the files of a merge upload were joined on Player.UID.
+// PlayersAdded counts UIDs not seen in an earlier file or in the target dataset.
+type MergeReport struct {
+	FilesMerged       int             `json:"filesMerged"`
+	TargetDatasetID   string          `json:"targetDatasetId,omitempty"`
+	PlayersMatched    int             `json:"playersMatched"`
+	PlayersAdded      int             `json:"playersAdded"`
+	PlayersWithoutUID int             `json:"playersWithoutUid"`
+	ConflictCount     int             `json:"conflictCount"`
+	Conflicts         []MergeConflict `json:"conflicts,omitempty"`
+}
+
+// MergeConflict records a field that two files disagreed on for the same player.
+// The value from the later file wins, since it is usually the more recent export.
+type MergeConflict struct {
+	UID       int64  `json:"uid"`
+	Name      string `json:"name"`
+	Field     string `json:"field"`
+	Kept      string `json:"kept"`
+	Discarded string `json:"discarded"`
+}
+
+// uploadMergeOptions captures the merge-related query parameters of an upload request.
+type uploadMergeOptions struct {
+	Enabled         bool
+	TargetDatasetID string
+}
+
+// parseUploadMergeOptions reads ?mode=merge and ?datasetId=... from the upload URL.
+// Naming a target dataset implies merge mode.
+func parseUploadMergeOptions(query url.Values) (uploadMergeOptions, error) {
+	options := uploadMergeOptions{
+		Enabled:         query.Get("mode") == "merge",
+		TargetDatasetID: query.Get("datasetId"),
+	}
+	if options.TargetDatasetID != "" {
+		if err := validateID(options.TargetDatasetID, 100); err != nil {
+			return options, err
+		}
+		options.Enabled = true
+	}
+	return options, nil
+}
+
+// recordConflict counts a conflict and keeps it in the sample if there is room.
+func (r *MergeReport) recordConflict(conflict MergeConflict) {
+	r.ConflictCount++
+	if len(r.Conflicts) < maxMergeReportConflicts {
+		r.Conflicts = append(r.Conflicts, conflict)
+	}
+}
+
+// mergePlayersByUID folds incoming players into base. Players sharing a non-zero UID are
+// merged field by field; everything else is appended. Players without a UID cannot be
+// joined and are always appended. The returned slice may share storage with base.
+func mergePlayersByUID(base, incoming []Player, report *MergeReport) []Player {
+	indexByUID := make(map[int64]int, len(base))
+	for i := range base {
+		if base[i].UID != 0 {
+			indexByUID[base[i].UID] = i
+		}
+	}
+
+	for i := range incoming {
+		player := incoming[i]
+		if player.UID == 0 {
+			report.PlayersWithoutUID++
+			base = append(base, player)
+			continue
+		}
+		if idx, found := indexByUID[player.UID]; found {
+			mergePlayerInto(&base[idx], &player, report)
+			report.PlayersMatched++
+			continue
+		}
+		indexByUID[player.UID] = len(base)
+		base = append(base, player)
+		report.PlayersAdded++
+	}
+	return base
+}
+
+// mergePlayerInto copies the parsed columns of src onto dst. Empty and fully masked ("-")
+// values never overwrite real data and do not count as conflicts.
+func mergePlayerInto(dst, src *Player, report *MergeReport) {
+	mergeField := func(field string, dstValue *string, srcValue string) bool {
+		if isMissingMergeValue(srcValue) || *dstValue == srcValue {
+			return false
+		}
+		if !isMissingMergeValue(*dstValue) {
+			report.recordConflict(MergeConflict{UID: dst.UID, Name: dst.Name, Field: field, Kept: srcValue, Discarded: *dstValue})
+		}
+		*dstValue = srcValue
+		return true
+	}
+
+	mergeField("Name", &dst.Name, src.Name)
+	mergeField("Position", &dst.Position, src.Position)
+	mergeField("Age", &dst.Age, src.Age)
+	mergeField("Club", &dst.Club, src.Club)
+	mergeField("Division", &dst.Division, src.Division)
+	mergeField("Personality", &dst.Personality, src.Personality)
+	mergeField("Media Handling", &dst.MediaHandling, src.MediaHandling)
+	if mergeField("Transfer Value", &dst.TransferValue, src.TransferValue) {
+		dst.TransferValueAmount = src.TransferValueAmount
+		dst.TransferValueMin = src.TransferValueMin
+		dst.TransferValueMax = src.TransferValueMax
+		dst.TransferValueEstimate = src.TransferValueEstimate
+	}
+	if mergeField("Wage", &dst.Wage, src.Wage) {
+		dst.WageAmount = src.WageAmount
+		dst.WagePeriod = src.WagePeriod
+	}
+	if mergeField("Nationality", &dst.Nationality, src.Nationality) {
+		dst.NationalityISO = src.NationalityISO
+		dst.NationalityFIFACode = src.NationalityFIFACode
+	}
+
+	if dst.Attributes == nil {
+		dst.Attributes = make(map[string]string, len(src.Attributes))
+	}
+	for key, value := range src.Attributes {
+		current := dst.Attributes[key]
+		mergeField(key, &current, value)
+		dst.Attributes[key] = current
+	}
+}
+
+// isMissingMergeValue reports whether a cell carries no usable information for merging.
+func isMissingMergeValue(value string) bool {
+	return value == "" || value == "-"
+}
+
+// clonePlayersForMerge copies stored players so merging does not mutate data that
+// readers of the dataset may still hold. Only the maps mergePlayerInto writes are copied.
+func clonePlayersForMerge(players []Player) []Player {
+	cloned := make([]Player, len(players))
+	for i := range players {
+		cloned[i] = players[i]
+		cloned[i].Attributes = make(map[string]string, len(players[i].Attributes))
+		for k, v := range players[i].Attributes {
+			cloned[i].Attributes[k] = v
+		}
+	}
+	return cloned
+}
+
+// reenhanceMergedPlayers recomputes derived values from the merged attribute maps. Stale
+// numeric maps and percentiles are dropped first so no value from a single file survives.
+func reenhanceMergedPlayers(players []Player) {
+	for i := range players {
+		players[i].NumericAttributes = make(map[string]int, len(players[i].Attributes))
+		players[i].PerformanceStatsNumeric = make(map[string]float64, len(PerformanceStatKeys))
+		players[i].PerformancePercentiles = make(map[string]map[string]float64)
+		players[i].RoleSpecificOveralls = nil
+		players[i].AttributeMasked = false
+		EnhancePlayerWithCalculations(&players[i])
+	}
+}
+
+// storeMergedUpload joins the parsed files on UID, optionally on top of an existing dataset,
+// re-runs the calculations and stores the result. Percentiles are left to the caller.
+func storeMergedUpload(ctx context.Context, files []*parsedUpload, options uploadMergeOptions, job *UploadJob) (UploadResponse, *apperrors.AppError) {
+	ctx, span := StartSpan(ctx, "upload.merge")
+	defer span.End()
+	job.SetPhase(UploadPhaseCalculating)
+
+	report := &MergeReport{FilesMerged: len(files), TargetDatasetID: options.TargetDatasetID}
+	var merged []Player
+	var existingMetadata *DatasetMetadata
+	currencySymbol := ""
+
+	if options.TargetDatasetID != "" {
+		existingPlayers, existingCurrency, found := GetPlayerData(options.TargetDatasetID)
+		if !found {
+			return UploadResponse{}, apperrors.CreateNotFoundError("Dataset to merge into was not found.")
+		}
+		merged = clonePlayersForMerge(existingPlayers)
+		currencySymbol = existingCurrency
+		if metadata, found := GetDatasetMetadata(options.TargetDatasetID); found {
+			existingMetadata = &metadata
+		}
+	}
+
+	for _, file := range files {
+		merged = mergePlayersByUID(merged, file.Players, report)
+	}
+	reenhanceMergedPlayers(merged)
+
+	if currencySymbol == "" {
+		currencySymbol = detectDatasetCurrency(merged)
+	}
+
+	datasetID := options.TargetDatasetID
+	if datasetID == "" {
+		datasetID = uuid.New().String()
+	}
+
+	SetSpanAttributes(ctx,
+		attribute.String("dataset.id", datasetID),
+		attribute.Int("merge.files", report.FilesMerged),
+		attribute.Int("merge.players_matched", report.PlayersMatched),
+		attribute.Int("merge.conflicts", report.ConflictCount),
+	)
+
+	job.SetPhase(UploadPhaseStoring)
+	SetDatasetAsync(datasetID, DatasetData{
+		Players:        merged,
+		CurrencySymbol: currencySymbol,
+		ExportProfile:  mergedExportProfileKey(files),
+		Metadata:       createMergedUploadMetadata(files, existingMetadata, len(merged)),
+	})
+	if options.TargetDatasetID != "" {
+		// The dataset no longer matches the file it was created from, and cached
+		// views must not outlive the merge until the async store invalidates them.
+		removeDuplicateMapping(datasetID)
+		invalidateDatasetCache(datasetID)
+	}
+
+	logInfo(ctx, "Merged upload stored",
+		"dataset_id", datasetID,
+		"files", report.FilesMerged,
+		"players", len(merged),
+		"matched", report.PlayersMatched,
+		"added", report.PlayersAdded,
+		"conflicts", report.ConflictCount)
+
+	RecordBusinessOperation(ctx, "merge_upload", true, map[string]interface{}{
+		"dataset_id":      datasetID,
+		"files_merged":    report.FilesMerged,
+		"players_matched": report.PlayersMatched,
+		"players_added":   report.PlayersAdded,
+		"conflicts":       report.ConflictCount,
+		"players_total":   len(merged),
+	})
+
+	response := UploadResponse{
+		DatasetID:              datasetID,
+		Message:                "Files merged into dataset successfully.",
+		DetectedCurrencySymbol: currencySymbol,
+		MergeReport:            report,
+	}
+	if len(files) == 1 {
+		response.ParseReport = &files[0].Report
+	}
+	return response, nil
+}
+
+// mergedExportProfileKey returns the export profile recorded for a merge. Files of different
+// views from the same FM version make a mixed view; files from different versions record none.
+func mergedExportProfileKey(files []*parsedUpload) string {
+	if len(files) == 0 {
+		return ""
+	}
+	first := files[0].Report.ExportProfile
+	view := first.View
+	for _, file := range files[1:] {
+		profile := file.Report.ExportProfile
+		if profile.FMVersion != first.FMVersion {
+			return ""
+		}
+		if profile.View != view {
+			view = ExportViewMixed
+		}
+	}
+	return exportProfileKey(first.FMVersion, view)
+}
+
+// spooledMergeFile is one file of an async merge upload, copied to disk before the job runs.
+type spooledMergeFile struct {
+	Filename string
+	Path     string
+	Size     int64
+}
+
+// handleMergeUpload processes an upload with ?mode=merge or ?datasetId=..., where every
+// playerFile part is a different view of the same save. Files are parsed one after another
+// and joined on UID. Duplicate-file detection is skipped, since the merged dataset is not
+// the product of any single file.
+func handleMergeUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, multipartReader *multipart.Reader, options uploadMergeOptions) {
+	startTime := time.Now()
+	SetSpanAttributes(ctx,
+		attribute.Bool("upload.merge", true),
+		attribute.String("upload.merge_target", options.TargetDatasetID),
+	)
+
+	if options.TargetDatasetID != "" {
+		if _, _, found := GetPlayerData(options.TargetDatasetID); !found {
+			http.Error(w, "Dataset to merge into was not found.", http.StatusNotFound)
+			return
+		}
+	}
+
+	if isAsyncUploadRequested(r) {
+		startAsyncMergeUpload(ctx, w, r, multipartReader, options)
+		return
+	}
+
+	var files []*parsedUpload
+	for {
+		filePart, err := nextUploadFilePart(multipartReader, uploadFormField)
+		if errors.Is(err, apperrors.ErrUploadFieldMissing) && len(files) > 0 {
+			break
+		}
+		if err != nil {
+			RecordError(ctx, err, "Failed to retrieve uploaded file")
+			http.Error(w, "Error retrieving the file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(files) == maxMergeUploadFiles {
+			closeMergeFilePart(ctx, filePart)
+			http.Error(w, fmt.Sprintf("A merge upload can contain at most %d files.", maxMergeUploadFiles), http.StatusBadRequest)
+			return
+		}
+
+		parsed, appErr := parseUploadSource(ctx, filePart.FileName(), filePart, 0, nil)
+		closeMergeFilePart(ctx, filePart)
+		if appErr != nil {
+			http.Error(w, appErr.Message, appErr.HTTPStatus)
+			return
+		}
+		files = append(files, parsed...)
+	}
+	if len(files) > maxMergeUploadFiles {
+		http.Error(w, fmt.Sprintf("A merge upload can contain at most %d files.", maxMergeUploadFiles), http.StatusBadRequest)
+		return
+	}
+
+	response, appErr := storeMergedUpload(ctx, files, options, nil)
+	if appErr != nil {
+		http.Error(w, appErr.Message, appErr.HTTPStatus)
+		return
+	}
+	SetSpanAttributes(ctx, attribute.Int64("upload.merge_duration_ms", time.Since(startTime).Milliseconds()))
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		RecordError(ctx, err, "Failed to encode JSON response")
+		log.Printf("Error encoding JSON response for merge upload: %v", err)
+		return
+	}
+
+	go calculateUploadPercentiles(response.DatasetID, nil)
+}
+
+// startAsyncMergeUpload spools every file part to disk and merges them in a background job.
+func startAsyncMergeUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, multipartReader *multipart.Reader, options uploadMergeOptions) {
+	var files []spooledMergeFile
+	removeSpooled := func() {
+		for _, file := range files {
+			if removeErr := os.Remove(file.Path); removeErr != nil {
+				log.Printf("Failed to remove temporary upload file %s: %v", file.Path, removeErr)
+			}
+		}
+	}
+
+	var bytesTotal int64
+	for {
+		filePart, err := nextUploadFilePart(multipartReader, uploadFormField)
+		if errors.Is(err, apperrors.ErrUploadFieldMissing) && len(files) > 0 {
+			break
+		}
+		if err != nil {
+			removeSpooled()
+			RecordError(ctx, err, "Failed to retrieve uploaded file")
+			http.Error(w, "Error retrieving the file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(files) == maxMergeUploadFiles {
+			closeMergeFilePart(ctx, filePart)
+			removeSpooled()
+			http.Error(w, fmt.Sprintf("A merge upload can contain at most %d files.", maxMergeUploadFiles), http.StatusBadRequest)
+			return
+		}
+
+		tempPath, size, err := spoolUploadToTempFile(filePart)
+		closeMergeFilePart(ctx, filePart)
+		if err != nil {
+			removeSpooled()
+			if errors.Is(err, apperrors.ErrUploadTooLarge) {
+				appErr := rejectOversizedUpload(ctx, filePart.FileName())
+				http.Error(w, appErr.Message, appErr.HTTPStatus)
+				return
+			}
+			RecordError(ctx, err, "Failed to spool upload for background processing")
+			http.Error(w, "Error reading the file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		files = append(files, spooledMergeFile{Filename: filePart.FileName(), Path: tempPath, Size: size})
+		bytesTotal += size
+	}
+
+	filenames := make([]string, len(files))
+	for i, file := range files {
+		filenames[i] = file.Filename
+	}
+	job := CreateUploadJob(strings.Join(filenames, ", "), bytesTotal)
+	SetSpanAttributes(ctx, attribute.String("upload.job_id", job.ID))
+	logInfo(ctx, "Merge upload accepted as background job",
+		"job_id", job.ID,
+		"files", len(files),
+		"size_bytes", bytesTotal)
+
+	go runMergeUploadJob(job, files, options)
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	w.WriteHeader(http.StatusAccepted)
+	response := UploadJobResponse{
+		JobID:     job.ID,
+		Phase:     UploadPhaseQueued,
+		StatusURL: "/api/upload-jobs/" + job.ID,
+		Message:   "Files accepted for background merging.",
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		RecordError(ctx, err, "Failed to encode upload job response")
+	}
+}
+
+// runMergeUploadJob parses and merges spooled files in the background, removing them when done.
+func runMergeUploadJob(job *UploadJob, files []spooledMergeFile, options uploadMergeOptions) {
+	defer func() {
+		for _, file := range files {
+			if removeErr := os.Remove(file.Path); removeErr != nil {
+				log.Printf("Failed to remove temporary upload file %s: %v", file.Path, removeErr)
+			}
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			LogCritical("Merge upload job %s PANICKED: %v", job.ID, r)
+			job.Fail("Internal error while merging the upload.")
+		}
+	}()
+
+	ctx, span := StartSpan(context.Background(), "upload.merge_job")
+	defer span.End()
+	SetSpanAttributes(ctx,
+		attribute.String("upload.job_id", job.ID),
+		attribute.Int("merge.files", len(files)),
+		attribute.Int64("file.size", job.BytesTotal),
+	)
+
+	parsedFiles := make([]*parsedUpload, 0, len(files))
+	for _, file := range files {
+		parsed, appErr := parseSpooledMergeFile(ctx, file, job)
+		if appErr != nil {
+			job.Fail(appErr.Message)
+			return
+		}
+		parsedFiles = append(parsedFiles, parsed...)
+	}
+	if len(parsedFiles) > maxMergeUploadFiles {
+		job.Fail(fmt.Sprintf("A merge upload can contain at most %d files.", maxMergeUploadFiles))
+		return
+	}
+
+	response, appErr := storeMergedUpload(ctx, parsedFiles, options, job)
+	if appErr != nil {
+		job.Fail(appErr.Message)
+		return
+	}
+	job.SetResult(response)
+
+	calculateUploadPercentiles(response.DatasetID, job)
+	job.Complete()
+
+	logInfo(ctx, "Merge upload job completed",
+		"job_id", job.ID,
+		"dataset_id", response.DatasetID,
+		"duration_ms", time.Since(job.CreatedAt).Milliseconds())
+}
+
+// parseSpooledMergeFile parses one spooled file of a background merge, which yields one
+// export per member when the file is a zip archive.
+func parseSpooledMergeFile(ctx context.Context, file spooledMergeFile, job *UploadJob) ([]*parsedUpload, *apperrors.AppError) {
+	source, err := os.Open(file.Path) // #nosec G304 -- path comes from os.CreateTemp in spoolUploadToTempFile
+	if err != nil {
+		RecordError(ctx, err, "Failed to open spooled upload")
+		return nil, apperrors.CreateInternalError("Error reading the uploaded file.")
+	}
+	defer func() {
+		if closeErr := source.Close(); closeErr != nil {
+			RecordError(ctx, closeErr, "Failed to close spooled upload")
+		}
+	}()
+	return parseUploadSource(ctx, file.Filename, source, file.Size, job)
+}
+
+// closeMergeFilePart closes a multipart file part, recording but otherwise ignoring failures.
+func closeMergeFilePart(ctx context.Context, filePart *multipart.Part) {
+	if closeErr := filePart.Close(); closeErr != nil {
+		RecordError(ctx, closeErr, "Failed to close uploaded file")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+)
+
+func TestMergePlayersByUID(t *testing.T) {
+	base := []Player{
+		{UID: 1, Name: "Keeper", Club: "Old FC", Attributes: map[string]string{"Ref": "15", "Han": "-"}},
+		{UID: 2, Name: "Striker", Club: "Old FC", Attributes: map[string]string{"Fin": "16"}},
+	}
+	incoming := []Player{
+		{UID: 1, Name: "Keeper", Club: "New FC", Attributes: map[string]string{"Han": "14", "Ref": "-"}},
+		{UID: 3, Name: "Winger", Club: "New FC", Attributes: map[string]string{"Cro": "13"}},
+		{UID: 0, Name: "Trialist", Attributes: map[string]string{}},
+	}
+
+	report := &MergeReport{}
+	merged := mergePlayersByUID(base, incoming, report)
+
+	if len(merged) != 4 {
+		t.Fatalf("Expected 4 players after merge, got %d", len(merged))
+	}
+	if report.PlayersMatched != 1 || report.PlayersAdded != 1 || report.PlayersWithoutUID != 1 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+
+	keeper := merged[0]
+	if keeper.Club != "New FC" {
+		t.Errorf("Expected later file to win the Club conflict, got %q", keeper.Club)
+	}
+	if keeper.Attributes["Han"] != "14" {
+		t.Errorf("Expected masked Han to be filled in, got %q", keeper.Attributes["Han"])
+	}
+	if keeper.Attributes["Ref"] != "15" {
+		t.Errorf("Expected masked incoming Ref not to override 15, got %q", keeper.Attributes["Ref"])
+	}
+
+	if report.ConflictCount != 1 || len(report.Conflicts) != 1 {
+		t.Fatalf("Expected exactly one conflict, got %+v", report.Conflicts)
+	}
+	conflict := report.Conflicts[0]
+	if conflict.UID != 1 || conflict.Field != "Club" || conflict.Kept != "New FC" || conflict.Discarded != "Old FC" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+}
+
+func TestMergeUploadIntoNewAndExistingDataset(t *testing.T) {
+	InitStore()
+
+	attributesView := "Name,UID,Club,Position,Fin,Pac,Dri\n" +
+		"Merge Forward,900001,Merge FC,ST (C),16,15,14\n" +
+		"Merge Back,900002,Merge FC,D (R),8,14,10\n"
+	statsView := "Name,UID,Club,Mins,Gls\n" +
+		"Merge Forward,900001,Merge FC,2700,21\n" +
+		"Merge Keeper,900003,Merge FC,3000,0\n"
+
+	response := postMergeUpload(t, "/api/upload?mode=merge", map[string]string{
+		"attributes.csv": attributesView,
+		"stats.csv":      statsView,
+	})
+	if response.MergeReport == nil {
+		t.Fatal("Expected a merge report in the response")
+	}
+	if response.MergeReport.FilesMerged != 2 || response.MergeReport.PlayersMatched != 1 {
+		t.Errorf("Unexpected merge report: %+v", response.MergeReport)
+	}
+
+	players, _, found := GetPlayerData(response.DatasetID)
+	if !found {
+		t.Fatalf("Merged dataset %s not found", response.DatasetID)
+	}
+	if len(players) != 3 {
+		t.Fatalf("Expected 3 merged players, got %d", len(players))
+	}
+	forward := players[0]
+	if forward.UID != 900001 || forward.Attributes["Fin"] != "16" || forward.Attributes["Gls"] != "21" {
+		t.Errorf("Expected attributes and stats on one player, got %+v", forward.Attributes)
+	}
+	if forward.PerformanceStatsNumeric["Mins"] != 2700 {
+		t.Errorf("Expected merged stats to be recalculated, got %v", forward.PerformanceStatsNumeric)
+	}
+
+	update := "Name,UID,Club\n" +
+		"Merge Back,900002,Transfer FC\n"
+	updated := postMergeUpload(t, "/api/upload?datasetId="+response.DatasetID, map[string]string{
+		"update.csv": update,
+	})
+	if updated.DatasetID != response.DatasetID {
+		t.Errorf("Expected merge into %s, got %s", response.DatasetID, updated.DatasetID)
+	}
+
+	players, _, _ = GetPlayerData(response.DatasetID)
+	if len(players) != 3 || players[1].Club != "Transfer FC" || players[1].Attributes["Pac"] != "14" {
+		t.Errorf("Expected club update on an otherwise unchanged player, got %+v", players[1])
+	}
+}
+
+func TestMergeUploadIntoMissingDataset(t *testing.T) {
+	InitStore()
+
+	body, contentType := buildMergeUploadBody(t, map[string]string{"view.csv": "Name,UID\nNobody,1\n"})
+	req := httptest.NewRequest("POST", "/api/upload?datasetId=does-not-exist", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	uploadHandler(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+}
+
+func postMergeUpload(t *testing.T, target string, files map[string]string) UploadResponse {
+	t.Helper()
+
+	body, contentType := buildMergeUploadBody(t, files)
+	req := httptest.NewRequest("POST", target, body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	uploadHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response UploadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse upload response: %v", err)
+	}
+	return response
+}
+
+// buildMergeUploadBody writes files as playerFile parts in a stable (sorted) order.
+func buildMergeUploadBody(t *testing.T, files map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range names {
+		fileWriter, err := writer.CreateFormFile(uploadFormField, name)
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		if _, err := fileWriter.Write([]byte(files[name])); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close multipart writer: %v", err)
+	}
+	return &buf, writer.FormDataContentType()
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	apperrors "api/errors"
+)
+
+// Limits on the user-editable parts of dataset metadata.
+const (
+	maxDatasetNameLength        = 120
+	maxDatasetDescriptionLength = 2000
+	maxDatasetTags              = 20
+	maxDatasetTagLength         = 40
+	maxDatasetMetadataBodySize  = 64 << 10
+)
+
+// DatasetMetadata describes where a dataset came from and what the user calls it.
+type DatasetMetadata struct {
+	Name             string    `json:"name"`
+	Description      string    `json:"description,omitempty"`
+	OriginalFilename string    `json:"originalFilename,omitempty"` // Comma-separated for merged uploads
+	UploadedAt       time.Time `json:"uploadedAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	PlayerCount      int       `json:"playerCount"`
+	FMVersion        string    `json:"fmVersion,omitempty"`
+	FileHash         string    `json:"fileHash,omitempty"` // Empty once the dataset no longer matches a single file
+	Tags             []string  `json:"tags,omitempty"`
+}
+
+// DatasetMetadataUpdate is the body of a metadata edit. Omitted fields are left unchanged;
+// an empty name resets it to the name derived from the original filename.
+type DatasetMetadataUpdate struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Tags        *[]string `json:"tags"`
+}
+
+// datasetDetails are the parts of a stored dataset that describe the upload rather than the
+// players, along with counts summarizing the players. They are kept in memory so player-only
+// updates, such as storing percentiles, do not drop them, and are persisted as a details record
+// so datasets can be listed and summarized without loading every player.
+type datasetDetails struct {
+	ExportProfile  string           `json:"exportProfile,omitempty"`
+	CurrencySymbol string           `json:"currencySymbol"`
+	BaseCurrency   string           `json:"baseCurrency,omitempty"` // ISO code of the dataset's amounts, recorded at upload time
+	Metadata       *DatasetMetadata `json:"metadata,omitempty"`     // Treated as immutable; edits store a new copy
+	Counts         datasetCounts    `json:"counts"`
+}
+
+// metadata returns the dataset's metadata. Datasets stored before metadata was recorded get
+// one holding only their player count.
+func (d datasetDetails) metadata() DatasetMetadata {
+	if d.Metadata == nil {
+		return DatasetMetadata{PlayerCount: d.Counts.Players}
+	}
+	return *d.Metadata
+}
+
+// datasetCounts summarizes a dataset's players for the dataset summary.
+type datasetCounts struct {
+	Players        int            `json:"players"`
+	Clubs          int            `json:"clubs"`
+	Divisions      int            `json:"divisions"`
+	Nationalities  int            `json:"nationalities"`
+	PositionGroups map[string]int `json:"positionGroups"` // Players per position group
+}
+
+// datasetDetailsRecordPrefix prefixes the storage IDs of persisted details records. It falls
+// under cacheDatasetPrefix, so listings, backups and migrations treat the records as cache entries.
+const datasetDetailsRecordPrefix = cacheDatasetPrefix + "dataset_details_"
+
+// countDatasetPlayers counts the clubs, divisions, nationalities and position groups of players.
+func countDatasetPlayers(players []Player) datasetCounts {
+	clubs := make(map[string]bool)
+	divisions := make(map[string]bool)
+	nationalities := make(map[string]bool)
+	positionGroups := make(map[string]int)
+	for i := range players {
+		player := &players[i]
+		if player.Club != "" {
+			clubs[player.Club] = true
+		}
+		if player.Division != "" {
+			divisions[player.Division] = true
+		}
+		if player.Nationality != "" {
+			nationalities[player.Nationality] = true
+		}
+		for _, group := range player.PositionGroups {
+			positionGroups[group]++
+		}
+	}
+	return datasetCounts{
+		Players:        len(players),
+		Clubs:          len(clubs),
+		Divisions:      len(divisions),
+		Nationalities:  len(nationalities),
+		PositionGroups: positionGroups,
+	}
+}
+
+// detailsFromDataset returns the details of a full dataset.
+func detailsFromDataset(data DatasetData) datasetDetails {
+	return datasetDetails{
+		ExportProfile:  data.ExportProfile,
+		CurrencySymbol: data.CurrencySymbol,
+		BaseCurrency:   data.BaseCurrency,
+		Metadata:       data.Metadata,
+		Counts:         countDatasetPlayers(data.Players),
+	}
+}
+
+var (
+	datasetDetailsStore = make(map[string]datasetDetails)
+	muDatasetDetails    sync.RWMutex
+
+	// muDatasetMetadataEdits serializes metadata edits, which read, modify and store the dataset.
+	muDatasetMetadataEdits sync.Mutex
+)
+
+// rememberDatasetDetails records the upload details of a dataset that is being stored.
+func rememberDatasetDetails(datasetID string, data DatasetData) {
+	muDatasetDetails.Lock()
+	defer muDatasetDetails.Unlock()
+	datasetDetailsStore[datasetID] = detailsFromDataset(data)
+}
+
+// cachedDatasetDetails returns the upload details recorded for a dataset, if any.
+func cachedDatasetDetails(datasetID string) (datasetDetails, bool) {
+	muDatasetDetails.RLock()
+	defer muDatasetDetails.RUnlock()
+	details, found := datasetDetailsStore[datasetID]
+	return details, found
+}
+
+// forgetDatasetDetails drops the upload details of a deleted dataset.
+func forgetDatasetDetails(datasetID string) {
+	muDatasetDetails.Lock()
+	defer muDatasetDetails.Unlock()
+	delete(datasetDetailsStore, datasetID)
+}
+
+// storeDatasetDetailsRecord persists the details recorded in memory for a dataset. A failure
+// is only logged: lookups rebuild missing records from the dataset.
+func storeDatasetDetailsRecord(datasetID string) {
+	details, found := cachedDatasetDetails(datasetID)
+	if !found {
+		return
+	}
+	jsonData, err := json.Marshal(details)
+	if err == nil {
+		err = storage.Store(datasetDetailsRecordPrefix+datasetID, DatasetData{
+			Players:   []Player{},
+			CacheData: string(jsonData),
+		})
+	}
+	if err != nil {
+		LogWarn("Error storing details of dataset %s: %v", sanitizeForLogging(datasetID), err)
+	}
+}
+
+// deleteDatasetDetailsRecord removes the persisted details of a deleted dataset.
+func deleteDatasetDetailsRecord(datasetID string) {
+	if err := storage.Delete(datasetDetailsRecordPrefix + datasetID); err != nil && !errors.Is(err, apperrors.ErrDatasetNotFound) {
+		LogWarn("Error deleting details of dataset %s: %v", sanitizeForLogging(datasetID), err)
+	}
+}
+
+// lookupDatasetDetails returns the details of a dataset from memory or from its persisted
+// details record, without loading the dataset itself.
+func lookupDatasetDetails(datasetID string) (datasetDetails, bool) {
+	if details, found := cachedDatasetDetails(datasetID); found {
+		return details, true
+	}
+
+	record, err := storage.Retrieve(datasetDetailsRecordPrefix + datasetID)
+	if err != nil || record.CacheData == "" {
+		return datasetDetails{}, false
+	}
+	var details datasetDetails
+	if err := json.Unmarshal([]byte(record.CacheData), &details); err != nil {
+		LogWarn("Skipping unreadable details of dataset %s: %v", sanitizeForLogging(datasetID), err)
+		return datasetDetails{}, false
+	}
+
+	muDatasetDetails.Lock()
+	defer muDatasetDetails.Unlock()
+	if current, found := datasetDetailsStore[datasetID]; found {
+		return current, true // Recorded by a store while the record was read
+	}
+	datasetDetailsStore[datasetID] = details
+	return details, true
+}
+
+// loadDatasetDetails is lookupDatasetDetails for datasets stored before details records
+// existed: when no details are found it reads the dataset once and persists its details.
+func loadDatasetDetails(datasetID string) (datasetDetails, bool) {
+	if details, found := lookupDatasetDetails(datasetID); found {
+		return details, true
+	}
+	data, err := storage.Retrieve(datasetID)
+	if err != nil || data.CacheData != "" {
+		return datasetDetails{}, false
+	}
+	rememberDatasetDetails(datasetID, data)
+	storeDatasetDetailsRecord(datasetID)
+	details, _ := cachedDatasetDetails(datasetID)
+	return details, true
+}
+
+// isDatasetInMemory reports whether a dataset is held in the in-memory player store, which
+// covers datasets whose asynchronous store has not finished.
+func isDatasetInMemory(datasetID string) bool {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	_, exists := playerDataStore[datasetID]
+	return exists
+}
+
+// cleanupStaleDatasetDetails drops the recorded details of datasets that are neither in
+// storage nor in memory, such as those removed by retention cleanup, and records details for
+// stored datasets that have none.
+func cleanupStaleDatasetDetails() {
+	storedIDs, err := storage.List()
+	if err != nil {
+		LogWarn("Skipping dataset details cleanup: %v", err)
+		return
+	}
+	stored := make(map[string]bool, len(storedIDs))
+	for _, id := range storedIDs {
+		stored[id] = true
+	}
+
+	muDatasetDetails.RLock()
+	var staleIDs []string
+	for datasetID := range datasetDetailsStore {
+		if !stored[datasetID] {
+			staleIDs = append(staleIDs, datasetID)
+		}
+	}
+	muDatasetDetails.RUnlock()
+	for _, id := range storedIDs {
+		if datasetID, isRecord := strings.CutPrefix(id, datasetDetailsRecordPrefix); isRecord && !stored[datasetID] {
+			staleIDs = append(staleIDs, datasetID)
+		}
+	}
+
+	for _, datasetID := range staleIDs {
+		if !isDatasetInMemory(datasetID) {
+			forgetDatasetDetails(datasetID)
+			if stored[datasetDetailsRecordPrefix+datasetID] {
+				deleteDatasetDetailsRecord(datasetID)
+			}
+		}
+	}
+
+	for _, datasetID := range storedIDs {
+		if !isReservedDatasetID(datasetID) && !stored[datasetDetailsRecordPrefix+datasetID] {
+			loadDatasetDetails(datasetID)
+		}
+	}
+}
+
+// withDatasetDetails fills in the upload details of a player-only update from the details
+// recorded for the dataset, or from the stored dataset when none are recorded. The recorded
+// base currency is kept, so later stores do not re-derive it from the symbol.
+func withDatasetDetails(datasetID string, data DatasetData) DatasetData {
+	if data.ExportProfile != "" || data.Metadata != nil {
+		return data
+	}
+	details, found := cachedDatasetDetails(datasetID)
+	if !found {
+		existing, err := storage.Retrieve(datasetID)
+		if err != nil {
+			return data
+		}
+		details = detailsFromDataset(existing)
+	}
+	data.ExportProfile = details.ExportProfile
+	data.Metadata = details.Metadata
+	if data.BaseCurrency == "" {
+		data.BaseCurrency = details.BaseCurrency
+	}
+	return data
+}
+
+// createUploadMetadata builds the metadata of a dataset created from a single file.
+func createUploadMetadata(parsed *parsedUpload) *DatasetMetadata {
+	now := time.Now().UTC()
+	return &DatasetMetadata{
+		Name:             datasetNameFromFilename(parsed.Filename),
+		OriginalFilename: parsed.Filename,
+		UploadedAt:       now,
+		UpdatedAt:        now,
+		PlayerCount:      len(parsed.Players),
+		FMVersion:        detectedFMVersion(parsed.Report.ExportProfile),
+		FileHash:         parsed.FileHash,
+	}
+}
+
+// createMergedUploadMetadata builds the metadata of a merged dataset. Merging into an existing
+// dataset keeps its name, description, tags and upload time.
+func createMergedUploadMetadata(files []*parsedUpload, existing *DatasetMetadata, playerCount int) *DatasetMetadata {
+	now := time.Now().UTC()
+	fmVersion := ""
+	filenames := make([]string, 0, len(files))
+	for i, file := range files {
+		version := detectedFMVersion(file.Report.ExportProfile)
+		if i == 0 {
+			fmVersion = version
+		} else if version != fmVersion {
+			fmVersion = ""
+		}
+		filenames = append(filenames, file.Filename)
+	}
+
+	if existing != nil {
+		metadata := *existing
+		if metadata.FMVersion != fmVersion {
+			metadata.FMVersion = ""
+		}
+		if metadata.OriginalFilename != "" {
+			filenames = append([]string{metadata.OriginalFilename}, filenames...)
+		}
+		metadata.OriginalFilename = strings.Join(filenames, ", ")
+		metadata.UpdatedAt = now
+		metadata.PlayerCount = playerCount
+		metadata.FileHash = ""
+		return &metadata
+	}
+
+	name := ""
+	if len(filenames) > 0 {
+		name = datasetNameFromFilename(filenames[0])
+	}
+	return &DatasetMetadata{
+		Name:             name,
+		OriginalFilename: strings.Join(filenames, ", "),
+		UploadedAt:       now,
+		UpdatedAt:        now,
+		PlayerCount:      playerCount,
+		FMVersion:        fmVersion,
+	}
+}
+
+// detectedFMVersion returns the FM version of an export profile match, or "" when none was detected.
+func detectedFMVersion(match ExportProfileMatch) string {
+	if match.FMVersion == unknownFMVersion {
+		return ""
+	}
+	return match.FMVersion
+}
+
+// datasetNameFromFilename derives a default dataset name, so "Season 3.html.gz" becomes "Season 3".
+func datasetNameFromFilename(filename string) string {
+	name := decompressedUploadFilename(filepath.Base(filename))
+	name = strings.TrimSpace(strings.TrimSuffix(name, filepath.Ext(name)))
+	if utf8.RuneCountInString(name) > maxDatasetNameLength {
+		name = string([]rune(name)[:maxDatasetNameLength])
+	}
+	return name
+}
+
+// normalizeDatasetTags trims tags and drops empty and case-insensitively repeated ones.
+func normalizeDatasetTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxDatasetTagLength {
+			return nil, apperrors.WrapErrDatasetMetadataTooLong("tag", maxDatasetTagLength)
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxDatasetTags {
+		return nil, apperrors.WrapErrTooManyDatasetTags(maxDatasetTags)
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// applyTo validates the update and applies it to metadata.
+func (u DatasetMetadataUpdate) applyTo(metadata *DatasetMetadata) error {
+	if u.Name != nil {
+		name := strings.TrimSpace(*u.Name)
+		if utf8.RuneCountInString(name) > maxDatasetNameLength {
+			return apperrors.WrapErrDatasetMetadataTooLong("name", maxDatasetNameLength)
+		}
+		if name == "" {
+			name = datasetNameFromFilename(strings.Split(metadata.OriginalFilename, ", ")[0])
+		}
+		metadata.Name = name
+	}
+	if u.Description != nil {
+		description := strings.TrimSpace(*u.Description)
+		if utf8.RuneCountInString(description) > maxDatasetDescriptionLength {
+			return apperrors.WrapErrDatasetMetadataTooLong("description", maxDatasetDescriptionLength)
+		}
+		metadata.Description = description
+	}
+	if u.Tags != nil {
+		tags, err := normalizeDatasetTags(*u.Tags)
+		if err != nil {
+			return err
+		}
+		metadata.Tags = tags
+	}
+	return nil
+}
+
+// GetDatasetMetadata returns the metadata of a dataset. Datasets stored before metadata was
+// recorded get one holding only their player count.
+func GetDatasetMetadata(datasetID string) (DatasetMetadata, bool) {
+	details, found := loadDatasetDetails(datasetID)
+	if !found {
+		return DatasetMetadata{}, false
+	}
+	return details.metadata(), true
+}
+
+// UpdateDatasetMetadata applies a metadata edit and stores the dataset with it.
+func UpdateDatasetMetadata(datasetID string, update DatasetMetadataUpdate) (DatasetMetadata, error) {
+	muDatasetMetadataEdits.Lock()
+	defer muDatasetMetadataEdits.Unlock()
+
+	metadata, found := GetDatasetMetadata(datasetID)
+	if !found {
+		return DatasetMetadata{}, apperrors.WrapErrDatasetNotFound(datasetID)
+	}
+	metadata.Tags = append([]string(nil), metadata.Tags...)
+	if err := update.applyTo(&metadata); err != nil {
+		return DatasetMetadata{}, err
+	}
+	metadata.UpdatedAt = time.Now().UTC()
+
+	// Record the edit first: a dataset that is still being stored asynchronously is not in
+	// storage yet and picks the edit up from datasetDetailsStore when its store runs.
+	previous, hadPrevious := cachedDatasetDetails(datasetID)
+	details := previous
+	details.Metadata = &metadata
+	muDatasetDetails.Lock()
+	datasetDetailsStore[datasetID] = details
+	muDatasetDetails.Unlock()
+
+	data, err := storage.Retrieve(datasetID)
+	if err == nil {
+		data.Metadata = &metadata
+		if err = storage.Store(datasetID, data); err == nil {
+			storeDatasetDetailsRecord(datasetID)
+		}
+	} else if errors.Is(err, apperrors.ErrDatasetNotFound) && isDatasetInMemory(datasetID) {
+		err = nil
+	}
+	if err != nil {
+		if hadPrevious {
+			muDatasetDetails.Lock()
+			datasetDetailsStore[datasetID] = previous
+			muDatasetDetails.Unlock()
+		} else {
+			forgetDatasetDetails(datasetID)
+		}
+		return DatasetMetadata{}, err
+	}
+	return metadata, nil
+}
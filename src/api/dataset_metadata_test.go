@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func requestDatasetMetadata(t *testing.T, method, datasetID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/datasets/"+datasetID+"/metadata", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	datasetsHandler(w, req)
+	return w
+}
+
+func TestDatasetNameFromFilename(t *testing.T) {
+	for filename, want := range map[string]string{
+		"Season 3 January.html":           "Season 3 January",
+		"exports/squad.html.gz":           "squad",
+		"stats.csv.zst":                   "stats",
+		"no extension":                    "no extension",
+		strings.Repeat("a", 200) + ".csv": strings.Repeat("a", maxDatasetNameLength),
+	} {
+		if got := datasetNameFromFilename(filename); got != want {
+			t.Errorf("datasetNameFromFilename(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestNormalizeDatasetTags(t *testing.T) {
+	tags, err := normalizeDatasetTags([]string{" Season 3 ", "january", "season 3", ""})
+	if err != nil || !reflect.DeepEqual(tags, []string{"Season 3", "january"}) {
+		t.Errorf("normalizeDatasetTags = %v, %v", tags, err)
+	}
+	if _, err := normalizeDatasetTags([]string{strings.Repeat("x", maxDatasetTagLength+1)}); err == nil {
+		t.Error("Expected an error for an overlong tag")
+	}
+	tooMany := make([]string, maxDatasetTags+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("t", i+1)
+	}
+	if _, err := normalizeDatasetTags(tooMany); err == nil {
+		t.Error("Expected an error for too many tags")
+	}
+}
+
+// metadataTestView is an export no other test uploads, so duplicate detection never maps it
+// onto another test's dataset.
+const metadataTestView = "Name,UID,Club,Position,Fin,Pac,Dri\n" +
+	"Metadata Forward,920001,Metadata FC,ST (C),15,13,12\n" +
+	"Metadata Back,920002,Metadata FC,D (L),7,12,9\n"
+
+func TestDatasetMetadataLifecycle(t *testing.T) {
+	InitStore()
+
+	response := postMergeUpload(t, "/api/upload", map[string]string{"Season 3 January.csv": metadataTestView})
+	datasetID := response.DatasetID
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	w := requestDatasetMetadata(t, http.MethodGet, datasetID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var metadata DatasetMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &metadata); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	if metadata.Name != "Season 3 January" || metadata.OriginalFilename != "Season 3 January.csv" ||
+		metadata.PlayerCount != 2 || metadata.FileHash == "" || metadata.UploadedAt.IsZero() {
+		t.Errorf("Unexpected upload metadata: %+v", metadata)
+	}
+
+	w = requestDatasetMetadata(t, http.MethodPatch, datasetID,
+		`{"name": "Season 3 January window", "description": "After deadline day", "tags": ["season-3", "Season-3", "window"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var edited DatasetMetadata
+	if err := json.Unmarshal(w.Body.Bytes(), &edited); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	if edited.Name != "Season 3 January window" || edited.Description != "After deadline day" ||
+		!reflect.DeepEqual(edited.Tags, []string{"season-3", "window"}) || edited.FileHash != metadata.FileHash {
+		t.Errorf("Unexpected edited metadata: %+v", edited)
+	}
+
+	// Storing percentiles rewrites the players and must keep the edit
+	players, currencySymbol, _ := GetPlayerData(datasetID)
+	SetPlayerData(datasetID, players, currencySymbol)
+	stored, err := storage.Retrieve(datasetID)
+	if err != nil {
+		t.Fatalf("Failed to retrieve dataset: %v", err)
+	}
+	if stored.Metadata == nil || stored.Metadata.Name != edited.Name || stored.ExportProfile == "" {
+		t.Errorf("Expected metadata and export profile to survive a player update, got %+v, %q", stored.Metadata, stored.ExportProfile)
+	}
+
+	if w := requestDatasetMetadata(t, http.MethodPatch, datasetID, `{"name": "`+strings.Repeat("n", maxDatasetNameLength+1)+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an overlong name, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := requestDatasetMetadata(t, http.MethodPatch, datasetID, `{"owner": "someone"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown field, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := requestDatasetMetadata(t, http.MethodGet, "missing-dataset", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing dataset, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// unreadableStorage is a MockStorage whose reads fail, as a backend outage would.
+type unreadableStorage struct {
+	*MockStorage
+}
+
+func (s unreadableStorage) Retrieve(_ string) (DatasetData, error) {
+	return DatasetData{}, errors.New("storage unavailable")
+}
+
+func TestUpdateDatasetMetadataStorageFailures(t *testing.T) {
+	originalStorage := storage
+	defer func() { storage = originalStorage }()
+	storage = unreadableStorage{CreateMockStorage()}
+
+	datasetID := "metadata-unreadable"
+	original := DatasetMetadata{Name: "Original"}
+	rememberDatasetDetails(datasetID, DatasetData{Metadata: &original})
+	defer forgetDatasetDetails(datasetID)
+
+	name := "Edited"
+	if _, err := UpdateDatasetMetadata(datasetID, DatasetMetadataUpdate{Name: &name}); err == nil {
+		t.Fatal("Expected a storage read failure to be returned")
+	}
+	if metadata, _ := GetDatasetMetadata(datasetID); metadata.Name != original.Name {
+		t.Errorf("Expected a failed edit to leave the name %q, got %q", original.Name, metadata.Name)
+	}
+
+	storage = CreateMockStorage()
+	cleanupStaleDatasetDetails()
+	if _, found := cachedDatasetDetails(datasetID); found {
+		t.Error("Expected details of a dataset missing from storage to be cleaned up")
+	}
+}
+
+func TestDatasetMetadataProtoRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	uploadedAt := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+	dataset := &PlayerDataWithCurrency{
+		Players:        []Player{{UID: 1, Name: "Round Trip"}},
+		CurrencySymbol: "£",
+		Metadata: &DatasetMetadata{
+			Name:             "Season 3 January window",
+			OriginalFilename: "squad.html.gz",
+			UploadedAt:       uploadedAt,
+			UpdatedAt:        uploadedAt.Add(time.Hour),
+			PlayerCount:      1,
+			FMVersion:        "FM24",
+			FileHash:         "abc123",
+			Tags:             []string{"season-3"},
+		},
+	}
+
+	protoDataset, err := dataset.ToProto(ctx)
+	if err != nil {
+		t.Fatalf("ToProto failed: %v", err)
+	}
+	restored, err := DatasetDataFromProto(ctx, protoDataset)
+	if err != nil {
+		t.Fatalf("DatasetDataFromProto failed: %v", err)
+	}
+	if !reflect.DeepEqual(restored.Metadata, dataset.Metadata) {
+		t.Errorf("Metadata round trip = %+v, want %+v", restored.Metadata, dataset.Metadata)
+	}
+
+	dataset.Metadata = nil
+	protoDataset, _ = dataset.ToProto(ctx)
+	if restored, _ := DatasetDataFromProto(ctx, protoDataset); restored.Metadata != nil {
+		t.Errorf("Expected datasets without metadata to stay without, got %+v", restored.Metadata)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "api/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Page sizes of the dataset list. Sizes and metadata are only read for the requested page,
+// so backends holding thousands of datasets are not loaded in full.
+const (
+	defaultDatasetPageSize = 50
+	maxDatasetPageSize     = 500
+)
+
+// Prefixes of the records kept alongside datasets in storage: cache entries written by
+// cache_handlers.go and save timelines.
+const (
+	cacheDatasetPrefix = "cache_"
+	saveRecordPrefix   = "save_"
+)
+
+// maxDatasetIDLength matches the ID limit LocalFileStorage enforces.
+const maxDatasetIDLength = 100
+
+// DatasetListItem is one dataset in the dataset list.
+type DatasetListItem struct {
+	ID        string          `json:"id"`
+	Metadata  DatasetMetadata `json:"metadata"`
+	SizeBytes int64           `json:"sizeBytes,omitempty"` // Stored size, when the backend can report it
+}
+
+// DatasetListResponse is a page of the dataset list, ordered by dataset ID. NextCursor is
+// passed back as ?cursor= to fetch the following page and is empty on the last page.
+type DatasetListResponse struct {
+	Datasets   []DatasetListItem `json:"datasets"`
+	Total      int               `json:"total"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+// DatasetSummary describes one dataset without returning its players.
+type DatasetSummary struct {
+	ID               string          `json:"id"`
+	Metadata         DatasetMetadata `json:"metadata"`
+	SizeBytes        int64           `json:"sizeBytes,omitempty"`
+	CurrencySymbol   string          `json:"currencySymbol"`
+	BaseCurrency     string          `json:"baseCurrency,omitempty"`
+	ExportProfile    string          `json:"exportProfile,omitempty"`
+	PlayerCount      int             `json:"playerCount"`
+	ClubCount        int             `json:"clubCount"`
+	DivisionCount    int             `json:"divisionCount"`
+	NationalityCount int             `json:"nationalityCount"`
+	PositionGroups   map[string]int  `json:"positionGroups"` // Players per position group
+}
+
+// isReservedDatasetID reports whether a stored ID belongs to a cache entry or save record
+// rather than a dataset.
+func isReservedDatasetID(datasetID string) bool {
+	return strings.HasPrefix(datasetID, cacheDatasetPrefix) || strings.HasPrefix(datasetID, saveRecordPrefix)
+}
+
+// listDatasetIDs returns the IDs of all datasets in sorted order, including datasets whose
+// asynchronous store has not finished yet.
+func listDatasetIDs() ([]string, error) {
+	storedIDs, err := ListDatasets()
+	if err != nil {
+		return nil, err
+	}
+
+	idSet := make(map[string]bool, len(storedIDs))
+	for _, id := range storedIDs {
+		idSet[id] = true
+	}
+	muDatasetDetails.RLock()
+	for id := range datasetDetailsStore {
+		idSet[id] = true
+	}
+	muDatasetDetails.RUnlock()
+
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		if !isReservedDatasetID(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// listDatasetPage returns up to limit datasets with IDs after cursor.
+func listDatasetPage(limit int, cursor string) (DatasetListResponse, error) {
+	ids, err := listDatasetIDs()
+	if err != nil {
+		return DatasetListResponse{}, err
+	}
+
+	start := sort.Search(len(ids), func(i int) bool { return ids[i] > cursor })
+	end := start + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	response := DatasetListResponse{
+		Datasets: make([]DatasetListItem, 0, end-start),
+		Total:    len(ids),
+	}
+	for _, id := range ids[start:end] {
+		// Only details are read here; datasets without any are listed by ID until the
+		// cleanup scheduler records theirs.
+		var metadata DatasetMetadata
+		if details, found := lookupDatasetDetails(id); found {
+			metadata = details.metadata()
+		}
+		size, _ := datasetSize(storage, id)
+		response.Datasets = append(response.Datasets, DatasetListItem{ID: id, Metadata: metadata, SizeBytes: size})
+	}
+	if end < len(ids) {
+		response.NextCursor = ids[end-1]
+	}
+	return response, nil
+}
+
+// getDatasetSummary returns the counts recorded for a dataset when it was stored.
+func getDatasetSummary(datasetID string) (DatasetSummary, bool) {
+	details, found := loadDatasetDetails(datasetID)
+	if !found {
+		return DatasetSummary{}, false
+	}
+	size, _ := datasetSize(storage, datasetID)
+
+	baseCurrency := details.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = currencyCodeForSymbol(details.CurrencySymbol)
+	}
+	positionGroups := details.Counts.PositionGroups
+	if positionGroups == nil {
+		positionGroups = make(map[string]int)
+	}
+
+	return DatasetSummary{
+		ID:               datasetID,
+		Metadata:         details.metadata(),
+		SizeBytes:        size,
+		CurrencySymbol:   details.CurrencySymbol,
+		BaseCurrency:     baseCurrency,
+		ExportProfile:    details.ExportProfile,
+		PlayerCount:      details.Counts.Players,
+		ClubCount:        details.Counts.Clubs,
+		DivisionCount:    details.Counts.Divisions,
+		NationalityCount: details.Counts.Nationalities,
+		PositionGroups:   positionGroups,
+	}, true
+}
+
+// datasetsHandler serves the dataset management API:
+//
+//	GET    /api/datasets?limit=&cursor=      lists datasets with metadata and sizes
+//	GET    /api/datasets/{datasetId}          returns a dataset summary
+//	DELETE /api/datasets/{datasetId}          deletes the dataset
+//	GET    /api/datasets/{datasetId}/metadata returns the dataset's metadata
+//	PATCH  /api/datasets/{datasetId}/metadata edits name, description and tags
+func datasetsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.datasets")
+	defer span.End()
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/datasets"), "/")
+	SetSpanAttributes(ctx, attribute.String("http.method", r.Method))
+
+	if path == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handleListDatasets(ctx, w, r)
+		return
+	}
+
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) > 2 || (len(pathParts) == 2 && pathParts[1] != "metadata") {
+		http.Error(w, "Unknown dataset resource", http.StatusNotFound)
+		return
+	}
+	datasetID := pathParts[0]
+	if err := validateID(datasetID, maxDatasetIDLength); err != nil || isReservedDatasetID(datasetID) {
+		http.Error(w, "Invalid dataset ID", http.StatusBadRequest)
+		return
+	}
+	metadataOnly := len(pathParts) == 2
+	SetSpanAttributes(ctx, attribute.String("dataset.id", datasetID))
+
+	switch {
+	case r.Method == http.MethodGet && metadataOnly:
+		metadata, found := GetDatasetMetadata(datasetID)
+		if !found {
+			http.Error(w, "Dataset not found", http.StatusNotFound)
+			return
+		}
+		writeDatasetsResponse(ctx, w, r, metadata)
+
+	case r.Method == http.MethodGet:
+		summary, found := getDatasetSummary(datasetID)
+		if !found {
+			http.Error(w, "Dataset not found", http.StatusNotFound)
+			return
+		}
+		writeDatasetsResponse(ctx, w, r, summary)
+
+	case r.Method == http.MethodPatch && metadataOnly:
+		handleUpdateDatasetMetadata(ctx, w, r, datasetID)
+
+	case r.Method == http.MethodDelete && !metadataOnly:
+		handleDeleteDataset(ctx, w, r, datasetID)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleListDatasets writes one page of the dataset list.
+func handleListDatasets(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := defaultDatasetPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid limit: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxDatasetPageSize)
+	}
+
+	response, err := listDatasetPage(limit, query.Get("cursor"))
+	if err != nil {
+		RecordError(ctx, err, "Failed to list datasets")
+		http.Error(w, "Failed to list datasets", http.StatusInternalServerError)
+		return
+	}
+
+	SetSpanAttributes(ctx,
+		attribute.Int("datasets.total", response.Total),
+		attribute.Int("datasets.returned", len(response.Datasets)),
+	)
+	writeDatasetsResponse(ctx, w, r, response)
+}
+
+// handleUpdateDatasetMetadata applies a metadata edit from the request body.
+func handleUpdateDatasetMetadata(ctx context.Context, w http.ResponseWriter, r *http.Request, datasetID string) {
+	var update DatasetMetadataUpdate
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDatasetMetadataBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		http.Error(w, "Invalid metadata: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metadata, err := UpdateDatasetMetadata(datasetID, update)
+	switch {
+	case errors.Is(err, apperrors.ErrDatasetNotFound):
+		http.Error(w, "Dataset not found", http.StatusNotFound)
+		return
+	case errors.Is(err, apperrors.ErrDatasetMetadataTooLong), errors.Is(err, apperrors.ErrTooManyDatasetTags):
+		http.Error(w, "Invalid metadata: "+err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		RecordError(ctx, err, "Failed to store dataset metadata")
+		http.Error(w, "Failed to store dataset metadata", http.StatusInternalServerError)
+		return
+	}
+
+	logInfo(ctx, "Dataset metadata updated", "dataset_id", datasetID)
+	writeDatasetsResponse(ctx, w, r, metadata)
+}
+
+// handleDeleteDataset deletes a dataset and everything cached for it.
+func handleDeleteDataset(ctx context.Context, w http.ResponseWriter, r *http.Request, datasetID string) {
+	if _, found := GetDatasetMetadata(datasetID); !found {
+		http.Error(w, "Dataset not found", http.StatusNotFound)
+		return
+	}
+
+	if err := DeleteDataset(datasetID); err != nil {
+		RecordError(ctx, err, "Failed to delete dataset")
+		http.Error(w, "Failed to delete dataset", http.StatusInternalServerError)
+		return
+	}
+
+	logInfo(ctx, "Dataset deleted", "dataset_id", datasetID)
+	RecordBusinessOperation(ctx, "dataset_delete", true, map[string]interface{}{
+		"dataset_id": datasetID,
+	})
+
+	setCORSHeaders(w, r)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeDatasetsResponse encodes a dataset API response as JSON.
+func writeDatasetsResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logError(ctx, "Error encoding datasets response", "error", err)
+	}
+}
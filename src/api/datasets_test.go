@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestDatasets(t *testing.T, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	w := httptest.NewRecorder()
+	datasetsHandler(w, req)
+	return w
+}
+
+func TestListDatasetsPagination(t *testing.T) {
+	InitStore()
+
+	ids := []string{"list-test-a", "list-test-b", "list-test-c"}
+	for _, id := range ids {
+		if err := storeDatasetData(id, DatasetData{
+			Players:        []Player{{UID: 1, Name: "Listed Player"}},
+			CurrencySymbol: "£",
+			Metadata:       &DatasetMetadata{Name: "Dataset " + id, PlayerCount: 1},
+		}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		t.Cleanup(func() { _ = DeleteDataset(id) })
+	}
+	if err := storage.Store("cache_list_test", DatasetData{CacheData: "{}"}); err != nil {
+		t.Fatalf("Failed to store cache entry: %v", err)
+	}
+	t.Cleanup(func() { _ = storage.Delete("cache_list_test") })
+
+	var listed []DatasetListItem
+	cursor, total, pages := "", 0, 0
+	for {
+		w := requestDatasets(t, http.MethodGet, "/api/datasets?limit=2&cursor="+cursor)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var page DatasetListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("Failed to parse dataset list: %v", err)
+		}
+		if len(page.Datasets) > 2 {
+			t.Fatalf("Page holds %d datasets, limit was 2", len(page.Datasets))
+		}
+		listed = append(listed, page.Datasets...)
+		total = page.Total
+		pages++
+		if page.NextCursor == "" || pages > 1000 {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if len(listed) != total {
+		t.Errorf("Pages returned %d datasets, total is %d", len(listed), total)
+	}
+	position := make(map[string]int)
+	for i, item := range listed {
+		if isReservedDatasetID(item.ID) {
+			t.Errorf("Reserved record %s listed as a dataset", item.ID)
+		}
+		position[item.ID] = i
+	}
+	for i, id := range ids {
+		index, found := position[id]
+		if !found {
+			t.Fatalf("Dataset %s missing from the list", id)
+		}
+		if listed[index].Metadata.Name != "Dataset "+id {
+			t.Errorf("Dataset %s listed with metadata %+v", id, listed[index].Metadata)
+		}
+		if i > 0 && index < position[ids[i-1]] {
+			t.Errorf("Datasets are not listed in ID order: %s before %s", ids[i-1], id)
+		}
+	}
+
+	if w := requestDatasets(t, http.MethodGet, "/api/datasets?limit=0"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for limit=0, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestDatasetSummaryAndDelete(t *testing.T) {
+	InitStore()
+
+	// Distinct content, so the upload is not redirected to another test's dataset as a duplicate
+	export := strings.ReplaceAll(archiveTestAttributesView, "Archive FC", "Summary FC")
+	response := postMergeUpload(t, "/api/upload", map[string]string{"summary.csv": export})
+	datasetID := response.DatasetID
+
+	w := requestDatasets(t, http.MethodGet, "/api/datasets/"+datasetID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var summary DatasetSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to parse summary: %v", err)
+	}
+	if summary.PlayerCount != 2 || summary.ClubCount != 1 || summary.Metadata.Name != "summary" || summary.ExportProfile == "" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	fileHash := summary.Metadata.FileHash
+	if w := requestDatasets(t, http.MethodPatch, "/api/datasets/"+datasetID); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d editing metadata outside /metadata, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+
+	if w := requestDatasets(t, http.MethodDelete, "/api/datasets/"+datasetID); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	if _, _, found := GetPlayerData(datasetID); found {
+		t.Error("Deleted dataset is still served")
+	}
+	if _, duplicate := checkForDuplicateUpload(fileHash); duplicate {
+		t.Error("Deleted dataset still has a duplicate-upload mapping")
+	}
+	if w := requestDatasets(t, http.MethodGet, "/api/datasets/"+datasetID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d after deletion, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := requestDatasets(t, http.MethodDelete, "/api/datasets/"+datasetID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d deleting twice, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := requestDatasets(t, http.MethodDelete, "/api/datasets/cache_search_x"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d deleting a cache entry, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// retrieveCountingStorage is a MockStorage that counts reads of datasets, leaving out reads of
+// cache entries such as details records.
+type retrieveCountingStorage struct {
+	*MockStorage
+	datasetReads int
+}
+
+func (s *retrieveCountingStorage) Retrieve(datasetID string) (DatasetData, error) {
+	if !isReservedDatasetID(datasetID) {
+		s.datasetReads++
+	}
+	return s.MockStorage.Retrieve(datasetID)
+}
+
+func TestDatasetListAndSummaryReadDetailsRecords(t *testing.T) {
+	originalStorage := storage
+	defer func() { storage = originalStorage }()
+	counting := &retrieveCountingStorage{MockStorage: CreateMockStorage()}
+	storage = counting
+
+	ids := []string{"details-test-a", "details-test-b"}
+	for _, id := range ids {
+		if err := storeDatasetData(id, DatasetData{
+			Players: []Player{
+				{UID: 1, Name: "First", Club: "Details FC", Division: "League", PositionGroups: []string{"Forwards"}},
+				{UID: 2, Name: "Second", Club: "Other FC", Division: "League", PositionGroups: []string{"Defenders"}},
+			},
+			CurrencySymbol: "€",
+			Metadata:       &DatasetMetadata{Name: "Dataset " + id, PlayerCount: 2},
+		}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		// Drop the in-memory details, as a restart would
+		forgetDatasetDetails(id)
+		defer forgetDatasetDetails(id)
+	}
+	counting.datasetReads = 0
+
+	// Other tests may leave details of in-memory datasets behind, so only this test's are checked
+	page, err := listDatasetPage(maxDatasetPageSize, "")
+	if err != nil {
+		t.Fatalf("listDatasetPage failed: %v", err)
+	}
+	listed := make(map[string]DatasetMetadata)
+	for _, item := range page.Datasets {
+		listed[item.ID] = item.Metadata
+	}
+	for _, id := range ids {
+		if listed[id].Name != "Dataset "+id {
+			t.Errorf("Dataset %s listed with metadata %+v", id, listed[id])
+		}
+	}
+	summary, found := getDatasetSummary(ids[1])
+	if !found || summary.PlayerCount != 2 || summary.ClubCount != 2 || summary.DivisionCount != 1 ||
+		summary.PositionGroups["Forwards"] != 1 || summary.BaseCurrency != "EUR" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if counting.datasetReads != 0 {
+		t.Errorf("Listing and summarizing read %d datasets, expected only details records", counting.datasetReads)
+	}
+
+	if err := DeleteDataset(ids[0]); err != nil {
+		t.Fatalf("DeleteDataset failed: %v", err)
+	}
+	if _, err := storage.Retrieve(datasetDetailsRecordPrefix + ids[0]); err == nil {
+		t.Error("Expected the details record to be deleted with its dataset")
+	}
+
+	// Restores store save records and cache entries the same way; they get no details
+	saveID := saveRecordPrefix + "details-test"
+	if err := storeDatasetData(saveID, DatasetData{Players: []Player{}, CacheData: "{}"}); err != nil {
+		t.Fatalf("Failed to store save record: %v", err)
+	}
+	if _, err := storage.Retrieve(datasetDetailsRecordPrefix + saveID); err == nil {
+		t.Error("Expected no details record for a save record")
+	}
+}
+
+func TestLocalFileStorageDatasetSize(t *testing.T) {
+	local, err := CreateLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+	if err := local.Store("sized", DatasetData{Players: []Player{{UID: 1, Name: "Sized"}}, CurrencySymbol: "€"}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+
+	size, err := datasetSize(CreateProtobufStorage(local), "sized")
+	if err != nil || size <= 0 {
+		t.Errorf("datasetSize = %d, %v; want a positive size", size, err)
+	}
+	if _, err := local.DatasetSize("missing"); err == nil {
+		t.Error("Expected an error for a missing dataset")
+	}
+	if _, err := datasetSize(CreateInMemoryStorage(), "sized"); err == nil {
+		t.Error("Expected in-memory storage to report sizes as unavailable")
+	}
+}
@@ -55,6 +55,7 @@ var (
 	ErrNoTableHeadersFound              = errors.New("no table headers found in HTML file")
 	ErrHeadersFoundButWorkersNotStarted = errors.New("headers found but workers were not started")
 	ErrTokenizingHTML                   = errors.New("error tokenizing HTML")
+	ErrParsingCSV                       = errors.New("error parsing delimited file")
 	ErrFailedToParseAppearances         = errors.New("failed to parse appearances")
 	ErrInvalidAppearancesFormat         = errors.New("invalid appearances format")
 	ErrEmptyString                      = errors.New("empty string")
@@ -98,6 +99,7 @@ var (
 	// Service errors
 	ErrDatasetIDEmpty         = errors.New("dataset ID cannot be empty")
 	ErrDatasetNotFound        = errors.New("dataset not found")
+	ErrDatasetSizeUnavailable = errors.New("storage backend cannot report dataset sizes")
 	ErrNoPlayersToStore       = errors.New("no players to store")
 	ErrNoPlayersToProcess     = errors.New("no players to process")
 	ErrNoPlayersForValidation = errors.New("no players provided for validation")
@@ -107,6 +109,43 @@ var (
 	ErrUnsupportedFileFormat  = errors.New("unsupported file format, expected .html file")
 	ErrInvalidHTML            = errors.New("file does not appear to be valid HTML")
 	ErrS3ClientNotAvailable   = errors.New("S3 client not available")
+	ErrUploadTooLarge         = errors.New("upload exceeds maximum allowed size")
+	ErrUploadFieldMissing     = errors.New("upload form field missing")
+
+	// Compressed upload errors
+	ErrCompressionRatioExceeded = errors.New("upload expands beyond the allowed compression ratio")
+	ErrArchiveNoExports         = errors.New("archive contains no player exports")
+	ErrArchiveTooManyExports    = errors.New("archive contains too many player exports")
+
+	// Dataset metadata errors
+	ErrDatasetMetadataTooLong = errors.New("dataset metadata field is too long")
+	ErrTooManyDatasetTags     = errors.New("dataset has too many tags")
+
+	// Player lookup errors
+	ErrPlayerNotFound  = errors.New("player not found")
+	ErrAmbiguousPlayer = errors.New("player lookup is ambiguous")
+
+	// Player filter expression errors
+	ErrInvalidFilterExpression = errors.New("invalid filter expression")
+
+	// Player list query errors
+	ErrInvalidPlayerQuery = errors.New("invalid player list query")
+
+	// Save timeline errors
+	ErrSaveNotFound         = errors.New("save not found")
+	ErrSaveNameEmpty        = errors.New("save name cannot be empty")
+	ErrSaveHasNoSnapshots   = errors.New("save has no snapshots")
+	ErrInvalidGameDate      = errors.New("invalid in-game date, expected YYYY-MM-DD")
+	ErrDatasetInAnotherSave = errors.New("dataset already belongs to a save")
+
+	// Progression errors
+	ErrUnknownProgressionMetric = errors.New("unknown progression metric")
+
+	// Backup archive errors
+	ErrBackupManifestMissing    = errors.New("backup archive has no manifest")
+	ErrUnsupportedBackupVersion = errors.New("unsupported backup archive format version")
+	ErrBackupEntryMissing       = errors.New("backup archive is missing a file listed in its manifest")
+	ErrBackupChecksumMismatch   = errors.New("backup archive file does not match its checksum")
 
 	// Storage errors
 	ErrJSONMarshalPanic = errors.New("panic during JSON marshal")
@@ -123,6 +162,10 @@ var (
 	ErrInvalidClubsDirPath  = errors.New("invalid clubs directory path")
 	ErrInvalidNormalDirPath = errors.New("invalid normal directory path")
 	ErrInvalidDatasetID     = errors.New("invalid dataset ID")
+
+	// Currency errors
+	ErrUnsupportedCurrency    = errors.New("unsupported currency")
+	ErrDatasetCurrencyUnknown = errors.New("dataset currency has no exchange rate")
 )
 
 // WrapErrInvalidCacheDataFormat wraps an error with context about invalid cache data format
@@ -225,6 +268,16 @@ func WrapErrDatasetNotFound(datasetID string) error {
 	return fmt.Errorf("%w: %s", ErrDatasetNotFound, datasetID)
 }
 
+// WrapErrUnsupportedCurrency wraps an unsupported currency error with the requested currency
+func WrapErrUnsupportedCurrency(currency string) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+}
+
+// WrapErrDatasetCurrencyUnknown wraps a dataset currency error with the dataset's symbol
+func WrapErrDatasetCurrencyUnknown(symbol string) error {
+	return fmt.Errorf("%w: %q", ErrDatasetCurrencyUnknown, symbol)
+}
+
 // WrapErrJSONMarshalPanic wraps a JSON marshal panic error with context
 func WrapErrJSONMarshalPanic(panicValue interface{}) error {
 	return fmt.Errorf("%w: %v", ErrJSONMarshalPanic, panicValue)
@@ -260,6 +313,96 @@ func WrapErrTokenizingHTML(err error) error {
 	return fmt.Errorf("%w: %v", ErrTokenizingHTML, err)
 }
 
+// WrapErrParsingCSV wraps a CSV/TSV reader error with context
+func WrapErrParsingCSV(err error) error {
+	return fmt.Errorf("%w: %v", ErrParsingCSV, err)
+}
+
+// WrapErrUploadFieldMissing wraps an upload field missing error with the expected field name
+func WrapErrUploadFieldMissing(fieldName string) error {
+	return fmt.Errorf("%w: %s", ErrUploadFieldMissing, fieldName)
+}
+
+// WrapErrCompressionRatioExceeded wraps a compression ratio error with the allowed ratio
+func WrapErrCompressionRatioExceeded(maxRatio int) error {
+	return fmt.Errorf("%w of %d:1", ErrCompressionRatioExceeded, maxRatio)
+}
+
+// WrapErrArchiveTooManyExports wraps a too many exports error with the allowed count
+func WrapErrArchiveTooManyExports(maxExports int) error {
+	return fmt.Errorf("%w (max %d)", ErrArchiveTooManyExports, maxExports)
+}
+
+// WrapErrDatasetMetadataTooLong wraps a metadata length error with the field and its limit
+func WrapErrDatasetMetadataTooLong(field string, maxLength int) error {
+	return fmt.Errorf("%w: %s (max %d characters)", ErrDatasetMetadataTooLong, field, maxLength)
+}
+
+// WrapErrTooManyDatasetTags wraps a tag count error with the allowed count
+func WrapErrTooManyDatasetTags(maxTags int) error {
+	return fmt.Errorf("%w (max %d)", ErrTooManyDatasetTags, maxTags)
+}
+
+// WrapErrPlayerNotFound wraps a player not found error with the player's UID
+func WrapErrPlayerNotFound(uid int64) error {
+	return fmt.Errorf("%w: %d", ErrPlayerNotFound, uid)
+}
+
+// WrapErrPlayerNameNotFound wraps a player not found error with the player's name
+func WrapErrPlayerNameNotFound(name string) error {
+	return fmt.Errorf("%w: %q", ErrPlayerNotFound, name)
+}
+
+// WrapErrAmbiguousPlayer wraps an ambiguous player error with the lookup and its match count
+func WrapErrAmbiguousPlayer(lookup string, matches int) error {
+	return fmt.Errorf("%w: %s matches %d players", ErrAmbiguousPlayer, lookup, matches)
+}
+
+// WrapErrInvalidFilterExpression wraps a filter expression error with the offending position
+func WrapErrInvalidFilterExpression(position int, message string) error {
+	return fmt.Errorf("%w at position %d: %s", ErrInvalidFilterExpression, position, message)
+}
+
+// WrapErrInvalidPlayerQuery wraps a player list query error with the offending parameter
+func WrapErrInvalidPlayerQuery(parameter, message string) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidPlayerQuery, parameter, message)
+}
+
+// WrapErrSaveNotFound wraps a save not found error with the save ID
+func WrapErrSaveNotFound(saveID string) error {
+	return fmt.Errorf("%w: %s", ErrSaveNotFound, saveID)
+}
+
+// WrapErrInvalidGameDate wraps an in-game date error with the rejected value
+func WrapErrInvalidGameDate(gameDate string) error {
+	return fmt.Errorf("%w: %q", ErrInvalidGameDate, gameDate)
+}
+
+// WrapErrUnknownProgressionMetric wraps a progression metric error with the rejected metric
+func WrapErrUnknownProgressionMetric(metric string) error {
+	return fmt.Errorf("%w: %q is not Overall, transferValue, a FIFA category or an attribute or role of the datasets", ErrUnknownProgressionMetric, metric)
+}
+
+// WrapErrDatasetInAnotherSave wraps a dataset ownership error with the owning save's ID
+func WrapErrDatasetInAnotherSave(datasetID, saveID string) error {
+	return fmt.Errorf("%w: %s is in save %s", ErrDatasetInAnotherSave, datasetID, saveID)
+}
+
+// WrapErrUnsupportedBackupVersion wraps a backup format error with the archive's version
+func WrapErrUnsupportedBackupVersion(version int) error {
+	return fmt.Errorf("%w: %d", ErrUnsupportedBackupVersion, version)
+}
+
+// WrapErrBackupEntryMissing wraps a missing archive file error with the file's path
+func WrapErrBackupEntryMissing(path string) error {
+	return fmt.Errorf("%w: %s", ErrBackupEntryMissing, path)
+}
+
+// WrapErrBackupChecksumMismatch wraps a checksum error with the damaged file's path
+func WrapErrBackupChecksumMismatch(path string) error {
+	return fmt.Errorf("%w: %s", ErrBackupChecksumMismatch, path)
+}
+
 // WrapErrFailedToParseAppearances wraps a failed to parse appearances error with context
 func WrapErrFailedToParseAppearances() error {
 	return fmt.Errorf("%w", ErrFailedToParseAppearances)
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// exportProfilesFile declares the known FM export layouts. Supporting a new FM release means
+// adding its profile here rather than teaching parseCellsToPlayer new header names.
+var exportProfilesFile = filepath.Join("public", "export_profiles.json")
+
+// Export views, told apart by the kind of columns a table carries.
+const (
+	ExportViewAttributes = "attributes" // Squad and scouting views of 1-20 attributes
+	ExportViewStats      = "stats"      // Performance statistics views
+	ExportViewMixed      = "mixed"      // Custom views with both attributes and statistics
+	ExportViewInfo       = "info"       // Only player info columns such as club, age and value
+)
+
+// unknownFMVersion is reported when no profile's marker headers are present.
+const unknownFMVersion = "unknown"
+
+// minExportViewColumns is how many attribute (or statistic) columns a table needs before it
+// counts as an attribute (or stats) view.
+const minExportViewColumns = 3
+
+// ExportProfile describes how one FM version exports its tables. A profile without a View is
+// the version's base profile and carries the marker headers used to detect it. A profile with
+// a View adds columns and quirks for that view on top of the base profile.
+type ExportProfile struct {
+	FMVersion      string            `json:"fmVersion"`
+	View           string            `json:"view,omitempty"`
+	MarkerHeaders  []string          `json:"markerHeaders,omitempty"`  // Headers only this version exports
+	Columns        map[string]string `json:"columns,omitempty"`        // Export header -> canonical header
+	IgnoredColumns []string          `json:"ignoredColumns,omitempty"` // Headers dropped before parsing
+}
+
+// ExportProfileMatch is the export profile detected for an uploaded table.
+type ExportProfileMatch struct {
+	Key       string `json:"key"` // e.g. "fm24-stats"
+	FMVersion string `json:"fmVersion"`
+	View      string `json:"view"`
+	Language  string `json:"language"`
+}
+
+var (
+	exportProfiles        = make(map[string]ExportProfile) // Keyed by exportProfileKey
+	exportProfileVersions []string                         // Versions in detection order
+	muExportProfiles      sync.RWMutex
+)
+
+// exportProfileKey identifies a profile by FM version and view, e.g. "fm24" or "fm24-stats".
+func exportProfileKey(fmVersion, view string) string {
+	key := strings.ToLower(fmVersion)
+	if view != "" {
+		key += "-" + view
+	}
+	return key
+}
+
+// loadExportProfiles reads the profile registry from a JSON array of profiles.
+func loadExportProfiles(filePath string) ([]ExportProfile, error) {
+	if err := validateConfigFilePath(filePath); err != nil {
+		LogWarn("Invalid file path %s: %v. Exports will be parsed without version profiles.", filePath, err)
+		return nil, err
+	}
+
+	//nolint:gosec // filePath is validated above to be within allowed directories
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		LogWarn("Could not read %s: %v. Exports will be parsed without version profiles.", filePath, err)
+		return nil, err
+	}
+
+	var profiles []ExportProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		LogWarn("Could not unmarshal %s: %v. Exports will be parsed without version profiles.", filePath, err)
+		return nil, err
+	}
+
+	LogDebug("Successfully loaded %d export profiles from %s.", len(profiles), filePath)
+	return profiles, nil
+}
+
+// setExportProfiles replaces the active registry. Versions are detected in the order their
+// base profiles appear, which breaks ties between equally matching versions.
+func setExportProfiles(profiles []ExportProfile) {
+	muExportProfiles.Lock()
+	defer muExportProfiles.Unlock()
+
+	exportProfiles = make(map[string]ExportProfile, len(profiles))
+	exportProfileVersions = nil
+	for _, profile := range profiles {
+		registerExportProfileLocked(profile)
+	}
+}
+
+// registerExportProfile adds a profile to the active registry, replacing any profile with the
+// same FM version and view.
+func registerExportProfile(profile ExportProfile) {
+	muExportProfiles.Lock()
+	defer muExportProfiles.Unlock()
+	registerExportProfileLocked(profile)
+}
+
+// registerExportProfileLocked is registerExportProfile for callers holding muExportProfiles.
+func registerExportProfileLocked(profile ExportProfile) {
+	key := exportProfileKey(profile.FMVersion, profile.View)
+	if _, exists := exportProfiles[key]; exists {
+		LogWarn("Export profile %s is declared more than once; the last declaration wins.", key)
+	} else if profile.View == "" {
+		exportProfileVersions = append(exportProfileVersions, profile.FMVersion)
+	}
+	exportProfiles[key] = profile
+}
+
+// detectFMVersion returns the version whose base profile has the most marker headers among
+// the raw and alias-resolved headers, or unknownFMVersion when none match.
+func detectFMVersion(headers, canonicalHeaders []string) string {
+	present := make(map[string]bool, len(headers)+len(canonicalHeaders))
+	for _, header := range headers {
+		present[strings.TrimSpace(header)] = true
+	}
+	for _, header := range canonicalHeaders {
+		present[header] = true
+	}
+
+	muExportProfiles.RLock()
+	defer muExportProfiles.RUnlock()
+
+	bestVersion := unknownFMVersion
+	bestMatches := 0
+	for _, version := range exportProfileVersions {
+		matches := 0
+		for _, marker := range exportProfiles[exportProfileKey(version, "")].MarkerHeaders {
+			if present[marker] {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			bestVersion = version
+			bestMatches = matches
+		}
+	}
+	return bestVersion
+}
+
+// detectExportView classifies canonical headers by how many attribute and statistic columns they carry.
+func detectExportView(canonicalHeaders []string) string {
+	attributeColumns, statColumns := 0, 0
+	for _, header := range canonicalHeaders {
+		switch {
+		case fmAttributeKeys[header]:
+			attributeColumns++
+		case isPerformanceStatKey(header):
+			statColumns++
+		}
+	}
+
+	switch {
+	case attributeColumns >= minExportViewColumns && statColumns >= minExportViewColumns:
+		return ExportViewMixed
+	case attributeColumns >= minExportViewColumns:
+		return ExportViewAttributes
+	case statColumns >= minExportViewColumns:
+		return ExportViewStats
+	}
+	return ExportViewInfo
+}
+
+// applyExportProfile returns headers with the profile's column mapping applied and its ignored
+// columns blanked, positionally aligned with the input so cells stay matched to their headers.
+func applyExportProfile(profile ExportProfile, headers []string) []string {
+	if len(profile.Columns) == 0 && len(profile.IgnoredColumns) == 0 {
+		return headers
+	}
+
+	ignored := make(map[string]bool, len(profile.IgnoredColumns))
+	for _, column := range profile.IgnoredColumns {
+		ignored[column] = true
+	}
+
+	mapped := make([]string, len(headers))
+	for i, header := range headers {
+		switch canonical, ok := profile.Columns[header]; {
+		case ignored[header]:
+			mapped[i] = ""
+		case ok:
+			mapped[i] = canonical
+		default:
+			mapped[i] = header
+		}
+	}
+	return mapped
+}
+
+// resolveExportHeaders detects the export profile of a table from its header row and returns
+// the headers translated to canonical keys. Localized headers are translated first, then the
+// detected version's base profile is applied, then the profile for the detected view, if any.
+func resolveExportHeaders(headers []string) ([]string, ExportProfileMatch) {
+	canonical, language := resolveHeaderAliases(headers)
+	version := detectFMVersion(headers, canonical)
+
+	muExportProfiles.RLock()
+	baseProfile, hasBase := exportProfiles[exportProfileKey(version, "")]
+	muExportProfiles.RUnlock()
+	if hasBase {
+		canonical = applyExportProfile(baseProfile, canonical)
+	}
+
+	view := detectExportView(canonical)
+	muExportProfiles.RLock()
+	viewProfile, hasView := exportProfiles[exportProfileKey(version, view)]
+	muExportProfiles.RUnlock()
+	if hasView {
+		canonical = applyExportProfile(viewProfile, canonical)
+	}
+
+	return canonical, ExportProfileMatch{
+		Key:       exportProfileKey(version, view),
+		FMVersion: version,
+		View:      view,
+		Language:  language,
+	}
+}
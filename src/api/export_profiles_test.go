@@ -0,0 +1,150 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withExportProfiles installs the shipped export profiles for the duration of a test.
+func withExportProfiles(t *testing.T) {
+	t.Helper()
+
+	profiles, err := loadExportProfiles(exportProfilesFile)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", exportProfilesFile, err)
+	}
+
+	muExportProfiles.RLock()
+	previousProfiles, previousVersions := exportProfiles, exportProfileVersions
+	muExportProfiles.RUnlock()
+
+	setExportProfiles(profiles)
+	t.Cleanup(func() {
+		muExportProfiles.Lock()
+		exportProfiles, exportProfileVersions = previousProfiles, previousVersions
+		muExportProfiles.Unlock()
+	})
+}
+
+func TestExportProfileFileTargetsCanonicalKeys(t *testing.T) {
+	profiles, err := loadExportProfiles(exportProfilesFile)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", exportProfilesFile, err)
+	}
+
+	baseProfiles := make(map[string]bool)
+	for _, profile := range profiles {
+		if profile.View == "" {
+			baseProfiles[profile.FMVersion] = true
+			if len(profile.MarkerHeaders) == 0 {
+				t.Errorf("Base profile %s has no marker headers and can never be detected", profile.FMVersion)
+			}
+		}
+	}
+
+	for _, profile := range profiles {
+		key := exportProfileKey(profile.FMVersion, profile.View)
+		switch profile.View {
+		case "", ExportViewAttributes, ExportViewStats, ExportViewMixed, ExportViewInfo:
+		default:
+			t.Errorf("Profile %s has unknown view %q", key, profile.View)
+		}
+		if profile.View != "" && !baseProfiles[profile.FMVersion] {
+			t.Errorf("View profile %s has no base profile for %s", key, profile.FMVersion)
+		}
+		for exported, canonical := range profile.Columns {
+			if !isCanonicalHeader(canonical) {
+				t.Errorf("Profile %s maps %q to %q, which is not a canonical header", key, exported, canonical)
+			}
+		}
+	}
+}
+
+func TestResolveExportHeaders(t *testing.T) {
+	withHeaderAliases(t)
+	withExportProfiles(t)
+
+	tests := []struct {
+		name        string
+		headers     []string
+		wantKey     string
+		wantHeaders []string
+	}{
+		{
+			name:        "fm23 stats view",
+			headers:     []string{"Name", "Shots/90", "Conc/90", "Pr Passes/90", "Shutouts"},
+			wantKey:     "fm23-stats",
+			wantHeaders: []string{"Name", "Shot/90", "Con/90", "Pr passes/90", "Clean Sheets"},
+		},
+		{
+			name:        "fm24 stats view",
+			headers:     []string{"Name", "xGP/90", "Pr passes/90", "NP-xG/90"},
+			wantKey:     "fm24-stats",
+			wantHeaders: []string{"Name", "xGP/90", "Pr passes/90", "NP-xG/90"},
+		},
+		{
+			name:        "fm25 stats view drops ignored columns",
+			headers:     []string{"Name", "Prog Passes/90", "Conceded/90", "xGOT/90", "Sv %"},
+			wantKey:     "fm25-stats",
+			wantHeaders: []string{"Name", "Pr passes/90", "Con/90", "", "Sv %"},
+		},
+		{
+			name:        "attributes view without markers",
+			headers:     []string{"Name", "Club", "Fin", "Pas", "Tec"},
+			wantKey:     "unknown-attributes",
+			wantHeaders: []string{"Name", "Club", "Fin", "Pas", "Tec"},
+		},
+		{
+			name:        "localized fm24 mixed view",
+			headers:     []string{"Name", "Verein", "Abs", "Atr", "Pss", "xGP/90", "NP-xG/90", "Poss Lost/90"},
+			wantKey:     "fm24-mixed",
+			wantHeaders: []string{"Name", "Club", "Fin", "Acc", "Pas", "xGP/90", "NP-xG/90", "Poss Lost/90"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHeaders, match := resolveExportHeaders(tt.headers)
+			if match.Key != tt.wantKey {
+				t.Errorf("profile = %q, want %q", match.Key, tt.wantKey)
+			}
+			if !reflect.DeepEqual(gotHeaders, tt.wantHeaders) {
+				t.Errorf("headers = %v, want %v", gotHeaders, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestRegisterExportProfile(t *testing.T) {
+	withExportProfiles(t)
+
+	registerExportProfile(ExportProfile{
+		FMVersion:     "FM26",
+		MarkerHeaders: []string{"Prog Carries/90"},
+		Columns:       map[string]string{"Shots on Target/90": "ShT/90"},
+	})
+
+	headers, match := resolveExportHeaders([]string{"Name", "Prog Carries/90", "Shots on Target/90"})
+	if match.FMVersion != "FM26" || match.Key != "fm26-info" {
+		t.Errorf("Expected the registered FM26 profile to be detected, got %+v", match)
+	}
+	if headers[2] != "ShT/90" {
+		t.Errorf("Expected the FM26 column mapping to apply, got %v", headers)
+	}
+}
+
+func TestMergedExportProfileKey(t *testing.T) {
+	upload := func(version, view string) *parsedUpload {
+		return &parsedUpload{Report: ParseReport{ExportProfile: ExportProfileMatch{FMVersion: version, View: view}}}
+	}
+
+	if got := mergedExportProfileKey([]*parsedUpload{upload("FM24", ExportViewStats), upload("FM24", ExportViewStats)}); got != "fm24-stats" {
+		t.Errorf("Same profile merge = %q, want fm24-stats", got)
+	}
+	if got := mergedExportProfileKey([]*parsedUpload{upload("FM24", ExportViewAttributes), upload("FM24", ExportViewStats)}); got != "fm24-mixed" {
+		t.Errorf("Different view merge = %q, want fm24-mixed", got)
+	}
+	if got := mergedExportProfileKey([]*parsedUpload{upload("FM23", ExportViewStats), upload("FM24", ExportViewStats)}); got != "" {
+		t.Errorf("Different version merge = %q, want none", got)
+	}
+}
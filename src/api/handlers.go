@@ -1,11 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -54,11 +54,6 @@ func calculateOptimalSliceCapacity(expectedSize int) int {
 var fileHashToDatasetMap = make(map[string]string)
 var hashMapMutex sync.RWMutex
 
-func calculateFileHash(content []byte) string {
-	hash := sha256.Sum256(content)
-	return hex.EncodeToString(hash[:])
-}
-
 func checkForDuplicateUpload(fileHash string) (string, bool) {
 	hashMapMutex.RLock()
 	defer hashMapMutex.RUnlock()
@@ -239,8 +234,22 @@ func getFileSizeLimitErrorMessage() string {
 	return fmt.Sprintf("Only 10,000 players or less can be in a given dataset. (Max file size: %dMB)", maxUploadSizeMB)
 }
 
-// uploadHandler handles POST requests for uploading HTML player files.
+// rejectOversizedUpload records a rejection once the streamed file has crossed the upload
+// limit and returns the 413 error to send back to the client.
+func rejectOversizedUpload(ctx context.Context, filename string) *apperrors.AppError {
+	logWarn(ctx, "Upload rejected: File size exceeds limit",
+		"filename", filename,
+		"max_size_bytes", getMaxUploadSize())
+	SetSpanAttributes(ctx, attribute.String("upload.rejection_reason", "file_size_exceeded"))
+	appErr := apperrors.CreateFileTooLargeError(getMaxUploadSize())
+	appErr.Message = getFileSizeLimitErrorMessage()
+	return appErr
+}
+
+// uploadHandler handles POST requests for uploading HTML, CSV or TSV player files.
 // It parses the file, processes player data concurrently, and stores the results.
+// With ?async=true the file is accepted as a background job instead and the response
+// carries a job ID to poll at /api/upload-jobs/{jobId}.
 func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if r.Method != http.MethodPost {
@@ -273,146 +282,168 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 		attribute.Int64("http.request.content_length", r.ContentLength),
 	)
 
-	if r.ContentLength > getMaxUploadSize() {
+	mergeOptions, err := parseUploadMergeOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Invalid dataset ID: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A merge request carries several files, each held to the single-upload limit
+	bodyLimit := getMaxUploadSize()
+	if mergeOptions.Enabled {
+		bodyLimit *= maxMergeUploadFiles
+	}
+
+	if r.ContentLength > bodyLimit {
 		logWarn(ctx, "Upload rejected: Content-Length exceeds limit",
 			"content_length_bytes", r.ContentLength,
-			"max_size_bytes", getMaxUploadSize())
+			"max_size_bytes", bodyLimit)
 		SetSpanAttributes(ctx, attribute.String("upload.rejection_reason", "content_length_exceeded"))
 		http.Error(w, getFileSizeLimitErrorMessage(), http.StatusRequestEntityTooLarge)
 		return
 	}
 
-	// ParseMultipartForm will also respect the maxMemory argument for in-memory parts,
-	// but the total request size is what we're primarily concerned with for the file part.
-	// We'll check the actual file size after getting the file.
-	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32MB for other form data, not the file itself immediately
+	// Stream the multipart body instead of buffering it: the file part is hashed,
+	// size-checked and parsed in a single pass so memory stays bounded by the parser's
+	// buffers rather than the size of the upload.
+	r.Body = http.MaxBytesReader(w, r.Body, bodyLimit+multipartOverheadAllowance)
+	multipartReader, err := r.MultipartReader()
+	if err != nil {
 		http.Error(w, "Error parsing multipart form: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	AddSpanEvent(ctx, "multipart.form.parsed")
+	if mergeOptions.Enabled {
+		handleMergeUpload(ctx, w, r, multipartReader, mergeOptions)
+		return
+	}
 
-	file, handler, err := r.FormFile("playerFile")
+	filePart, err := nextUploadFilePart(multipartReader, uploadFormField)
 	if err != nil {
 		RecordError(ctx, err, "Failed to retrieve uploaded file")
 		http.Error(w, "Error retrieving the file: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
+		if closeErr := filePart.Close(); closeErr != nil {
 			RecordError(ctx, closeErr, "Failed to close uploaded file")
 		}
 	}()
 
-	// Validate actual file size by reading content (more secure than relying on headers)
-	limitedReader := http.MaxBytesReader(w, file, getMaxUploadSize())
-	fileContent, err := io.ReadAll(limitedReader)
-	if err != nil {
-		RecordError(ctx, err, "File size validation failed - file too large or read error")
-		logWarn(ctx, "Upload rejected: File content exceeds size limit or read error",
-			"max_size_bytes", getMaxUploadSize(),
-			"filename", handler.Filename)
-		http.Error(w, getFileSizeLimitErrorMessage(), http.StatusRequestEntityTooLarge)
-		return
-	}
+	AddSpanEvent(ctx, "multipart.file_part.found")
 
-	actualFileSize := int64(len(fileContent))
+	filename := filePart.FileName()
 	SetSpanAttributes(ctx,
-		attribute.String("file.name", handler.Filename),
-		attribute.Int64("file.size", actualFileSize),
-		attribute.String("file.content_type", handler.Header.Get("Content-Type")),
-		attribute.Int64("file.size_from_header", handler.Size),
+		attribute.String("file.name", filename),
+		attribute.String("file.content_type", filePart.Header.Get("Content-Type")),
 	)
 
-	logDebug(ctx, "File uploaded",
-		"filename", handler.Filename,
-		"size_bytes", actualFileSize)
-
-	// Enforce the 50MB limit on the actual file size
-	if actualFileSize > getMaxUploadSize() {
-		logWarn(ctx, "Upload rejected: File size exceeds limit",
-			"filename", handler.Filename,
-			"file_size_bytes", actualFileSize,
-			"max_size_bytes", getMaxUploadSize())
-		SetSpanAttributes(ctx, attribute.String("upload.rejection_reason", "file_size_exceeded"))
-		http.Error(w, getFileSizeLimitErrorMessage(), http.StatusRequestEntityTooLarge)
+	if isAsyncUploadRequested(r) {
+		startAsyncUpload(ctx, w, r, filename, filePart)
 		return
 	}
 
-	// Check for duplicate upload before processing
-	ctx, duplicateSpan := StartSpan(ctx, "duplicate.check")
-	fileHash := calculateFileHash(fileContent)
-	existingDatasetID, isDuplicate := checkForDuplicateUpload(fileHash)
-
-	SetSpanAttributes(ctx,
-		attribute.String("file.hash", fileHash[:16]+"..."), // Only log first 16 chars for security
-		attribute.Bool("duplicate.found", isDuplicate),
-	)
+	// The real size is only known once the stream is consumed, so buffer sizing uses the
+	// request's Content-Length as an estimate.
+	response, duplicate, appErr := processUploadStream(ctx, filename, filePart, r.ContentLength, nil)
+	if appErr != nil {
+		http.Error(w, appErr.Message, appErr.HTTPStatus)
+		return
+	}
 
-	if isDuplicate {
-		// Verify the existing dataset still exists in storage
-		if _, _, found := GetPlayerData(existingDatasetID); found {
-			logInfo(ctx, "Duplicate upload detected, redirecting to existing dataset",
-				"filename", handler.Filename,
-				"existing_dataset_id", existingDatasetID,
-				"file_hash", fileHash[:16]+"...")
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
 
-			SetSpanAttributes(ctx,
-				attribute.String("duplicate.existing_dataset_id", existingDatasetID),
-				attribute.String("duplicate.action", "redirect_to_existing"),
-			)
-			duplicateSpan.End()
+	ctx, responseSpan := StartSpan(ctx, "response.encode")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		RecordError(ctx, err, "Failed to encode JSON response")
+		log.Printf("Error encoding JSON response for upload: %v", err)
+		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	responseSpan.End()
 
-			// Return the existing dataset info without reprocessing
-			response := UploadResponse{
-				DatasetID:              existingDatasetID,
-				Message:                "Duplicate file detected. Redirected to existing dataset.",
-				DetectedCurrencySymbol: "$", // Will be updated with actual value from existing dataset
-			}
+	// Calculate percentiles asynchronously after response is sent
+	if !duplicate {
+		go calculateUploadPercentiles(response.DatasetID, nil)
+	}
+}
 
-			// Get the actual currency symbol from the existing dataset
-			if _, currencySymbol, found := GetPlayerData(existingDatasetID); found {
-				response.DetectedCurrencySymbol = currencySymbol
-			}
+// parsedUpload is the outcome of parsing a single uploaded file, before it is stored.
+type parsedUpload struct {
+	Filename      string // As uploaded, before any compression extension is stripped
+	Players       []Player
+	Report        ParseReport
+	Format        UploadFormat
+	FileHash      string
+	FileSize      int64
+	NumWorkers    int
+	ParseDuration time.Duration
+}
 
-			w.Header().Set("Content-Type", "application/json")
-			setCORSHeaders(w, r)
+// parseUploadStream streams one file through format detection and the parser worker pool,
+// hashing it on the way for duplicate detection. job may be nil; when set it receives
+// tokenizing/parsing progress.
+func parseUploadStream(ctx context.Context, filename string, source io.Reader, estimatedFileSize int64, job *UploadJob) (*parsedUpload, *apperrors.AppError) {
+	job.SetPhase(UploadPhaseTokenizing)
+	uploadedFilename := filename
 
-			if err := json.NewEncoder(w).Encode(response); err != nil {
-				RecordError(ctx, err, "Failed to encode duplicate response")
-				http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
-				return
-			}
+	// Gzip and zstd uploads are decompressed on the fly; the size limit, hash and format
+	// detection below all apply to the decompressed export.
+	compressedStream := bufio.NewReaderSize(job.TrackReader(source), optimalChunkSize)
+	compressedHead, _ := compressedStream.Peek(compressionMagicLength)
+	fileSource := io.Reader(compressedStream)
+	var decompressedStream *decompressingReader
+	if compression := detectUploadCompression(compressedHead); compression == UploadCompressionGzip || compression == UploadCompressionZstd {
+		var err error
+		decompressedStream, err = createDecompressingReader(compression, compressedStream)
+		if err != nil {
+			RecordError(ctx, err, "Failed to open compressed upload")
+			return nil, apperrors.CreateBadRequestError("Error decompressing the file: " + err.Error())
+		}
+		defer decompressedStream.Close()
+		fileSource = decompressedStream
+		filename = decompressedUploadFilename(filename)
+		SetSpanAttributes(ctx, attribute.String("file.compression", compression.String()))
+	}
 
-			RecordBusinessOperation(ctx, "duplicate_upload_detected", true, map[string]interface{}{
-				"filename":            handler.Filename,
-				"file_size_bytes":     actualFileSize,
-				"existing_dataset_id": existingDatasetID,
-				"file_hash":           fileHash[:16] + "...",
-			})
+	uploadStream := createUploadStreamReader(fileSource, getMaxUploadSize())
+	bufferedStream := bufio.NewReaderSize(uploadStream, optimalChunkSize)
 
-			return
+	// Short files return io.EOF alongside the partial data, which is all the sniffer needs
+	fileHead, peekErr := bufferedStream.Peek(formatSniffLength)
+	if peekErr != nil && peekErr != io.EOF && !errors.Is(peekErr, bufio.ErrBufferFull) {
+		if uploadStream.Exceeded() {
+			return nil, rejectOversizedUpload(ctx, filename)
+		}
+		if decompressedStream.RatioExceeded() {
+			return nil, rejectCompressionBomb(ctx, filename)
 		}
+		RecordError(ctx, peekErr, "Failed to read uploaded file")
+		return nil, apperrors.CreateBadRequestError("Error reading the file: " + peekErr.Error())
+	}
 
-		// Dataset no longer exists, remove the stale mapping and continue processing
-		logWarn(ctx, "Stale duplicate mapping found, dataset no longer exists",
-			"filename", handler.Filename,
-			"stale_dataset_id", existingDatasetID)
-		removeDuplicateMapping(existingDatasetID)
+	if estimatedFileSize <= 0 {
+		estimatedFileSize = int64(optimalChunkSize)
 	}
+	SetSpanAttributes(ctx, attribute.Int64("file.size_estimate", estimatedFileSize))
 
-	AddSpanEvent(ctx, "duplicate.check.completed", attribute.Bool("is_duplicate", isDuplicate))
-	duplicateSpan.End()
+	uploadFormat := detectUploadFormat(filename, fileHead)
+	SetSpanAttributes(ctx, attribute.String("file.format", uploadFormat.String()))
+
+	logDebug(ctx, "File upload stream opened",
+		"filename", filename,
+		"estimated_size_bytes", estimatedFileSize,
+		"format", uploadFormat.String())
 
 	parseStartTime := time.Now()
 	// Optimized pre-allocation based on file size estimation
-	estimatedPlayerCount := int(actualFileSize / 2048) // Rough estimation: ~2KB per player row
+	estimatedPlayerCount := int(estimatedFileSize / 2048) // Rough estimation: ~2KB per player row
 	if estimatedPlayerCount == 0 {
 		estimatedPlayerCount = 100 // Minimum reasonable estimate
 	}
 	optimalCapacity := calculateOptimalSliceCapacity(estimatedPlayerCount)
 	playersList := make([]Player, 0, optimalCapacity)
-	var processingError error
 
 	// Ensure configuration is initialized before processing players to avoid slow fallback path
 	if err := EnsureConfigInitialized(5 * time.Second); err != nil {
@@ -429,17 +460,24 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Dynamic buffer sizing based on available memory and system resources
-	bufferSize := calculateOptimalBufferSize(numWorkers, actualFileSize)
+	bufferSize := calculateOptimalBufferSize(numWorkers, estimatedFileSize)
 	rowCellsChan := make(chan []string, bufferSize)
 	resultsChan := make(chan PlayerParseResult, bufferSize)
 	var wg sync.WaitGroup
 
 	var headersSnapshot []string
+	var parseReport ParseReport
 
 	doneConsumingResults := make(chan struct{})
 	go func() {
 		defer close(doneConsumingResults)
 		for result := range resultsChan {
+			if parseReport.RowsSeen == 0 {
+				// First worker result means the header row has been read and rows are flowing
+				job.SetPhase(UploadPhaseParsingRows)
+			}
+			parseReport.RecordResult(result)
+			job.RecordRow(result.Err)
 			if result.Err == nil {
 				playersList = append(playersList, result.Player)
 			} else {
@@ -450,38 +488,52 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	// Start performance timer for parsing
-	parseTimer := CreateParseTimerWithContext(ctx, "html_parsing")
+	parseTimer := CreateParseTimerWithContext(ctx, uploadFormat.String()+"_parsing")
 
-	// Wrap file parsing in a child span using the already-read content
-	err = TraceFileProcessing(ctx, handler.Filename, actualFileSize, func(_ context.Context) error {
-		contentReader := strings.NewReader(string(fileContent))
-		return ParseHTMLPlayerTable(contentReader, &headersSnapshot, rowCellsChan, numWorkers, resultsChan, &wg)
+	// Wrap file parsing in a child span, reading straight from the upload stream
+	err := TraceFileProcessing(ctx, filename, estimatedFileSize, func(_ context.Context) error {
+		return parsePlayerTable(uploadFormat, bufferedStream, &headersSnapshot, rowCellsChan, numWorkers, resultsChan, &wg)
 	})
-	processingError = err
+	processingError := err
 
-	// Note: rowCellsChan is now closed by ParseHTMLPlayerTable function to prevent race conditions
-	LogDebug("HTML parsing attempt finished - channel closed by parser.")
+	// Note: rowCellsChan is closed by the format-specific parser to prevent race conditions
+	LogDebug("%s parsing attempt finished - channel closed by parser.", uploadFormat)
 
-	if processingError != nil {
-		RecordError(ctx, processingError, "HTML parsing failed")
-		log.Printf("Error during HTML parsing or worker setup: %v", processingError)
+	// The parser may stop before the end of the file (e.g. after </table>), so consume the
+	// rest to complete the hash and enforce the size limit on the whole upload.
+	if processingError == nil {
+		if drainErr := uploadStream.Drain(); drainErr != nil && !uploadStream.Exceeded() {
+			processingError = drainErr
+		}
+	}
+
+	if processingError != nil || uploadStream.Exceeded() || decompressedStream.RatioExceeded() {
 		if len(headersSnapshot) > 0 {
 			log.Println("Waiting for any potentially started workers after parsing error...")
 			wg.Wait()
 		}
 		close(resultsChan)
 		<-doneConsumingResults
-		http.Error(w, processingError.Error(), http.StatusInternalServerError)
-		return
+		if uploadStream.Exceeded() {
+			return nil, rejectOversizedUpload(ctx, filename)
+		}
+		if decompressedStream.RatioExceeded() {
+			return nil, rejectCompressionBomb(ctx, filename)
+		}
+		RecordError(ctx, processingError, "Player table parsing failed")
+		log.Printf("Error during %s parsing or worker setup: %v", uploadFormat, processingError)
+		return nil, apperrors.CreateProcessingError(processingError.Error())
 	}
 
+	actualFileSize := uploadStream.BytesRead()
+	SetSpanAttributes(ctx, attribute.Int64("file.size", actualFileSize))
+
 	if len(headersSnapshot) == 0 {
-		logError(ctx, "Critical: No headers were parsed from the HTML file")
+		logError(ctx, "Critical: No headers were parsed from the uploaded file", "format", uploadFormat.String())
 		SetSpanAttributes(ctx, attribute.String("error.type", "no_headers_parsed"))
 		close(resultsChan)
 		<-doneConsumingResults
-		http.Error(w, "Could not parse table headers, no data processed.", http.StatusInternalServerError)
-		return
+		return nil, apperrors.CreateProcessingError("Could not parse table headers, no data processed.")
 	}
 
 	AddSpanEvent(ctx, "workers.waiting_for_completion")
@@ -497,34 +549,122 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	LogDebug("Results consumer goroutine finished processing all items.")
 
 	// Finish performance timing
-	parseTimer.Finish(int64(len(playersList)), 0) // No errors counted here since workers handle errors
+	parseTimer.Finish(int64(len(playersList)), int64(parseReport.RowsSkipped))
+
+	canonicalHeaders, exportProfile := resolveExportHeaders(headersSnapshot)
+	parseReport.HeaderLanguage = exportProfile.Language
+	parseReport.ExportProfile = exportProfile
+	parseReport.UnrecognisedHeaders = findUnrecognisedHeaders(canonicalHeaders)
+	if parseReport.RowsSkipped > 0 || len(parseReport.UnrecognisedHeaders) > 0 {
+		logInfo(ctx, "Upload parsed with skipped rows or unrecognised headers",
+			"filename", filename,
+			"rows_seen", parseReport.RowsSeen,
+			"rows_skipped", parseReport.RowsSkipped,
+			"unrecognised_headers", len(parseReport.UnrecognisedHeaders))
+	}
+	SetSpanAttributes(ctx,
+		attribute.Int("parse.rows_seen", parseReport.RowsSeen),
+		attribute.Int("parse.rows_skipped", parseReport.RowsSkipped),
+		attribute.Int("parse.rows_masked", parseReport.RowsMasked),
+		attribute.String("parse.header_language", exportProfile.Language),
+		attribute.String("parse.export_profile", exportProfile.Key),
+	)
 
-	finalDatasetCurrencySymbol := "$" // Default
-	if len(playersList) > 0 {
-		var foundSymbol bool
-		for i := range playersList {
-			_, _, tvSymbol := ParseMonetaryValueGo(playersList[i].TransferValue) // Assumes ParseMonetaryValueGo is in parsing.go
-			if tvSymbol != "" {
-				finalDatasetCurrencySymbol = tvSymbol
-				foundSymbol = true
-				break
-			}
-			_, _, wSymbol := ParseMonetaryValueGo(playersList[i].Wage)
-			if wSymbol != "" {
-				finalDatasetCurrencySymbol = wSymbol
-				foundSymbol = true
-				break
+	return &parsedUpload{
+		Filename:      uploadedFilename,
+		Players:       playersList,
+		Report:        parseReport,
+		Format:        uploadFormat,
+		FileHash:      uploadStream.Sum(),
+		FileSize:      actualFileSize,
+		NumWorkers:    numWorkers,
+		ParseDuration: time.Since(parseStartTime),
+	}, nil
+}
+
+// processUploadStream runs the upload pipeline over a single file stream: format detection,
+// parsing with the worker pool, duplicate detection and storage. Percentiles are left to the
+// caller. It is shared by synchronous uploads (job is nil) and background upload jobs, which
+// use job to report progress. The returned bool is true when the file matched an existing dataset.
+func processUploadStream(ctx context.Context, filename string, source io.Reader, estimatedFileSize int64, job *UploadJob) (UploadResponse, bool, *apperrors.AppError) {
+	startTime := time.Now()
+
+	files, appErr := parseUploadSource(ctx, filename, source, estimatedFileSize, job)
+	if appErr != nil {
+		return UploadResponse{}, false, appErr
+	}
+	if len(files) > 1 {
+		// The exports of one archive are views of the same save, joined as in a merge upload
+		response, appErr := storeMergedUpload(ctx, files, uploadMergeOptions{Enabled: true}, job)
+		return response, false, appErr
+	}
+	parsed := files[0]
+	playersList := parsed.Players
+	parseReport := parsed.Report
+	actualFileSize := parsed.FileSize
+	uploadFormat := parsed.Format
+	numWorkers := parsed.NumWorkers
+	parseDuration := parsed.ParseDuration
+
+	job.SetPhase(UploadPhaseCalculating)
+
+	// Check for duplicate upload now that the streamed hash covers the whole file
+	ctx, duplicateSpan := StartSpan(ctx, "duplicate.check")
+	fileHash := parsed.FileHash
+	existingDatasetID, isDuplicate := checkForDuplicateUpload(fileHash)
+
+	SetSpanAttributes(ctx,
+		attribute.String("file.hash", fileHash[:16]+"..."), // Only log first 16 chars for security
+		attribute.Bool("duplicate.found", isDuplicate),
+	)
+
+	if isDuplicate {
+		// Verify the existing dataset still exists in storage
+		if _, currencySymbol, found := GetPlayerData(existingDatasetID); found {
+			logInfo(ctx, "Duplicate upload detected, redirecting to existing dataset",
+				"filename", filename,
+				"existing_dataset_id", existingDatasetID,
+				"file_hash", fileHash[:16]+"...")
+
+			SetSpanAttributes(ctx,
+				attribute.String("duplicate.existing_dataset_id", existingDatasetID),
+				attribute.String("duplicate.action", "redirect_to_existing"),
+			)
+			duplicateSpan.End()
+
+			// Return the existing dataset info; the freshly parsed players are discarded
+			response := UploadResponse{
+				DatasetID:              existingDatasetID,
+				Message:                "Duplicate file detected. Redirected to existing dataset.",
+				DetectedCurrencySymbol: currencySymbol,
+				ParseReport:            &parseReport,
 			}
+
+			RecordBusinessOperation(ctx, "duplicate_upload_detected", true, map[string]interface{}{
+				"filename":            filename,
+				"file_size_bytes":     actualFileSize,
+				"existing_dataset_id": existingDatasetID,
+				"file_hash":           fileHash[:16] + "...",
+			})
+
+			return response, true, nil
 		}
-		if !foundSymbol {
-			log.Println("No currency symbol detected from parsed player monetary values, using default '$'.")
-		}
+
+		// Dataset no longer exists, remove the stale mapping and continue processing
+		logWarn(ctx, "Stale duplicate mapping found, dataset no longer exists",
+			"filename", filename,
+			"stale_dataset_id", existingDatasetID)
+		removeDuplicateMapping(existingDatasetID)
 	}
 
-	parseDuration := time.Since(parseStartTime)
+	AddSpanEvent(ctx, "duplicate.check.completed", attribute.Bool("is_duplicate", isDuplicate))
+	duplicateSpan.End()
+
+	finalDatasetCurrencySymbol := detectDatasetCurrency(playersList)
 	datasetID := uuid.New().String()
 
 	// Store data immediately in memory for fast access (without percentiles initially)
+	job.SetPhase(UploadPhaseStoring)
 	ctx, storageSpan := StartSpan(ctx, "storage.save_dataset_async")
 	SetSpanAttributes(ctx,
 		attribute.String("dataset.id", datasetID),
@@ -534,7 +674,12 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Use async storage for performance - data available immediately in memory
-	SetPlayerDataAsync(datasetID, playersList, finalDatasetCurrencySymbol)
+	SetDatasetAsync(datasetID, DatasetData{
+		Players:        playersList,
+		CurrencySymbol: finalDatasetCurrencySymbol,
+		ExportProfile:  parseReport.ExportProfile.Key,
+		Metadata:       createUploadMetadata(parsed),
+	})
 	storageSpan.End()
 
 	// Store the file hash mapping for duplicate detection
@@ -549,109 +694,12 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 		"player_count", len(playersList),
 		"detected_currency", finalDatasetCurrencySymbol)
 
-	// Send response immediately - percentiles will be calculated asynchronously
-	response := UploadResponse{DatasetID: datasetID, Message: "File uploaded and parsed successfully.", DetectedCurrencySymbol: finalDatasetCurrencySymbol}
-	w.Header().Set("Content-Type", "application/json")
-	setCORSHeaders(w, r)
-
-	ctx, responseSpan := StartSpan(ctx, "response.encode")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		RecordError(ctx, err, "Failed to encode JSON response")
-		log.Printf("Error encoding JSON response for upload: %v", err)
-		http.Error(w, "Error encoding JSON response: "+err.Error(), http.StatusInternalServerError)
-		return
+	response := UploadResponse{
+		DatasetID:              datasetID,
+		Message:                "File uploaded and parsed successfully.",
+		DetectedCurrencySymbol: finalDatasetCurrencySymbol,
+		ParseReport:            &parseReport,
 	}
-	responseSpan.End()
-
-	// Calculate percentiles asynchronously after response is sent
-	go func() {
-		percentileCtx := context.Background()
-		percentileCtx, percentileSpan := StartSpan(percentileCtx, "percentiles.calculate_upload_async")
-		defer percentileSpan.End()
-
-		SetSpanAttributes(percentileCtx,
-			attribute.String("dataset.id", datasetID),
-			attribute.Int("dataset.player_count", len(playersList)),
-			attribute.String("operation.type", "async_percentile_calculation"),
-		)
-
-		startTime := time.Now()
-		logDebug(percentileCtx, "Starting async percentile calculation after response sent",
-			"dataset_id", datasetID,
-			"player_count", len(playersList))
-
-		// Get the stored data and calculate percentiles
-		storedPlayers, storedCurrency, found := GetPlayerData(datasetID)
-		if !found {
-			LogWarn("Could not retrieve stored dataset %s for percentile calculation", sanitizeForLogging(datasetID))
-			return
-		}
-
-		// Make a deep copy to avoid race conditions during calculation
-		// Use the original deep copy to avoid any concurrency issues with optimizations
-		playersCopyForPercentiles := make([]Player, len(storedPlayers))
-		for i := range storedPlayers {
-			playersCopyForPercentiles[i] = storedPlayers[i]
-			// Deep copy all the maps to prevent race conditions
-			if storedPlayers[i].Attributes != nil {
-				playersCopyForPercentiles[i].Attributes = make(map[string]string)
-				for k, v := range storedPlayers[i].Attributes {
-					playersCopyForPercentiles[i].Attributes[k] = v
-				}
-			}
-			if storedPlayers[i].NumericAttributes != nil {
-				playersCopyForPercentiles[i].NumericAttributes = make(map[string]int)
-				for k, v := range storedPlayers[i].NumericAttributes {
-					playersCopyForPercentiles[i].NumericAttributes[k] = v
-				}
-			}
-			if storedPlayers[i].PerformanceStatsNumeric != nil {
-				playersCopyForPercentiles[i].PerformanceStatsNumeric = make(map[string]float64)
-				for k, v := range storedPlayers[i].PerformanceStatsNumeric {
-					playersCopyForPercentiles[i].PerformanceStatsNumeric[k] = v
-				}
-			}
-			if storedPlayers[i].PerformancePercentiles != nil {
-				playersCopyForPercentiles[i].PerformancePercentiles = make(map[string]map[string]float64)
-				for group, stats := range storedPlayers[i].PerformancePercentiles {
-					playersCopyForPercentiles[i].PerformancePercentiles[group] = make(map[string]float64)
-					for stat, value := range stats {
-						playersCopyForPercentiles[i].PerformancePercentiles[group][stat] = value
-					}
-				}
-			}
-			if storedPlayers[i].RoleSpecificOveralls != nil {
-				playersCopyForPercentiles[i].RoleSpecificOveralls = make([]RoleOverallScore, len(storedPlayers[i].RoleSpecificOveralls))
-				copy(playersCopyForPercentiles[i].RoleSpecificOveralls, storedPlayers[i].RoleSpecificOveralls)
-			}
-			if storedPlayers[i].ShortPositions != nil {
-				playersCopyForPercentiles[i].ShortPositions = make([]string, len(storedPlayers[i].ShortPositions))
-				copy(playersCopyForPercentiles[i].ShortPositions, storedPlayers[i].ShortPositions)
-			}
-			if storedPlayers[i].ParsedPositions != nil {
-				playersCopyForPercentiles[i].ParsedPositions = make([]string, len(storedPlayers[i].ParsedPositions))
-				copy(playersCopyForPercentiles[i].ParsedPositions, storedPlayers[i].ParsedPositions)
-			}
-			if storedPlayers[i].PositionGroups != nil {
-				playersCopyForPercentiles[i].PositionGroups = make([]string, len(storedPlayers[i].PositionGroups))
-				copy(playersCopyForPercentiles[i].PositionGroups, storedPlayers[i].PositionGroups)
-			}
-		}
-
-		// Calculate percentiles for all division filters to ensure stability
-		CalculatePlayerPerformancePercentiles(playersCopyForPercentiles)
-
-		// Update the stored data with calculated percentiles (use sync SetPlayerData to avoid additional async operations)
-		SetPlayerData(datasetID, playersCopyForPercentiles, storedCurrency)
-
-		duration := time.Since(startTime)
-		SetSpanAttributes(percentileCtx,
-			attribute.Int64("percentile_calculation.duration_ms", duration.Milliseconds()),
-			attribute.String("percentile_calculation.status", "success"),
-		)
-
-		LogInfo("Completed async percentile calculation for dataset %s in %v", sanitizeForLogging(datasetID), duration)
-	}()
 
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
@@ -664,9 +712,11 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Record comprehensive business operation metrics
 	RecordBusinessOperation(ctx, "file_upload", true, map[string]interface{}{
-		"filename":          handler.Filename,
+		"filename":          filename,
 		"file_size_bytes":   actualFileSize,
 		"players_processed": len(playersList),
+		"file_format":       uploadFormat.String(),
+		"export_profile":    parseReport.ExportProfile.Key,
 		"workers_used":      numWorkers,
 		"currency_detected": finalDatasetCurrencySymbol,
 		"rows_per_second":   rowsPerSecond,
@@ -690,7 +740,7 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Log performance metrics with trace context
 	logDebug(ctx, "Upload processing completed",
-		"filename", handler.Filename,
+		"filename", filename,
 		"file_size_kb", actualFileSize/1024,
 		"total_duration_ms", totalDuration.Milliseconds(),
 		"parse_duration_ms", parseDuration.Milliseconds(),
@@ -705,6 +755,226 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	// Log immediate performance and memory stats after parsing completion
 	LogImmediatePerformanceStats()
 	LogImmediateMemoryStats(ctx)
+
+	return response, false, nil
+}
+
+// detectDatasetCurrency returns the currency symbol of the first parsed transfer value or
+// wage that carries one, defaulting to "$".
+func detectDatasetCurrency(playersList []Player) string {
+	finalDatasetCurrencySymbol := "$" // Default
+	if len(playersList) > 0 {
+		var foundSymbol bool
+		for i := range playersList {
+			_, _, tvSymbol := ParseMonetaryValueGo(playersList[i].TransferValue) // Assumes ParseMonetaryValueGo is in parsing.go
+			if tvSymbol != "" {
+				finalDatasetCurrencySymbol = tvSymbol
+				foundSymbol = true
+				break
+			}
+			_, _, wSymbol := ParseMonetaryValueGo(playersList[i].Wage)
+			if wSymbol != "" {
+				finalDatasetCurrencySymbol = wSymbol
+				foundSymbol = true
+				break
+			}
+		}
+		if !foundSymbol {
+			log.Println("No currency symbol detected from parsed player monetary values, using default '$'.")
+		}
+	}
+
+	return finalDatasetCurrencySymbol
+}
+
+// calculateUploadPercentiles computes performance percentiles for a freshly stored dataset
+// and writes them back to storage. Uploads return before this runs, so it works on a deep
+// copy of the stored players to avoid racing with readers.
+func calculateUploadPercentiles(datasetID string, job *UploadJob) {
+	percentileCtx := context.Background()
+	percentileCtx, percentileSpan := StartSpan(percentileCtx, "percentiles.calculate_upload_async")
+	defer percentileSpan.End()
+
+	SetSpanAttributes(percentileCtx,
+		attribute.String("dataset.id", datasetID),
+		attribute.String("operation.type", "async_percentile_calculation"),
+	)
+
+	startTime := time.Now()
+	logDebug(percentileCtx, "Starting async percentile calculation after response sent",
+		"dataset_id", datasetID)
+
+	// Get the stored data and calculate percentiles
+	storedPlayers, storedCurrency, found := GetPlayerData(datasetID)
+	if !found {
+		LogWarn("Could not retrieve stored dataset %s for percentile calculation", sanitizeForLogging(datasetID))
+		return
+	}
+
+	// Make a deep copy to avoid race conditions during calculation
+	// Use the original deep copy to avoid any concurrency issues with optimizations
+	playersCopyForPercentiles := make([]Player, len(storedPlayers))
+	for i := range storedPlayers {
+		playersCopyForPercentiles[i] = storedPlayers[i]
+		// Deep copy all the maps to prevent race conditions
+		if storedPlayers[i].Attributes != nil {
+			playersCopyForPercentiles[i].Attributes = make(map[string]string)
+			for k, v := range storedPlayers[i].Attributes {
+				playersCopyForPercentiles[i].Attributes[k] = v
+			}
+		}
+		if storedPlayers[i].NumericAttributes != nil {
+			playersCopyForPercentiles[i].NumericAttributes = make(map[string]int)
+			for k, v := range storedPlayers[i].NumericAttributes {
+				playersCopyForPercentiles[i].NumericAttributes[k] = v
+			}
+		}
+		if storedPlayers[i].PerformanceStatsNumeric != nil {
+			playersCopyForPercentiles[i].PerformanceStatsNumeric = make(map[string]float64)
+			for k, v := range storedPlayers[i].PerformanceStatsNumeric {
+				playersCopyForPercentiles[i].PerformanceStatsNumeric[k] = v
+			}
+		}
+		if storedPlayers[i].PerformancePercentiles != nil {
+			playersCopyForPercentiles[i].PerformancePercentiles = make(map[string]map[string]float64)
+			for group, stats := range storedPlayers[i].PerformancePercentiles {
+				playersCopyForPercentiles[i].PerformancePercentiles[group] = make(map[string]float64)
+				for stat, value := range stats {
+					playersCopyForPercentiles[i].PerformancePercentiles[group][stat] = value
+				}
+			}
+		}
+		if storedPlayers[i].RoleSpecificOveralls != nil {
+			playersCopyForPercentiles[i].RoleSpecificOveralls = make([]RoleOverallScore, len(storedPlayers[i].RoleSpecificOveralls))
+			copy(playersCopyForPercentiles[i].RoleSpecificOveralls, storedPlayers[i].RoleSpecificOveralls)
+		}
+		if storedPlayers[i].ShortPositions != nil {
+			playersCopyForPercentiles[i].ShortPositions = make([]string, len(storedPlayers[i].ShortPositions))
+			copy(playersCopyForPercentiles[i].ShortPositions, storedPlayers[i].ShortPositions)
+		}
+		if storedPlayers[i].ParsedPositions != nil {
+			playersCopyForPercentiles[i].ParsedPositions = make([]string, len(storedPlayers[i].ParsedPositions))
+			copy(playersCopyForPercentiles[i].ParsedPositions, storedPlayers[i].ParsedPositions)
+		}
+		if storedPlayers[i].PositionGroups != nil {
+			playersCopyForPercentiles[i].PositionGroups = make([]string, len(storedPlayers[i].PositionGroups))
+			copy(playersCopyForPercentiles[i].PositionGroups, storedPlayers[i].PositionGroups)
+		}
+	}
+
+	job.SetPhase(UploadPhasePercentiles)
+	SetSpanAttributes(percentileCtx, attribute.Int("dataset.player_count", len(storedPlayers)))
+
+	// Calculate percentiles for all division filters to ensure stability
+	CalculatePlayerPerformancePercentiles(playersCopyForPercentiles)
+
+	// Update the stored data with calculated percentiles (use sync SetPlayerData to avoid additional async operations)
+	SetPlayerData(datasetID, playersCopyForPercentiles, storedCurrency)
+
+	duration := time.Since(startTime)
+	SetSpanAttributes(percentileCtx,
+		attribute.Int64("percentile_calculation.duration_ms", duration.Milliseconds()),
+		attribute.String("percentile_calculation.status", "success"),
+	)
+
+	LogInfo("Completed async percentile calculation for dataset %s in %v", sanitizeForLogging(datasetID), duration)
+}
+
+// percentileCacheKey returns the percentiles: cache key for a dataset's players with
+// percentiles calculated for a division filter.
+func percentileCacheKey(datasetID, divisionFilterStr, targetDivision string) string {
+	return fmt.Sprintf("percentiles:%s:%s:%s", datasetID, divisionFilterStr, targetDivision)
+}
+
+// percentileCalculator calculates percentiles for a division filter over players that the
+// calculation may modify, returning the players with percentiles.
+type percentileCalculator func(ctx context.Context, players []Player, divisionFilter DivisionFilter, targetDivision string) []Player
+
+// calculatePercentilesInline calculates percentiles on the calling goroutine, using the
+// global-percentile algorithm when no division filter applies.
+func calculatePercentilesInline(_ context.Context, players []Player, divisionFilter DivisionFilter, targetDivision string) []Player {
+	if divisionFilter != DivisionFilterAll {
+		CalculatePlayerPerformancePercentilesWithDivisionFilter(players, divisionFilter, targetDivision)
+	} else {
+		CalculatePlayerPerformancePercentiles(players)
+	}
+	return players
+}
+
+// loadPlayersWithPercentiles returns a dataset's players with ratings recalculated and
+// percentiles calculated for the division filter, from the percentiles: cache when possible.
+// found is false when the dataset does not exist.
+func loadPlayersWithPercentiles(ctx context.Context, datasetID, divisionFilterStr, targetDivision string) (players []Player, currencySymbol string, cacheHit, found bool) {
+	return loadPlayersWithPercentilesUsing(ctx, datasetID, divisionFilterStr, targetDivision, calculatePercentilesInline)
+}
+
+// loadPlayersWithPercentilesUsing is loadPlayersWithPercentiles with the calculation run on a
+// cache miss supplied by the caller. Every calculator shares the percentiles: cache entry.
+func loadPlayersWithPercentilesUsing(ctx context.Context, datasetID, divisionFilterStr, targetDivision string, calculate percentileCalculator) (players []Player, currencySymbol string, cacheHit, found bool) {
+	// Create cache key for percentile-calculated data (separate from final filtered result)
+	cacheKey := percentileCacheKey(datasetID, divisionFilterStr, targetDivision)
+
+	// Parse division filter early
+	divisionFilter := parseDivisionFilter(divisionFilterStr)
+
+	// Check cache for percentile-calculated players first
+	if cachedData, cacheFound := getFromMemCache(cacheKey); cacheFound {
+		if cachedResult, ok := cachedData.(struct {
+			Players        []Player
+			CurrencySymbol string
+		}); ok {
+			logDebug(ctx, "Using cached percentile data", "dataset_id", datasetID, "division_filter", divisionFilterStr)
+			SetSpanAttributes(ctx, attribute.Bool("percentile_cache.hit", true))
+			return cachedResult.Players, cachedResult.CurrencySymbol, true, true
+		}
+	}
+
+	// Cache miss - need to load and calculate percentiles
+	SetSpanAttributes(ctx, attribute.Bool("percentile_cache.hit", false))
+
+	// Use the new storage interface to get player data
+	ctx, dataSpan := StartSpan(ctx, "storage.get_dataset")
+	players, currencySymbol, found = GetPlayerData(datasetID)
+	dataSpan.End()
+
+	if !found {
+		return nil, "", false, false
+	}
+
+	SetSpanAttributes(ctx,
+		attribute.Int("dataset.initial_player_count", len(players)),
+		attribute.String("dataset.currency", currencySymbol),
+	)
+
+	// Recalculate all player ratings based on the current calculation method setting
+	ctx, recalcSpan := StartSpan(ctx, "ratings.recalculate")
+	players = RecalculateAllPlayersRatings(players)
+	recalcSpan.End()
+
+	// Calculate percentiles with appropriate filtering using optimized algorithm
+	ctx, percentileSpan := StartSpan(ctx, "percentiles.calculate")
+	// Make a deep copy of players to avoid modifying the stored data and prevent race conditions
+	// Use optimized deep copy for better memory efficiency
+	playersCopy := OptimizedDeepCopyPlayers(players)
+	players = calculate(ctx, playersCopy, divisionFilter, targetDivision)
+	percentileSpan.End()
+
+	// Cache the percentile-calculated data for future requests
+	cacheData := struct {
+		Players        []Player
+		CurrencySymbol string
+	}{
+		Players:        players,
+		CurrencySymbol: currencySymbol,
+	}
+	setInMemCacheForDataset(cacheKey, cacheData, 10*time.Minute) // Cache for 10 minutes
+
+	logDebug(ctx, "Calculated and cached percentiles",
+		"dataset_id", datasetID,
+		"division_filter", divisionFilterStr,
+		"player_count", len(players))
+
+	return players, currencySymbol, false, true
 }
 
 // playerDataHandler handles GET requests for retrieving player data by dataset ID.
@@ -747,6 +1017,11 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 
 	SetSpanAttributes(ctx, attribute.String("dataset.id", datasetID))
 
+	if len(pathParts) > 1 && pathParts[1] != "" {
+		playerDetailHandler(w, r, datasetID, pathParts[1])
+		return
+	}
+
 	queryValues := r.URL.Query()
 	filterPosition := queryValues.Get("position")
 	filterRole := queryValues.Get("role")
@@ -755,9 +1030,27 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 	minTransferValueStr := queryValues.Get("minTransferValue")
 	maxTransferValueStr := queryValues.Get("maxTransferValue")
 	maxSalaryStr := queryValues.Get("maxSalary")
+	salaryPeriod, _ := ParseWagePeriod(queryValues.Get("wagePeriod"))
 	divisionFilterStr := queryValues.Get("divisionFilter") // "all", "same", "top5"
 	targetDivision := queryValues.Get("targetDivision")
 	positionCompare := queryValues.Get("positionCompare") // "all", "broad", "detailed"
+	minHeightStr := queryValues.Get("minHeight")
+	maxHeightStr := queryValues.Get("maxHeight")
+	minWeightStr := queryValues.Get("minWeight")
+	maxWeightStr := queryValues.Get("maxWeight")
+	preferredFootFilter := normalizePreferredFoot(queryValues.Get("preferredFoot"))
+	contractExpiresBefore := queryValues.Get("contractExpiresBefore") // YYYY-MM-DD
+	bornAfter := queryValues.Get("bornAfter")                         // YYYY-MM-DD
+	bornBefore := queryValues.Get("bornBefore")                       // YYYY-MM-DD
+	requestedCurrency := queryValues.Get("currency")                  // ISO code or symbol, e.g. "EUR" or "€"
+	filterExpressionStr := queryValues.Get("filter")                  // Filter expression, e.g. "Fin>=15 AND pos:ST"
+	listQuery, err := parsePlayerListQuery(queryValues)               // limit, cursor, sort and fields
+	if err != nil {
+		logWarn(ctx, "Player list query rejected", "dataset_id", datasetID, "error", err)
+		SetSpanAttributes(ctx, attribute.String("error.type", "invalid_list_query"))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	logDebug(ctx, "Processing player data request",
 		"dataset_id", datasetID,
@@ -768,105 +1061,50 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 		"min_transfer_value", minTransferValueStr,
 		"max_transfer_value", maxTransferValueStr,
 		"max_salary", maxSalaryStr,
+		"wage_period", salaryPeriod,
 		"division_filter", divisionFilterStr,
 		"target_division", targetDivision,
-		"position_compare", positionCompare)
-
-	// Create cache key for percentile-calculated data (separate from final filtered result)
-	percentileCacheKey := fmt.Sprintf("percentiles:%s:%s:%s", datasetID, divisionFilterStr, targetDivision)
-
-	// Parse division filter early
-	var divisionFilter = DivisionFilterAll
-	switch divisionFilterStr {
-	case "same":
-		divisionFilter = DivisionFilterSame
-	case "top5":
-		divisionFilter = DivisionFilterTop5
-	case "all", "":
-		divisionFilter = DivisionFilterAll
-	}
-
-	// Check cache for percentile-calculated players first
-	var players []Player
-	var currencySymbol string
-	var found bool
-
-	if cachedData, cacheFound := getFromMemCache(percentileCacheKey); cacheFound {
-		if cachedResult, ok := cachedData.(struct {
-			Players        []Player
-			CurrencySymbol string
-		}); ok {
-			players = cachedResult.Players
-			currencySymbol = cachedResult.CurrencySymbol
-			found = true
-			logDebug(ctx, "Using cached percentile data", "dataset_id", datasetID, "division_filter", divisionFilterStr)
-			SetSpanAttributes(ctx, attribute.Bool("percentile_cache.hit", true))
-		}
-	}
-
+		"position_compare", positionCompare,
+		"min_height", minHeightStr,
+		"max_height", maxHeightStr,
+		"min_weight", minWeightStr,
+		"max_weight", maxWeightStr,
+		"preferred_foot", preferredFootFilter,
+		"contract_expires_before", contractExpiresBefore,
+		"born_after", bornAfter,
+		"born_before", bornBefore,
+		"currency", requestedCurrency,
+		"filter", filterExpressionStr,
+		"sort", listQuery.Sort,
+		"descending", listQuery.Descending,
+		"limit", listQuery.Limit,
+		"fields", listQuery.Fields)
+
+	listPlayers, found, err := loadPlayerListPlayers(ctx, datasetID, divisionFilterStr, targetDivision, queryValues, listQuery, salaryPeriod)
 	if !found {
-		// Cache miss - need to load and calculate percentiles
-		SetSpanAttributes(ctx, attribute.Bool("percentile_cache.hit", false))
-
-		// Use the new storage interface to get player data
-		ctx, dataSpan := StartSpan(ctx, "storage.get_dataset")
-		players, currencySymbol, found = GetPlayerData(datasetID)
-		dataSpan.End()
-
-		if !found {
-			logWarn(ctx, "Player data not found", "dataset_id", datasetID)
-			SetSpanAttributes(ctx, attribute.String("error.type", "dataset_not_found"))
-			http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
-			return
-		}
-
-		SetSpanAttributes(ctx,
-			attribute.Int("dataset.initial_player_count", len(players)),
-			attribute.String("dataset.currency", currencySymbol),
-		)
-
-		// Recalculate all player ratings based on the current calculation method setting
-		ctx, recalcSpan := StartSpan(ctx, "ratings.recalculate")
-		players = RecalculateAllPlayersRatings(players)
-		recalcSpan.End()
-
-		// Calculate percentiles with appropriate filtering using optimized algorithm
-		ctx, percentileSpan := StartSpan(ctx, "percentiles.calculate")
-		// Make a deep copy of players to avoid modifying the stored data and prevent race conditions
-		// Use optimized deep copy for better memory efficiency
-		playersCopy := OptimizedDeepCopyPlayers(players)
-
-		if divisionFilter != DivisionFilterAll {
-			// Recalculate percentiles with division filter
-			CalculatePlayerPerformancePercentilesWithDivisionFilter(playersCopy, divisionFilter, targetDivision)
-		} else {
-			// Calculate global percentiles using optimized algorithm
-			CalculatePlayerPerformancePercentiles(playersCopy)
-		}
-
-		players = playersCopy
-		percentileSpan.End()
-
-		// Cache the percentile-calculated data for future requests
-		cacheData := struct {
-			Players        []Player
-			CurrencySymbol string
-		}{
-			Players:        players,
-			CurrencySymbol: currencySymbol,
-		}
-		setInMemCacheForDataset(percentileCacheKey, cacheData, 10*time.Minute) // Cache for 10 minutes
-
-		logDebug(ctx, "Calculated and cached percentiles",
-			"dataset_id", datasetID,
-			"division_filter", divisionFilterStr,
-			"player_count", len(players))
+		logWarn(ctx, "Player data not found", "dataset_id", datasetID)
+		SetSpanAttributes(ctx, attribute.String("error.type", "dataset_not_found"))
+		http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
+		return
 	}
+	if err != nil {
+		logWarn(ctx, "Player list query rejected", "dataset_id", datasetID, "sort", listQuery.Sort, "error", err)
+		SetSpanAttributes(ctx, attribute.String("error.type", "invalid_list_query"))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	players, currencySymbol, listSort := listPlayers.players, listPlayers.currencySymbol, listPlayers.sort
 
-	// Create cache key for final filtered result
-	finalCacheKey := fmt.Sprintf("filtered:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
+	// Create cache key for final filtered result. Cursors are tied to the filters and sort rather
+	// than to a cached result, so they keep working after either cache entry expires.
+	filtersKey := fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
 		datasetID, filterPosition, filterRole, minAgeStr, maxAgeStr,
-		minTransferValueStr, maxTransferValueStr, maxSalaryStr, divisionFilterStr, targetDivision)
+		minTransferValueStr, maxTransferValueStr, maxSalaryStr, salaryPeriod, divisionFilterStr, targetDivision,
+		minHeightStr, maxHeightStr, minWeightStr, maxWeightStr, preferredFootFilter, contractExpiresBefore,
+		bornAfter, bornBefore, requestedCurrency, filterExpressionStr)
+	querySignature := playerQuerySignature(filtersKey, listQuery)
+	finalCacheKey := fmt.Sprintf("filtered:%s:%s:%s:%d:%s", filtersKey, querySignature, listQuery.Cursor,
+		listQuery.Limit, strings.Join(listQuery.Fields, ","))
 
 	// Check cache for final filtered result
 	if cachedFiltered, cacheFound := getFromMemCache(finalCacheKey); cacheFound {
@@ -886,6 +1124,29 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 
 	SetSpanAttributes(ctx, attribute.Bool("final_cache.hit", false))
 
+	// Convert before filtering so the transfer value and salary filters are read in the
+	// requested currency too
+	baseCurrency := datasetBaseCurrency(datasetID, currencySymbol)
+	players, currencySymbol, err = convertPlayersForRequest(players, currencySymbol, baseCurrency, requestedCurrency)
+	if err != nil {
+		logWarn(ctx, "Currency conversion rejected", "dataset_id", datasetID, "currency", requestedCurrency, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The filter expression is compiled once and evaluated on the chunked worker pool; the
+	// fixed filters below then run on the players it kept
+	if filterExpressionStr != "" {
+		expression, err := CompilePlayerFilterExpression(filterExpressionStr, players)
+		if err != nil {
+			logWarn(ctx, "Filter expression rejected", "dataset_id", datasetID, "filter", filterExpressionStr, "error", err)
+			SetSpanAttributes(ctx, attribute.String("error.type", "invalid_filter_expression"))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		players = playerExpressionFilter.FilterPlayersAsync(ctx, players, PlayerFilter{Expression: expression})
+	}
+
 	data := struct {
 		Players        []Player
 		CurrencySymbol string
@@ -896,6 +1157,9 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 	var minAge, maxAge = -1, -1
 	var minTransferValue, maxTransferValue int64 = -1, -1
 	var maxSalary int64 = -1
+	var minHeight, maxHeight = -1, -1
+	var minWeight, maxWeight = -1, -1
+	contractExpiryCutoff, bornAfterCutoff, bornBeforeCutoff := "", "", ""
 
 	if val, err := strconv.Atoi(minAgeStr); err == nil {
 		minAge = val
@@ -910,7 +1174,29 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 		maxTransferValue = val
 	}
 	if val, err := strconv.ParseInt(maxSalaryStr, 10, 64); err == nil {
-		maxSalary = val
+		// maxSalary is given in the requested wagePeriod; stored wages are weekly
+		maxSalary = ConvertWageAmount(val, salaryPeriod, WagePeriodWeekly)
+	}
+	if val, err := strconv.Atoi(minHeightStr); err == nil {
+		minHeight = val
+	}
+	if val, err := strconv.Atoi(maxHeightStr); err == nil {
+		maxHeight = val
+	}
+	if val, err := strconv.Atoi(minWeightStr); err == nil {
+		minWeight = val
+	}
+	if val, err := strconv.Atoi(maxWeightStr); err == nil {
+		maxWeight = val
+	}
+	if cutoff, ok := parseFMDate(contractExpiresBefore); ok {
+		contractExpiryCutoff = cutoff.Format(playerDetailDateLayout)
+	}
+	if cutoff, ok := parseFMDate(bornAfter); ok {
+		bornAfterCutoff = cutoff.Format(playerDetailDateLayout)
+	}
+	if cutoff, ok := parseFMDate(bornBefore); ok {
+		bornBeforeCutoff = cutoff.Format(playerDetailDateLayout)
 	}
 
 	for i := range data.Players {
@@ -942,10 +1228,12 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		if minTransferValue != -1 && playerCopy.TransferValueAmount < minTransferValue {
+		// Scouted ranges are compared on their estimate rather than the upper bound
+		transferValueEstimate := playerTransferValueEstimate(&playerCopy)
+		if minTransferValue != -1 && transferValueEstimate < minTransferValue {
 			continue
 		}
-		if maxTransferValue != -1 && playerCopy.TransferValueAmount > maxTransferValue {
+		if maxTransferValue != -1 && transferValueEstimate > maxTransferValue {
 			continue
 		}
 
@@ -953,6 +1241,34 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// Players whose height, weight, contract or date of birth was not exported cannot satisfy
+		// these filters
+		if minHeight != -1 && (playerCopy.HeightCm == 0 || playerCopy.HeightCm < minHeight) {
+			continue
+		}
+		if maxHeight != -1 && (playerCopy.HeightCm == 0 || playerCopy.HeightCm > maxHeight) {
+			continue
+		}
+		if minWeight != -1 && (playerCopy.WeightKg == 0 || playerCopy.WeightKg < minWeight) {
+			continue
+		}
+		if maxWeight != -1 && (playerCopy.WeightKg == 0 || playerCopy.WeightKg > maxWeight) {
+			continue
+		}
+		if preferredFootFilter != "" && playerCopy.PreferredFoot != preferredFootFilter {
+			continue
+		}
+		// ISO dates compare correctly as strings
+		if contractExpiryCutoff != "" && (playerCopy.ContractExpiry == "" || playerCopy.ContractExpiry >= contractExpiryCutoff) {
+			continue
+		}
+		if bornAfterCutoff != "" && (playerCopy.DateOfBirth == "" || playerCopy.DateOfBirth <= bornAfterCutoff) {
+			continue
+		}
+		if bornBeforeCutoff != "" && (playerCopy.DateOfBirth == "" || playerCopy.DateOfBirth >= bornBeforeCutoff) {
+			continue
+		}
+
 		if filterRole != "" {
 			roleMatched := false
 			for _, roleOverall := range playerCopy.RoleSpecificOveralls {
@@ -969,9 +1285,30 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 		processedPlayers = append(processedPlayers, playerCopy)
 	}
 
-	logDebug(ctx, "Returning processed players", "dataset_id", datasetID, "player_count", len(processedPlayers))
+	page, nextCursor, err := pagePlayers(processedPlayers, listQuery, listSort, querySignature)
+	if err != nil {
+		logWarn(ctx, "Player list query rejected", "dataset_id", datasetID, "sort", listQuery.Sort, "error", err)
+		SetSpanAttributes(ctx, attribute.String("error.type", "invalid_list_query"))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logDebug(ctx, "Returning processed players", "dataset_id", datasetID, "player_count", len(processedPlayers), "page_size", len(page))
 
-	response := PlayerDataWithCurrency{Players: processedPlayers, CurrencySymbol: currencySymbol}
+	// Wages are filtered and sorted weekly, as stored, and returned in the requested period
+	page = convertPlayersWagePeriod(page, currencySymbol, salaryPeriod)
+
+	response := PlayerListResponse{
+		Players:        page,
+		CurrencySymbol: currencySymbol,
+		BaseCurrency:   baseCurrency,
+		WagePeriod:     salaryPeriod,
+		Total:          len(processedPlayers),
+		NextCursor:     nextCursor,
+	}
+	if len(listQuery.Fields) > 0 {
+		response.Players = projectPlayers(page, listQuery.Fields)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	setCORSHeaders(w, r)
 
@@ -1005,9 +1342,9 @@ func playerDataHandler(w http.ResponseWriter, r *http.Request) {
 		"player_count":         len(processedPlayers),
 		"response_size_bytes":  len(jsonData),
 		"processing_time_ms":   time.Since(startTime).Milliseconds(),
-		"percentile_cache_hit": found, // Whether percentiles were from cache
+		"percentile_cache_hit": listPlayers.cacheHit, // Whether percentiles were from cache
 		"division_filter":      divisionFilterStr,
-		"has_filters":          filterPosition != "" || filterRole != "" || minAgeStr != "" || maxAgeStr != "",
+		"has_filters":          filterPosition != "" || filterRole != "" || minAgeStr != "" || maxAgeStr != "" || filterExpressionStr != "",
 	})
 }
 
@@ -1118,11 +1455,12 @@ func teamsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	datasetID := pathParts[0]
 	division := pathParts[1]
+	requestedCurrency := r.URL.Query().Get("currency")
 
-	logInfo(ctx, "Processing teams request", "dataset_id", datasetID, "division", division)
+	logInfo(ctx, "Processing teams request", "dataset_id", datasetID, "division", division, "currency", requestedCurrency)
 
 	// Try to get teams data from cache first
-	cacheKey := fmt.Sprintf("teams_%s_%s", datasetID, division)
+	cacheKey := fmt.Sprintf("teams_%s_%s_%s", datasetID, division, requestedCurrency)
 	if cached, found := getFromMemCache(cacheKey); found {
 		if teamsData, ok := cached.([]Team); ok {
 			logInfo(ctx, "Retrieved teams data from memory cache", "dataset_id", datasetID, "division", division)
@@ -1139,13 +1477,20 @@ func teamsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get player data from storage
-	players, _, found := GetPlayerData(datasetID)
+	players, currencySymbol, found := GetPlayerData(datasetID)
 	if !found {
 		logWarn(ctx, "Player data not found", "dataset_id", datasetID)
 		http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
 		return
 	}
 
+	players, _, err := convertPlayersForRequest(players, currencySymbol, datasetBaseCurrency(datasetID, currencySymbol), requestedCurrency)
+	if err != nil {
+		logWarn(ctx, "Currency conversion rejected", "dataset_id", datasetID, "currency", requestedCurrency, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Recalculate all player ratings based on the current calculation method setting
 	players = RecalculateAllPlayersRatings(players)
 
@@ -1224,7 +1569,8 @@ func processTeamsData(players []Player, division string) []Team {
 
 // PercentileRequest represents the request body for percentile recalculation
 type PercentileRequest struct {
-	PlayerName     string `json:"playerName"`
+	PlayerUID      int64  `json:"playerUid,omitempty"`  // Identifies the player
+	PlayerName     string `json:"playerName,omitempty"` // Fallback for clients without UIDs; rejected when ambiguous
 	DivisionFilter string `json:"divisionFilter"`
 	TargetDivision string `json:"targetDivision"`
 }
@@ -1250,8 +1596,14 @@ func percentilesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.PlayerUID == 0 && req.PlayerName == "" {
+		http.Error(w, "playerUid or playerName is required", http.StatusBadRequest)
+		return
+	}
+
 	logInfo(ctx, "Processing percentiles request",
 		"dataset_id", datasetID,
+		"player_uid", req.PlayerUID,
 		"player_name", req.PlayerName,
 		"division_filter", req.DivisionFilter,
 		"target_division", req.TargetDivision)
@@ -1264,47 +1616,34 @@ func percentilesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find the specific player
-	var targetPlayerIndex = -1
-	for i := range players {
-		if players[i].Name == req.PlayerName {
-			targetPlayerIndex = i
-			break
-		}
-	}
-
-	if targetPlayerIndex == -1 {
-		http.Error(w, "Player not found in dataset", http.StatusNotFound)
+	// Find the specific player, by UID unless the client only sent a name
+	targetPlayerIndex, matches, err := findPlayer(players, req.PlayerUID, req.PlayerName)
+	if err != nil {
+		logDebug(ctx, "Player lookup failed", "dataset_id", datasetID, "player_uid", req.PlayerUID, "player_name", req.PlayerName, "error", err)
+		writePlayerLookupError(ctx, w, r, err, players, matches)
 		return
 	}
+	targetPlayer := &players[targetPlayerIndex]
 
 	// Parse division filter
-	var divisionFilter = DivisionFilterAll
-	switch req.DivisionFilter {
-	case "same":
-		divisionFilter = DivisionFilterSame
-	case "top5":
-		divisionFilter = DivisionFilterTop5
-	case "all", "":
-		divisionFilter = DivisionFilterAll
-	}
+	divisionFilter := parseDivisionFilter(req.DivisionFilter)
 
 	// NEW: Generate cache key and try to load from cache first
-	cacheKey := generatePercentilesCacheKey(ctx, datasetID, req.PlayerName, req.DivisionFilter, req.TargetDivision, players)
+	cacheKey := generatePercentilesCacheKey(ctx, datasetID, targetPlayer, req.DivisionFilter, req.TargetDivision, players)
 
 	logDebug(ctx, "Generated cache key for percentiles request",
 		"dataset_id", datasetID,
-		"player_name", req.PlayerName,
+		"player_uid", targetPlayer.UID,
 		"division_filter", req.DivisionFilter,
 		"target_division", req.TargetDivision,
 		"cache_key", cacheKey,
 		"player_count", len(players))
 
 	// Try to load from cache
-	if cachedPercentiles, found := loadPercentilesFromCache(ctx, cacheKey, datasetID, req.PlayerName, req.DivisionFilter, req.TargetDivision, players); found {
+	if cachedPercentiles, found := loadPercentilesFromCache(ctx, cacheKey, datasetID, targetPlayer, req.DivisionFilter, req.TargetDivision, players); found {
 		logDebug(ctx, "🎯 CACHE HIT - Returning cached percentiles",
 			"dataset_id", datasetID,
-			"player_name", req.PlayerName,
+			"player_uid", targetPlayer.UID,
 			"division_filter", req.DivisionFilter,
 			"target_division", req.TargetDivision,
 			"cache_key", cacheKey)
@@ -1322,7 +1661,7 @@ func percentilesHandler(w http.ResponseWriter, r *http.Request) {
 	// Cache miss - perform calculation
 	logDebug(ctx, "💫 CACHE MISS - calculating percentiles",
 		"dataset_id", datasetID,
-		"player_name", req.PlayerName,
+		"player_uid", targetPlayer.UID,
 		"division_filter", req.DivisionFilter,
 		"target_division", req.TargetDivision,
 		"cache_key", cacheKey)
@@ -1338,7 +1677,7 @@ func percentilesHandler(w http.ResponseWriter, r *http.Request) {
 
 	// NEW: Save to cache for future requests
 	go func() {
-		savePercentilesToCache(ctx, cacheKey, datasetID, req.PlayerName, req.DivisionFilter, req.TargetDivision, players, updatedPercentiles)
+		savePercentilesToCache(ctx, cacheKey, datasetID, targetPlayer, req.DivisionFilter, req.TargetDivision, players, updatedPercentiles)
 	}()
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1856,11 +2195,12 @@ func performSearch(players []Player, query string) []SearchResult {
 
 // BargainHunterRequest represents the request body for bargain hunter analysis
 type BargainHunterRequest struct {
-	MaxBudget  int64 `json:"maxBudget"`
-	MaxSalary  int64 `json:"maxSalary"`
-	MinAge     int   `json:"minAge"`
-	MaxAge     int   `json:"maxAge"`
-	MinOverall int   `json:"minOverall"`
+	MaxBudget  int64  `json:"maxBudget"`
+	MaxSalary  int64  `json:"maxSalary"`
+	WagePeriod string `json:"wagePeriod,omitempty"` // Period of MaxSalary and of the returned wages; weekly by default
+	MinAge     int    `json:"minAge"`
+	MaxAge     int    `json:"maxAge"`
+	MinOverall int    `json:"minOverall"`
 }
 
 // BargainHunterResponse represents a player with calculated value score
@@ -1890,6 +2230,10 @@ func bargainHunterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Player wages are stored weekly, so compare against a weekly cap
+	salaryPeriod, _ := ParseWagePeriod(req.WagePeriod)
+	req.MaxSalary = ConvertWageAmount(req.MaxSalary, salaryPeriod, WagePeriodWeekly)
+
 	logInfo(ctx, "Processing bargain hunter request",
 		"dataset_id", datasetID,
 		"max_budget", req.MaxBudget,
@@ -1899,13 +2243,32 @@ func bargainHunterHandler(w http.ResponseWriter, r *http.Request) {
 		"min_overall", req.MinOverall)
 
 	// Get player data from storage
-	players, _, found := GetPlayerData(datasetID)
+	players, currencySymbol, found := GetPlayerData(datasetID)
 	if !found {
 		logWarn(ctx, "Player data not found", "dataset_id", datasetID)
 		http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
 		return
 	}
 
+	// With a currency parameter the budget and salary cap are given in that currency. They are
+	// converted to the dataset's currency so the search and its cache work on stored amounts,
+	// and only the returned players are converted back.
+	requestedCurrency := r.URL.Query().Get("currency")
+	var baseCurrency, targetCurrency string
+	resultSymbol := currencySymbol
+	if requestedCurrency != "" {
+		var err error
+		baseCurrency, targetCurrency, err = resolveRequestCurrency(currencySymbol, datasetBaseCurrency(datasetID, currencySymbol), requestedCurrency)
+		if err != nil {
+			logWarn(ctx, "Currency conversion rejected", "dataset_id", datasetID, "currency", requestedCurrency, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.MaxBudget, _ = convertCurrencyAmount(req.MaxBudget, targetCurrency, baseCurrency)
+		req.MaxSalary, _ = convertCurrencyAmount(req.MaxSalary, targetCurrency, baseCurrency)
+		resultSymbol = currencySymbolForCode(targetCurrency)
+	}
+
 	// Recalculate all player ratings based on the current calculation method setting
 	players = RecalculateAllPlayersRatings(players)
 
@@ -1919,6 +2282,11 @@ func bargainHunterHandler(w http.ResponseWriter, r *http.Request) {
 			"cache_key", cacheKey,
 			"result_count", len(cachedResults))
 
+		if targetCurrency != "" {
+			cachedResults = convertBargainResultsCurrency(cachedResults, baseCurrency, targetCurrency)
+		}
+		cachedResults = convertBargainResultsWagePeriod(cachedResults, resultSymbol, salaryPeriod)
+
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache-Status", "HIT")
 		setCORSHeaders(w, r)
@@ -1942,6 +2310,11 @@ func bargainHunterHandler(w http.ResponseWriter, r *http.Request) {
 		saveBargainHunterToCache(ctx, cacheKey, datasetID, req.MaxBudget, req.MaxSalary, req.MinAge, req.MaxAge, req.MinOverall, players, bargainPlayers)
 	}()
 
+	if targetCurrency != "" {
+		bargainPlayers = convertBargainResultsCurrency(bargainPlayers, baseCurrency, targetCurrency)
+	}
+	bargainPlayers = convertBargainResultsWagePeriod(bargainPlayers, resultSymbol, salaryPeriod)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Cache-Status", "MISS")
 	setCORSHeaders(w, r)
@@ -1990,12 +2363,13 @@ func processBargainHunter(players []Player, maxBudget, maxSalary, minAge, maxAge
 		player := players[i]
 
 		// Skip free transfers entirely
-		if player.TransferValueAmount == 0 {
+		transferValue := playerTransferValueEstimate(&player)
+		if transferValue == 0 {
 			continue
 		}
 
 		// Skip players outside budget constraints
-		if maxBudget > 0 && player.TransferValueAmount > maxBudget {
+		if maxBudget > 0 && transferValue > maxBudget {
 			continue
 		}
 		if maxSalary > 0 && player.WageAmount > maxSalary {
@@ -2024,7 +2398,7 @@ func processBargainHunter(players []Player, maxBudget, maxSalary, minAge, maxAge
 		// Calculate value score using improved algorithm
 		var valueScore float64
 		overall := float64(player.Overall)
-		transferValueMillions := float64(player.TransferValueAmount) / 1000000.0
+		transferValueMillions := float64(transferValue) / 1000000.0
 
 		// Prevent division by zero
 		if transferValueMillions == 0 {
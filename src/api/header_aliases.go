@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// headerAliasesFile maps localized FM column headers to the canonical English keys used by
+// parseCellsToPlayer and EnhancePlayerWithCalculations, keyed by language code.
+var headerAliasesFile = filepath.Join("public", "header_aliases.json")
+
+// defaultHeaderLanguage is reported when an export's headers are already canonical.
+const defaultHeaderLanguage = "en"
+
+// minHeaderAliasMatches is how many language-specific headers must be present before an
+// export is treated as localized. A single coincidental match (e.g. "Club") is not enough.
+const minHeaderAliasMatches = 3
+
+var (
+	headerAliases   = make(map[string]map[string]string)
+	muHeaderAliases sync.RWMutex
+)
+
+// loadHeaderAliases reads the alias registry from a JSON file of the form
+// {"de": {"Verein": "Club", ...}, "es": {...}}.
+func loadHeaderAliases(filePath string) (map[string]map[string]string, error) {
+	if err := validateConfigFilePath(filePath); err != nil {
+		LogWarn("Invalid file path %s: %v. Localized headers will not be translated.", filePath, err)
+		return nil, err
+	}
+
+	//nolint:gosec // filePath is validated above to be within allowed directories
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		LogWarn("Could not read %s: %v. Localized headers will not be translated.", filePath, err)
+		return nil, err
+	}
+
+	var aliases map[string]map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		LogWarn("Could not unmarshal %s: %v. Localized headers will not be translated.", filePath, err)
+		return nil, err
+	}
+
+	LogDebug("Successfully loaded header aliases from %s for %d languages.", filePath, len(aliases))
+	return aliases, nil
+}
+
+// setHeaderAliases replaces the active alias registry.
+func setHeaderAliases(aliases map[string]map[string]string) {
+	if aliases == nil {
+		aliases = make(map[string]map[string]string)
+	}
+	muHeaderAliases.Lock()
+	defer muHeaderAliases.Unlock()
+	headerAliases = aliases
+}
+
+// isCanonicalHeader reports whether header is already one of the English keys the parser understands.
+func isCanonicalHeader(header string) bool {
+	return playerInfoHeaders[header] || playerDetailHeaders[header] || fmAttributeKeys[header] || isPerformanceStatKey(header)
+}
+
+// detectHeaderLanguage picks the registry language with the most headers that are specific
+// to it, i.e. present in its alias table but not already canonical English headers.
+// It returns defaultHeaderLanguage when no language reaches minHeaderAliasMatches.
+func detectHeaderLanguage(headers []string) string {
+	muHeaderAliases.RLock()
+	defer muHeaderAliases.RUnlock()
+
+	languages := make([]string, 0, len(headerAliases))
+	for language := range headerAliases {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages) // Deterministic tie-breaking
+
+	bestLanguage := defaultHeaderLanguage
+	bestMatches := minHeaderAliasMatches - 1
+	for _, language := range languages {
+		aliases := headerAliases[language]
+		matches := 0
+		for _, header := range headers {
+			header = strings.TrimSpace(header)
+			if _, ok := aliases[header]; ok && !isCanonicalHeader(header) {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			bestLanguage = language
+			bestMatches = matches
+		}
+	}
+	return bestLanguage
+}
+
+// resolveHeaderAliases detects the export language and returns the headers translated to
+// their canonical keys, positionally aligned with the input. Headers without an alias are
+// kept as-is so unknown columns still reach Player.Attributes.
+func resolveHeaderAliases(headers []string) ([]string, string) {
+	language := detectHeaderLanguage(headers)
+
+	muHeaderAliases.RLock()
+	aliases, found := headerAliases[language]
+	muHeaderAliases.RUnlock()
+	if !found {
+		return headers, language
+	}
+
+	canonical := make([]string, len(headers))
+	for i, header := range headers {
+		if alias, ok := aliases[strings.TrimSpace(header)]; ok {
+			canonical[i] = alias
+		} else {
+			canonical[i] = header
+		}
+	}
+	LogDebug("Translated %d headers from language %q to canonical keys", len(headers), language)
+	return canonical, language
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withHeaderAliases installs the shipped alias file for the duration of a test.
+func withHeaderAliases(t *testing.T) {
+	t.Helper()
+
+	aliases, err := loadHeaderAliases(headerAliasesFile)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", headerAliasesFile, err)
+	}
+
+	muHeaderAliases.RLock()
+	previous := headerAliases
+	muHeaderAliases.RUnlock()
+
+	setHeaderAliases(aliases)
+	t.Cleanup(func() { setHeaderAliases(previous) })
+}
+
+func TestHeaderAliasFileTargetsCanonicalKeys(t *testing.T) {
+	aliases, err := loadHeaderAliases(headerAliasesFile)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", headerAliasesFile, err)
+	}
+
+	for language, table := range aliases {
+		for localized, canonical := range table {
+			if !isCanonicalHeader(canonical) {
+				t.Errorf("%s alias %q maps to %q, which is not a canonical header", language, localized, canonical)
+			}
+		}
+	}
+}
+
+func TestResolveHeaderAliases(t *testing.T) {
+	withHeaderAliases(t)
+
+	tests := []struct {
+		name         string
+		headers      []string
+		wantLanguage string
+		wantHeaders  []string
+	}{
+		{
+			name:         "english export untouched",
+			headers:      []string{"Name", "Club", "Age", "Ant", "Fin"},
+			wantLanguage: defaultHeaderLanguage,
+			wantHeaders:  []string{"Name", "Club", "Age", "Ant", "Fin"},
+		},
+		{
+			name:         "german export translated",
+			headers:      []string{"Name", "Verein", "Alter", "Abs", "Atr", "Unbekannt"},
+			wantLanguage: "de",
+			wantHeaders:  []string{"Name", "Club", "Age", "Fin", "Acc", "Unbekannt"},
+		},
+		{
+			name:         "too few localized headers",
+			headers:      []string{"Name", "Verein", "Age"},
+			wantLanguage: defaultHeaderLanguage,
+			wantHeaders:  []string{"Name", "Verein", "Age"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHeaders, gotLanguage := resolveHeaderAliases(tt.headers)
+			if gotLanguage != tt.wantLanguage {
+				t.Errorf("language = %q, want %q", gotLanguage, tt.wantLanguage)
+			}
+			if !reflect.DeepEqual(gotHeaders, tt.wantHeaders) {
+				t.Errorf("headers = %v, want %v", gotHeaders, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestParseLocalizedCSVExport(t *testing.T) {
+	withHeaderAliases(t)
+
+	content := "Name;Verein;Alter;Position;Abs;Atr;Ant\n" +
+		"Lokaler Spieler;Test FC;24;ST (C);16;14;12\n"
+
+	players, headers, err := parseTableForTest(t, UploadFormatCSV, content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(players) != 1 {
+		t.Fatalf("Expected 1 player, got %d", len(players))
+	}
+	if headers[1] != "Verein" {
+		t.Errorf("Header snapshot should keep the file's headers, got %v", headers)
+	}
+
+	player := players[0]
+	if player.Club != "Test FC" || player.Age != "24" {
+		t.Errorf("Expected Club/Age to be mapped, got Club=%q Age=%q", player.Club, player.Age)
+	}
+	if player.NumericAttributes["Fin"] != 16 || player.NumericAttributes["Acc"] != 14 {
+		t.Errorf("Expected Fin=16 and Acc=14, got %v", player.NumericAttributes)
+	}
+	if player.NumericAttributes["Ant"] != 12 {
+		t.Errorf("Expected German Ant to stay Anticipation (12), got %d", player.NumericAttributes["Ant"])
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -102,7 +103,30 @@ func validateEnvironmentVariables() error {
 	return nil
 }
 
+// runSubcommand runs a maintenance command of the API binary and returns its exit code.
+func runSubcommand(name string, args []string) int {
+	switch name {
+	case "migrate":
+		return runMigrateCommand(args, os.Stdout, os.Stderr)
+	case "backup":
+		return runBackupCommand(args, os.Stdout, os.Stderr)
+	case "restore":
+		return runRestoreCommand(args, os.Stdout, os.Stderr)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\navailable commands:\n"+
+			"  migrate  copy datasets between storage backends\n"+
+			"  backup   write a backup archive of a storage backend\n"+
+			"  restore  restore a backup archive into a storage backend\n", name)
+		return 2
+	}
+}
+
 func main() {
+	// Subcommands run instead of the server, e.g. "api migrate -from local:./datasets -to sqlite:fmdash.db"
+	if len(os.Args) > 1 {
+		os.Exit(runSubcommand(os.Args[1], os.Args[2:]))
+	}
+
 	// Validate environment variables first
 	if err := validateEnvironmentVariables(); err != nil {
 		slog.Error("Environment validation failed", "error", err)
@@ -199,6 +223,18 @@ func main() {
 	// API endpoint for file uploads
 	http.Handle("/api/upload", wrapHandler(http.HandlerFunc(uploadHandler), "upload"))
 
+	// API endpoint for polling background upload jobs
+	http.Handle("/api/upload-jobs/", wrapHandler(http.HandlerFunc(uploadJobStatusHandler), "upload-jobs"))
+
+	// Dataset management endpoints: list, summary, metadata edits and deletion
+	http.Handle("/api/datasets", wrapHandler(http.HandlerFunc(datasetsHandler), "datasets"))
+	http.Handle("/api/datasets/", wrapHandler(http.HandlerFunc(datasetsHandler), "datasets"))
+	// Save timelines, and player progression and transfers between snapshots
+	http.Handle("/api/saves", wrapHandler(http.HandlerFunc(savesHandler), "saves"))
+	http.Handle("/api/saves/", wrapHandler(http.HandlerFunc(savesHandler), "saves"))
+	http.Handle("/api/progression/", wrapHandler(http.HandlerFunc(progressionHandler), "progression"))
+	http.Handle("/api/transfers/", wrapHandler(http.HandlerFunc(transfersHandler), "transfers"))
+
 	// API endpoint for retrieving player data
 	http.Handle("/api/players/", wrapHandler(http.HandlerFunc(playerDataHandler), "player-data"))
 
@@ -217,6 +253,12 @@ func main() {
 	// API endpoint for checking percentile status
 	http.Handle("/api/percentiles-status/", wrapHandler(http.HandlerFunc(percentilesStatusHandler), "percentiles-status"))
 
+	// API endpoint for percentiles of many players from one calculation
+	http.Handle("/api/percentiles-batch/", wrapHandler(http.HandlerFunc(percentilesBatchHandler), "percentiles-batch"))
+
+	// API endpoint for percentiles against a cohort defined by a filter expression
+	http.Handle("/api/percentiles-cohort/", wrapHandler(http.HandlerFunc(percentileCohortHandler), "percentiles-cohort"))
+
 	// API endpoint for universal search (players, teams, leagues, nations)
 	http.Handle("/api/search/", wrapHandler(http.HandlerFunc(searchHandler), "search"))
 
@@ -244,6 +286,10 @@ func main() {
 	// API endpoint for memory optimization reports
 	http.Handle("/api/memory-optimization", wrapHandler(http.HandlerFunc(GetMemoryOptimizationHandler()), "memory-optimization"))
 
+	// Admin endpoints for backing up and restoring every dataset, cache entry and save (require ADMIN_TOKEN)
+	http.Handle("/api/admin/backup", wrapHandler(http.HandlerFunc(backupHandler), "admin-backup"))
+	http.Handle("/api/admin/restore", wrapHandler(http.HandlerFunc(restoreHandler), "admin-restore"))
+
 	// Create HTTP server with timeouts and middleware
 	mux := http.NewServeMux()
 
@@ -265,12 +311,21 @@ func main() {
 	mux.Handle("/", wrapHandler(indexHandler, "index"))
 	mux.Handle("/public/", http.StripPrefix("/public/", fsPublic))
 	mux.Handle("/api/upload", wrapHandler(http.HandlerFunc(uploadHandler), "upload"))
+	mux.Handle("/api/upload-jobs/", wrapHandler(http.HandlerFunc(uploadJobStatusHandler), "upload-jobs"))
+	mux.Handle("/api/datasets", wrapHandler(http.HandlerFunc(datasetsHandler), "datasets"))
+	mux.Handle("/api/datasets/", wrapHandler(http.HandlerFunc(datasetsHandler), "datasets"))
+	mux.Handle("/api/saves", wrapHandler(http.HandlerFunc(savesHandler), "saves"))
+	mux.Handle("/api/saves/", wrapHandler(http.HandlerFunc(savesHandler), "saves"))
+	mux.Handle("/api/progression/", wrapHandler(http.HandlerFunc(progressionHandler), "progression"))
+	mux.Handle("/api/transfers/", wrapHandler(http.HandlerFunc(transfersHandler), "transfers"))
 	mux.Handle("/api/players/", wrapHandler(http.HandlerFunc(playerDataHandler), "player-data"))
 	mux.Handle("/api/roles", wrapHandler(http.HandlerFunc(rolesHandler), "roles"))
 	mux.Handle("/api/leagues/", wrapHandler(http.HandlerFunc(leaguesHandler), "leagues"))
 	mux.Handle("/api/teams/", wrapHandler(http.HandlerFunc(teamsHandler), "teams"))
 	mux.Handle("/api/percentiles/", wrapHandler(http.HandlerFunc(percentilesHandler), "percentiles"))
 	mux.Handle("/api/percentiles-status/", wrapHandler(http.HandlerFunc(percentilesStatusHandler), "percentiles-status"))
+	mux.Handle("/api/percentiles-batch/", wrapHandler(http.HandlerFunc(percentilesBatchHandler), "percentiles-batch"))
+	mux.Handle("/api/percentiles-cohort/", wrapHandler(http.HandlerFunc(percentileCohortHandler), "percentiles-cohort"))
 	mux.Handle("/api/search/", wrapHandler(http.HandlerFunc(searchHandler), "search"))
 	mux.Handle("/api/config", wrapHandler(http.HandlerFunc(cachedConfigHandler), "config"))
 	mux.Handle("/api/bargain-hunter/", wrapHandler(http.HandlerFunc(bargainHunterHandler), "bargain-hunter"))
@@ -293,6 +348,10 @@ func main() {
 	// API endpoint for memory optimization reports
 	mux.Handle("/api/memory-optimization", wrapHandler(http.HandlerFunc(GetMemoryOptimizationHandler()), "memory-optimization"))
 
+	// Admin endpoints for backing up and restoring every dataset, cache entry and save (require ADMIN_TOKEN)
+	mux.Handle("/api/admin/backup", wrapHandler(http.HandlerFunc(backupHandler), "admin-backup"))
+	mux.Handle("/api/admin/restore", wrapHandler(http.HandlerFunc(restoreHandler), "admin-restore"))
+
 	// Create server with proper timeouts
 	server := &http.Server{
 		Addr:              ":" + port,
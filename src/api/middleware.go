@@ -317,7 +317,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
@@ -395,3 +395,9 @@ func (gzw *gzipResponseWriter) WriteHeader(statusCode int) {
 	gzw.ResponseWriter.Header().Del("Content-Length")
 	gzw.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController, so handlers can extend
+// their deadlines through the compression middleware.
+func (gzw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gzw.ResponseWriter
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	apperrors "api/errors"
+)
+
+// protobufDatasetMarker is the currency symbol ProtobufStorage stores on its wrapper records.
+const protobufDatasetMarker = "__PROTOBUF_MARKER__"
+
+// Dataset encodings reported by the migrate command
+const (
+	encodingJSON     = "json"
+	encodingProtobuf = "protobuf"
+)
+
+// migrationOptions controls how migrateDatasets copies datasets.
+type migrationOptions struct {
+	DryRun    bool // Report what would be copied without writing to the destination
+	Protobuf  bool // Re-encode JSON datasets to protobuf on the destination
+	Overwrite bool // Replace destination datasets whose contents differ from the source
+}
+
+// migrationReport counts the outcome of a migration run.
+type migrationReport struct {
+	Total   int
+	Copied  int
+	Skipped int
+	Failed  int
+}
+
+// unavailableStorage rejects every operation. It stands in for the in-memory fallback of
+// S3Storage during migrations, so an unreachable bucket fails loudly instead of copying
+// datasets into memory that disappears when the command exits.
+type unavailableStorage struct {
+	err error
+}
+
+func (s unavailableStorage) Store(string, DatasetData) error                  { return s.err }
+func (s unavailableStorage) Retrieve(string) (DatasetData, error)             { return DatasetData{}, s.err }
+func (s unavailableStorage) Delete(string) error                              { return s.err }
+func (s unavailableStorage) List() ([]string, error)                          { return nil, s.err }
+func (s unavailableStorage) CleanupOldDatasets(time.Duration, []string) error { return s.err }
+
+// openMigrationStorage opens a backend from a command-line spec:
+//
+//	local:DIR     JSON-gzip files in DIR, as used by hybrid storage with DATASETS_DIR
+//	hybrid:DIR    alias of local:DIR; the in-memory layer is empty in a fresh process
+//	sqlite:PATH   the embedded SQL database at PATH
+//	s3:BUCKET     an S3 bucket, using S3_ENDPOINT, S3_ACCESS_KEY, S3_SECRET_KEY and S3_USE_SSL
+//
+// The returned function releases the backend.
+func openMigrationStorage(spec string) (StorageInterface, func(), error) {
+	kind, location, found := strings.Cut(spec, ":")
+	if !found || location == "" {
+		return nil, nil, fmt.Errorf("invalid storage %q: expected local:DIR, hybrid:DIR, sqlite:PATH or s3:BUCKET", spec)
+	}
+
+	switch strings.ToLower(kind) {
+	case "local", "hybrid":
+		storage, err := CreateLocalFileStorage(location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", spec, err)
+		}
+		return storage, func() {}, nil
+	case "sqlite", "sql":
+		storage, err := CreateSQLStorage(location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", spec, err)
+		}
+		return storage, func() { _ = storage.Close() }, nil
+	case "s3":
+		endpoint := os.Getenv("S3_ENDPOINT")
+		accessKey := os.Getenv("S3_ACCESS_KEY")
+		secretKey := os.Getenv("S3_SECRET_KEY")
+		if endpoint == "" || accessKey == "" || secretKey == "" {
+			return nil, nil, fmt.Errorf("%s requires S3_ENDPOINT, S3_ACCESS_KEY and S3_SECRET_KEY", spec)
+		}
+		useSSL := strings.ToLower(os.Getenv("S3_USE_SSL")) == "true"
+		unavailable := unavailableStorage{err: fmt.Errorf("S3 bucket %s is unavailable", location)}
+		storage := CreateS3Storage(endpoint, accessKey, secretKey, location, useSSL, unavailable)
+		if storage.client == nil {
+			return nil, nil, fmt.Errorf("failed to connect to S3 bucket %s at %s", location, endpoint)
+		}
+		return storage, storage.Shutdown, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage type %q in %q", kind, spec)
+	}
+}
+
+// readStoredDataset retrieves a dataset and reports how it is encoded on the backend.
+// Protobuf wrapper records are decoded into the dataset they hold.
+func readStoredDataset(backend StorageInterface, datasetID string) (DatasetData, string, error) {
+	raw, err := backend.Retrieve(datasetID)
+	if err != nil {
+		return DatasetData{}, "", err
+	}
+	if raw.CurrencySymbol != protobufDatasetMarker {
+		return raw, encodingJSON, nil
+	}
+
+	data, err := CreateProtobufStorage(backend).Retrieve(datasetID)
+	if err != nil {
+		return DatasetData{}, "", err
+	}
+	// ProtobufStorage falls back to the raw record when decoding fails
+	if data.CurrencySymbol == protobufDatasetMarker {
+		return DatasetData{}, "", fmt.Errorf("failed to decode protobuf dataset %s", datasetID)
+	}
+	return data, encodingProtobuf, nil
+}
+
+// datasetFingerprint hashes the contents of a dataset that every encoding preserves, so a copy
+// can be verified whether it was stored as JSON, protobuf or SQL rows.
+func datasetFingerprint(data *DatasetData) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%q|%q|%q|%q\n", data.CurrencySymbol, data.BaseCurrency, data.ExportProfile, data.CacheData)
+	if data.Metadata != nil {
+		fmt.Fprintf(hash, "%q|%q|%d\n", data.Metadata.Name, data.Metadata.Description, data.Metadata.UploadedAt.Unix())
+	}
+	for i := range data.Players {
+		player := &data.Players[i]
+		fmt.Fprintf(hash, "%d|%q|%q|%q|%q|%q|%q|%d|%d|%d\n",
+			player.UID, player.Name, player.Position, player.Age, player.Club, player.Division,
+			player.Nationality, player.Overall, player.TransferValueAmount, player.WageAmount)
+
+		attributes := make([]string, 0, len(player.NumericAttributes))
+		for attribute := range player.NumericAttributes {
+			attributes = append(attributes, attribute)
+		}
+		sort.Strings(attributes)
+		for _, attribute := range attributes {
+			fmt.Fprintf(hash, "%s=%d;", attribute, player.NumericAttributes[attribute])
+		}
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// migrationOutcome describes what happened to one dataset.
+type migrationOutcome struct {
+	Skipped      bool // Already present and verified on the destination
+	Players      int
+	FromEncoding string
+	ToEncoding   string
+}
+
+// migrateDataset copies one dataset and verifies the player count and hash of the copy.
+func migrateDataset(source, destination StorageInterface, datasetID string, destinationIDs map[string]bool,
+	options migrationOptions,
+) (migrationOutcome, error) {
+	data, encoding, err := readStoredDataset(source, datasetID)
+	if err != nil {
+		return migrationOutcome{}, fmt.Errorf("failed to read source: %w", err)
+	}
+	fingerprint := datasetFingerprint(&data)
+	outcome := migrationOutcome{Players: len(data.Players), FromEncoding: encoding, ToEncoding: encoding}
+
+	// Datasets already copied and verified are skipped, so re-running a migration resumes it
+	if destinationIDs[datasetID] {
+		existing, _, err := readStoredDataset(destination, datasetID)
+		if err != nil && !errors.Is(err, apperrors.ErrDatasetNotFound) {
+			return migrationOutcome{}, fmt.Errorf("failed to read destination: %w", err)
+		}
+		if err == nil && len(existing.Players) == len(data.Players) && datasetFingerprint(&existing) == fingerprint {
+			outcome.Skipped = true
+			return outcome, nil
+		}
+		if err == nil && !options.Overwrite {
+			return migrationOutcome{}, errors.New("destination holds a different dataset with this ID (use -overwrite to replace it)")
+		}
+	}
+
+	// SQL storage keeps players as rows, and cache entries and save records have no players to encode
+	if _, sqlDestination := destination.(*SQLStorage); sqlDestination || data.CacheData != "" {
+		outcome.ToEncoding = encodingJSON
+	} else if options.Protobuf {
+		outcome.ToEncoding = encodingProtobuf
+	}
+	if options.DryRun {
+		return outcome, nil
+	}
+
+	writer := destination
+	if outcome.ToEncoding == encodingProtobuf {
+		writer = CreateProtobufStorage(destination)
+	}
+	if err := writer.Store(datasetID, data); err != nil {
+		return migrationOutcome{}, fmt.Errorf("failed to write destination: %w", err)
+	}
+
+	copied, _, err := readStoredDataset(destination, datasetID)
+	if err != nil {
+		return migrationOutcome{}, fmt.Errorf("failed to read back copy: %w", err)
+	}
+	if len(copied.Players) != len(data.Players) {
+		return migrationOutcome{}, fmt.Errorf("verification failed: copy has %d players, source has %d",
+			len(copied.Players), len(data.Players))
+	}
+	if datasetFingerprint(&copied) != fingerprint {
+		return migrationOutcome{}, errors.New("verification failed: copy does not match the source hash")
+	}
+	return outcome, nil
+}
+
+// migrateDatasets copies every dataset on source to destination, writing one line per dataset
+// to out. Failures are reported and counted without stopping the run.
+func migrateDatasets(source, destination StorageInterface, options migrationOptions, out io.Writer) (migrationReport, error) {
+	var report migrationReport
+
+	sourceIDs, err := source.List()
+	if err != nil {
+		return report, fmt.Errorf("failed to list source datasets: %w", err)
+	}
+	existingIDs, err := destination.List()
+	if err != nil {
+		return report, fmt.Errorf("failed to list destination datasets: %w", err)
+	}
+	destinationIDs := make(map[string]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		destinationIDs[id] = true
+	}
+
+	copiedLabel := "copied"
+	if options.DryRun {
+		copiedLabel = "would copy"
+	}
+
+	sort.Strings(sourceIDs)
+	report.Total = len(sourceIDs)
+	for _, datasetID := range sourceIDs {
+		outcome, err := migrateDataset(source, destination, datasetID, destinationIDs, options)
+		switch {
+		case err != nil:
+			report.Failed++
+			fmt.Fprintf(out, "failed     %s: %v\n", datasetID, err)
+		case outcome.Skipped:
+			report.Skipped++
+			fmt.Fprintf(out, "skipped    %s (already in destination)\n", datasetID)
+		default:
+			report.Copied++
+			fmt.Fprintf(out, "%-10s %s (%d players, %s -> %s)\n",
+				copiedLabel, datasetID, outcome.Players, outcome.FromEncoding, outcome.ToEncoding)
+		}
+	}
+	return report, nil
+}
+
+// runMigrateCommand implements "api migrate", copying datasets between storage backends:
+//
+//	api migrate -from local:./datasets -to sqlite:./datasets/fmdash.db [-dry-run] [-protobuf] [-overwrite]
+//
+// It returns the process exit code: 0 on success, 1 when any dataset failed and 2 for usage errors.
+func runMigrateCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	from := flags.String("from", "", "source storage: local:DIR, hybrid:DIR, sqlite:PATH or s3:BUCKET")
+	to := flags.String("to", "", "destination storage, in the same form as -from")
+	var options migrationOptions
+	flags.BoolVar(&options.DryRun, "dry-run", false, "list the datasets that would be copied without writing them")
+	flags.BoolVar(&options.Protobuf, "protobuf", false, "re-encode JSON-gzip datasets to protobuf on the destination")
+	flags.BoolVar(&options.Overwrite, "overwrite", false, "replace destination datasets that differ from the source")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *from == "" || *to == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: api migrate -from STORAGE -to STORAGE [-dry-run] [-protobuf] [-overwrite]")
+		flags.PrintDefaults()
+		return 2
+	}
+	if *from == *to {
+		fmt.Fprintln(stderr, "migrate: -from and -to must name different storage")
+		return 2
+	}
+
+	source, closeSource, err := openMigrationStorage(*from)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 2
+	}
+	defer closeSource()
+	destination, closeDestination, err := openMigrationStorage(*to)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 2
+	}
+	defer closeDestination()
+	if _, sqlDestination := destination.(*SQLStorage); sqlDestination && options.Protobuf {
+		fmt.Fprintln(stderr, "migrate: -protobuf does not apply to sqlite storage, which stores players as rows")
+		return 2
+	}
+
+	start := time.Now()
+	report, err := migrateDatasets(source, destination, options, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "migrate: %v\n", err)
+		return 1
+	}
+
+	summary := "Migrated"
+	if options.DryRun {
+		summary = "Dry run:"
+	}
+	fmt.Fprintf(stdout, "%s %d datasets from %s to %s in %s: %d copied, %d skipped, %d failed\n",
+		summary, report.Total, *from, *to, time.Since(start).Round(time.Millisecond), report.Copied, report.Skipped, report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
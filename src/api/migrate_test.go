@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func createMigrationSource(t *testing.T) (*LocalFileStorage, string) {
+	t.Helper()
+	dir := t.TempDir()
+	source, err := CreateLocalFileStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to create source storage: %v", err)
+	}
+	if err := source.Store("migrate-json", DatasetData{Players: sqlTestPlayers(), CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Failed to store JSON dataset: %v", err)
+	}
+	if err := CreateProtobufStorage(source).Store("migrate-protobuf", DatasetData{Players: sqlTestPlayers()[:2], CurrencySymbol: "€"}); err != nil {
+		t.Fatalf("Failed to store protobuf dataset: %v", err)
+	}
+	if err := source.Store("cache_migrate", DatasetData{Players: []Player{}, CacheData: `{"cached":true}`}); err != nil {
+		t.Fatalf("Failed to store cache entry: %v", err)
+	}
+	return source, dir
+}
+
+func TestMigrateDatasets(t *testing.T) {
+	source, _ := createMigrationSource(t)
+	destination, err := CreateLocalFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create destination storage: %v", err)
+	}
+
+	var out bytes.Buffer
+	report, err := migrateDatasets(source, destination, migrationOptions{DryRun: true, Protobuf: true}, &out)
+	if err != nil || report.Copied != 3 || report.Failed != 0 {
+		t.Fatalf("Dry run = %+v, %v:\n%s", report, err, out.String())
+	}
+	if ids, _ := destination.List(); len(ids) != 0 {
+		t.Fatalf("Dry run wrote datasets: %v", ids)
+	}
+
+	out.Reset()
+	report, err = migrateDatasets(source, destination, migrationOptions{Protobuf: true}, &out)
+	if err != nil || report.Copied != 3 || report.Failed != 0 {
+		t.Fatalf("Migration = %+v, %v:\n%s", report, err, out.String())
+	}
+	if !strings.Contains(out.String(), "migrate-json (3 players, json -> protobuf)") ||
+		!strings.Contains(out.String(), "cache_migrate (0 players, json -> json)") {
+		t.Errorf("Unexpected migration output:\n%s", out.String())
+	}
+
+	data, encoding, err := readStoredDataset(destination, "migrate-json")
+	if err != nil || encoding != encodingProtobuf || len(data.Players) != 3 || data.CurrencySymbol != "£" {
+		t.Errorf("Re-encoded dataset = %d players, %q, %s, %v", len(data.Players), data.CurrencySymbol, encoding, err)
+	}
+	if cached, encoding, err := readStoredDataset(destination, "cache_migrate"); err != nil || encoding != encodingJSON || cached.CacheData == "" {
+		t.Errorf("Cache entry = %+v, %s, %v", cached, encoding, err)
+	}
+
+	// Re-running resumes: verified copies are skipped
+	out.Reset()
+	report, _ = migrateDatasets(source, destination, migrationOptions{Protobuf: true}, &out)
+	if report.Skipped != 3 || report.Copied != 0 {
+		t.Errorf("Expected every dataset to be skipped on resume, got %+v:\n%s", report, out.String())
+	}
+
+	// A different dataset under the same ID is only replaced with Overwrite
+	if err := destination.Store("migrate-json", DatasetData{Players: sqlTestPlayers()[:1], CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Failed to replace destination dataset: %v", err)
+	}
+	report, _ = migrateDatasets(source, destination, migrationOptions{}, &out)
+	if report.Failed != 1 {
+		t.Errorf("Expected the conflicting dataset to fail, got %+v", report)
+	}
+	report, _ = migrateDatasets(source, destination, migrationOptions{Overwrite: true}, &out)
+	if report.Failed != 0 || report.Copied != 1 {
+		t.Errorf("Expected the conflicting dataset to be overwritten, got %+v", report)
+	}
+}
+
+func TestRunMigrateCommand(t *testing.T) {
+	_, sourceDir := createMigrationSource(t)
+	databasePath := filepath.Join(t.TempDir(), "fmdash.db")
+
+	var stdout, stderr bytes.Buffer
+	code := runMigrateCommand([]string{"-from", "local:" + sourceDir, "-to", "sqlite:" + databasePath}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d:\n%s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "3 copied, 0 skipped, 0 failed") {
+		t.Errorf("Unexpected summary:\n%s", stdout.String())
+	}
+
+	destination, err := CreateSQLStorage(databasePath)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQL storage: %v", err)
+	}
+	defer destination.Close()
+	if player, err := destination.PlayerByUID("migrate-protobuf", 20); err != nil || player.Name != "Winger" {
+		t.Errorf("Protobuf dataset was not decoded into rows: %+v, %v", player, err)
+	}
+
+	for _, args := range [][]string{
+		{"-from", "local:" + sourceDir},
+		{"-from", "local:" + sourceDir, "-to", "local:" + sourceDir},
+		{"-from", "ftp:somewhere", "-to", "sqlite:" + databasePath},
+		{"-from", "local:" + sourceDir, "-to", "sqlite:" + databasePath, "-protobuf"},
+	} {
+		if code := runMigrateCommand(args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: expected exit code 2, got %d", args, code)
+		}
+	}
+}
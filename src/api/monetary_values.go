@@ -0,0 +1,191 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// WagePeriod is the pay period a wage was displayed in by the FM export.
+type WagePeriod string
+
+// Wage periods FM can display. Stored wages are normalized to WagePeriodWeekly.
+const (
+	WagePeriodWeekly  WagePeriod = "week"
+	WagePeriodMonthly WagePeriod = "month"
+	WagePeriodYearly  WagePeriod = "year"
+)
+
+// defaultWagePeriod is assumed when a wage carries no period suffix, matching FM's default display.
+const defaultWagePeriod = WagePeriodWeekly
+
+// Conversion factors to and from a year's pay.
+const (
+	weeksPerYear  = 52
+	monthsPerYear = 12
+)
+
+// wagePeriodSuffixes maps the suffixes FM and its translations append to wages onto a period.
+// Longer suffixes come first so "p/w" is stripped before a bare "w" could match.
+var wagePeriodSuffixes = []struct {
+	suffix string
+	period WagePeriod
+}{
+	{"per week", WagePeriodWeekly},
+	{"per month", WagePeriodMonthly},
+	{"per annum", WagePeriodYearly},
+	{"per year", WagePeriodYearly},
+	{"a week", WagePeriodWeekly},
+	{"a month", WagePeriodMonthly},
+	{"a year", WagePeriodYearly},
+	{"p/w", WagePeriodWeekly},
+	{"p/m", WagePeriodMonthly},
+	{"p/a", WagePeriodYearly},
+	{"p/y", WagePeriodYearly},
+	{"/wk", WagePeriodWeekly},
+	{"/mo", WagePeriodMonthly},
+	{"/yr", WagePeriodYearly},
+	{"/w", WagePeriodWeekly},
+	{"/m", WagePeriodMonthly},
+	{"/a", WagePeriodYearly},
+	{"/y", WagePeriodYearly},
+	{" pw", WagePeriodWeekly},
+	{" pm", WagePeriodMonthly},
+	{" pa", WagePeriodYearly},
+}
+
+// ParseWagePeriod maps a query or request value such as "month" or "p/m" to a WagePeriod.
+// Empty and unknown values return defaultWagePeriod and false.
+func ParseWagePeriod(value string) (WagePeriod, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "week", "weekly", "pw", "p/w":
+		return WagePeriodWeekly, true
+	case "month", "monthly", "pm", "p/m":
+		return WagePeriodMonthly, true
+	case "year", "yearly", "annual", "pa", "p/a":
+		return WagePeriodYearly, true
+	}
+	return defaultWagePeriod, false
+}
+
+// splitWagePeriod strips the period suffix from a wage cell and reports which period it was.
+func splitWagePeriod(rawValue string) (string, WagePeriod) {
+	trimmed := strings.TrimSpace(rawValue)
+	lower := strings.ToLower(trimmed)
+	for _, candidate := range wagePeriodSuffixes {
+		if strings.HasSuffix(lower, candidate.suffix) {
+			return strings.TrimSpace(trimmed[:len(trimmed)-len(candidate.suffix)]), candidate.period
+		}
+	}
+	return trimmed, defaultWagePeriod
+}
+
+// ConvertWageAmount converts a wage between pay periods via its yearly total, rounding to the
+// nearest whole unit.
+func ConvertWageAmount(amount int64, from, to WagePeriod) int64 {
+	if from == to || amount == 0 {
+		return amount
+	}
+	yearly := amount
+	switch from {
+	case WagePeriodWeekly:
+		yearly = amount * weeksPerYear
+	case WagePeriodMonthly:
+		yearly = amount * monthsPerYear
+	}
+	switch to {
+	case WagePeriodWeekly:
+		return int64(math.Round(float64(yearly) / weeksPerYear))
+	case WagePeriodMonthly:
+		return int64(math.Round(float64(yearly) / monthsPerYear))
+	}
+	return yearly
+}
+
+// wagePeriodDisplaySuffixes are appended to wages rendered for a requested period.
+var wagePeriodDisplaySuffixes = map[WagePeriod]string{
+	WagePeriodWeekly:  " p/w",
+	WagePeriodMonthly: " p/m",
+	WagePeriodYearly:  " p/a",
+}
+
+// convertPlayersWagePeriod returns copies of players with their wage amounts, and wage display
+// strings rendered with symbol, converted from weekly to period. A weekly period returns the
+// players unchanged, keeping the wage as the export displayed it.
+func convertPlayersWagePeriod(players []Player, symbol string, period WagePeriod) []Player {
+	if period == WagePeriodWeekly {
+		return players
+	}
+	converted := make([]Player, len(players))
+	copy(converted, players)
+	for i := range converted {
+		player := &converted[i]
+		player.WageAmount = ConvertWageAmount(player.WageAmount, WagePeriodWeekly, period)
+		if strings.ContainsAny(player.Wage, "0123456789") {
+			player.Wage = formatCurrencyAmount(symbol, player.WageAmount) + wagePeriodDisplaySuffixes[period]
+		}
+	}
+	return converted
+}
+
+// convertBargainResultsWagePeriod returns bargain hunter results with their players' wages in
+// the requested period.
+func convertBargainResultsWagePeriod(results []BargainHunterResponse, symbol string, period WagePeriod) []BargainHunterResponse {
+	if period == WagePeriodWeekly {
+		return results
+	}
+	convertedResults := make([]BargainHunterResponse, len(results))
+	for i := range results {
+		player := convertPlayersWagePeriod([]Player{results[i].Player}, symbol, period)[0]
+		convertedResults[i] = BargainHunterResponse{Player: player, ValueScore: results[i].ValueScore}
+	}
+	return convertedResults
+}
+
+// ParseWageValue parses a wage cell such as "£45K p/m" into its display string, the amount
+// normalized to a weekly wage, the period it was shown in and the currency symbol.
+func ParseWageValue(rawValue string) (originalDisplay string, weeklyAmount int64, period WagePeriod, detectedSymbol string) {
+	_, period = splitWagePeriod(rawValue)
+	_, amount, detectedSymbol := FastParseMonetaryValue(rawValue)
+	return rawValue, ConvertWageAmount(amount, period, WagePeriodWeekly), period, detectedSymbol
+}
+
+// ParseTransferValueRange parses a transfer value cell into its bounds and an estimate.
+// Scouted ranges such as "£1.2M - £3.5M" estimate at the midpoint; single values use
+// the same amount for all three.
+func ParseTransferValueRange(rawValue string) (minAmount, maxAmount, estimate int64) {
+	parts := splitTransferValueRange(rawValue)
+	if len(parts) != 2 {
+		_, amount, _ := FastParseMonetaryValue(rawValue)
+		return amount, amount, amount
+	}
+
+	_, lower, _ := FastParseMonetaryValue(parts[0])
+	_, upper, _ := FastParseMonetaryValue(parts[1])
+	if lower > upper {
+		lower, upper = upper, lower
+	}
+	return lower, upper, lower + (upper-lower)/2
+}
+
+// splitTransferValueRange splits "£1.2M - £3.5M" into its two sides. A hyphen only counts
+// as a range separator when digits appear on both sides of it.
+func splitTransferValueRange(rawValue string) []string {
+	idx := strings.Index(rawValue, "-")
+	if idx <= 0 || idx == len(rawValue)-1 {
+		return nil
+	}
+	lower, upper := rawValue[:idx], rawValue[idx+1:]
+	if !strings.ContainsAny(lower, "0123456789") || !strings.ContainsAny(upper, "0123456789") {
+		return nil
+	}
+	return []string{strings.TrimSpace(lower), strings.TrimSpace(upper)}
+}
+
+// playerTransferValueEstimate returns the value used for budget comparisons. Datasets stored
+// before ranges were parsed only carry TransferValueAmount.
+func playerTransferValueEstimate(player *Player) int64 {
+	if player.TransferValueEstimate != 0 {
+		return player.TransferValueEstimate
+	}
+	return player.TransferValueAmount
+}
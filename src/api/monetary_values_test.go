@@ -0,0 +1,99 @@
+package main
+
+import "testing"
+
+func TestParseWageValue(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantWeekly int64
+		wantPeriod WagePeriod
+	}{
+		{"£10K p/w", 10000, WagePeriodWeekly},
+		{"£10K", 10000, WagePeriodWeekly},
+		{"£52K p/m", 12000, WagePeriodMonthly},
+		{"€520K p/a", 10000, WagePeriodYearly},
+		{"$1.04M per year", 20000, WagePeriodYearly},
+		{"£4,333 /m", 1000, WagePeriodMonthly},
+		{"-", 0, WagePeriodWeekly},
+	}
+
+	for _, tt := range tests {
+		display, weekly, period, _ := ParseWageValue(tt.input)
+		if display != tt.input {
+			t.Errorf("ParseWageValue(%q) display = %q, want the original text", tt.input, display)
+		}
+		if weekly != tt.wantWeekly || period != tt.wantPeriod {
+			t.Errorf("ParseWageValue(%q) = %d %s, want %d %s", tt.input, weekly, period, tt.wantWeekly, tt.wantPeriod)
+		}
+	}
+}
+
+func TestConvertWageAmount(t *testing.T) {
+	if got := ConvertWageAmount(10000, WagePeriodWeekly, WagePeriodYearly); got != 520000 {
+		t.Errorf("weekly to yearly = %d, want 520000", got)
+	}
+	if got := ConvertWageAmount(120000, WagePeriodMonthly, WagePeriodWeekly); got != 27692 {
+		t.Errorf("monthly to weekly = %d, want 27692", got)
+	}
+	if got := ConvertWageAmount(5000, WagePeriodMonthly, WagePeriodMonthly); got != 5000 {
+		t.Errorf("same period = %d, want 5000", got)
+	}
+}
+
+func TestConvertPlayersWagePeriod(t *testing.T) {
+	players := []Player{{Name: "Paid", Wage: "£10K p/w", WageAmount: 10000}, {Name: "Unpaid", Wage: "-"}}
+
+	monthly := convertPlayersWagePeriod(players, "£", WagePeriodMonthly)
+	if monthly[0].WageAmount != 43333 || monthly[0].Wage != "£43K p/m" {
+		t.Errorf("monthly wage = %d %q, want 43333 \"£43K p/m\"", monthly[0].WageAmount, monthly[0].Wage)
+	}
+	if monthly[1].Wage != "-" || monthly[1].WageAmount != 0 {
+		t.Errorf("a missing wage should stay missing, got %d %q", monthly[1].WageAmount, monthly[1].Wage)
+	}
+	if players[0].WageAmount != 10000 || players[0].Wage != "£10K p/w" {
+		t.Errorf("the input players should not be modified, got %d %q", players[0].WageAmount, players[0].Wage)
+	}
+	if weekly := convertPlayersWagePeriod(players, "£", WagePeriodWeekly); weekly[0].Wage != "£10K p/w" {
+		t.Errorf("a weekly period should keep the exported wage, got %q", weekly[0].Wage)
+	}
+}
+
+func TestParseTransferValueRange(t *testing.T) {
+	tests := []struct {
+		input                  string
+		wantMin, wantMax, want int64
+	}{
+		{"£1.2M - £3.5M", 1200000, 3500000, 2350000},
+		{"€500K-€1M", 500000, 1000000, 750000},
+		{"£4.5M", 4500000, 4500000, 4500000},
+		{"-", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		minAmount, maxAmount, estimate := ParseTransferValueRange(tt.input)
+		if minAmount != tt.wantMin || maxAmount != tt.wantMax || estimate != tt.want {
+			t.Errorf("ParseTransferValueRange(%q) = %d, %d, %d, want %d, %d, %d",
+				tt.input, minAmount, maxAmount, estimate, tt.wantMin, tt.wantMax, tt.want)
+		}
+	}
+}
+
+func TestBargainHunterUsesNormalizedValues(t *testing.T) {
+	players := []Player{
+		// Displayed monthly, £40K p/m is about £9.2K a week and fits a £10K weekly cap
+		{Name: "Monthly Wage", Overall: 70, TransferValueAmount: 2000000, TransferValueEstimate: 2000000, WageAmount: 9230, WagePeriod: WagePeriodMonthly},
+		{Name: "Too Expensive Weekly", Overall: 70, TransferValueAmount: 2000000, TransferValueEstimate: 2000000, WageAmount: 15000, WagePeriod: WagePeriodWeekly},
+		// A £1M - £5M range estimates at £3M, inside a £4M budget despite its upper bound
+		{Name: "Scouted Range", Overall: 72, TransferValueAmount: 5000000, TransferValueMin: 1000000, TransferValueMax: 5000000, TransferValueEstimate: 3000000, WageAmount: 5000},
+	}
+
+	results := processBargainHunter(players, 4000000, 10000, 0, 0, 0)
+
+	names := make(map[string]bool, len(results))
+	for _, result := range results {
+		names[result.Player.Name] = true
+	}
+	if !names["Monthly Wage"] || !names["Scouted Range"] || names["Too Expensive Weekly"] {
+		t.Errorf("Unexpected bargain hunter results: %v", names)
+	}
+}
@@ -87,6 +87,10 @@ func resetPlayer(player *Player) {
 	player.NationalityISO = ""
 	player.NationalityFIFACode = ""
 	player.BestRoleOverall = ""
+	player.PreferredFoot = ""
+	player.ContractExpiry = ""
+	player.DateOfBirth = ""
+	player.RatingConfidence = ""
 
 	// Reset numeric fields
 	player.Overall = 0
@@ -105,6 +109,17 @@ func resetPlayer(player *Player) {
 	player.POS = 0
 	player.TransferValueAmount = 0
 	player.WageAmount = 0
+	player.WagePeriod = ""
+	player.TransferValueMin = 0
+	player.TransferValueMax = 0
+	player.TransferValueEstimate = 0
+	player.AgeYears = 0
+	player.HeightCm = 0
+	player.WeightKg = 0
+	player.LeftFoot = 0
+	player.RightFoot = 0
+	player.OverallMin = 0
+	player.OverallMax = 0
 
 	// Reset boolean fields
 	player.AttributeMasked = false
@@ -123,6 +138,12 @@ func resetPlayer(player *Player) {
 		delete(player.PerformancePercentiles, k)
 	}
 
+	// Masked-attribute maps are only allocated for masked players
+	player.AttributeMin = nil
+	player.AttributeMax = nil
+	player.StatMin = nil
+	player.StatMax = nil
+
 	// Reset slices but keep capacity
 	player.ParsedPositions = player.ParsedPositions[:0]
 	player.ShortPositions = player.ShortPositions[:0]
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	apperrors "api/errors"
+)
+
+// maxParseReportFailures caps how many failed rows are echoed back to the uploader.
+const maxParseReportFailures = 25
+
+// parseFailureReasons are the sentinel errors a worker can report for a row, used to
+// give each failure a stable reason alongside its detailed message.
+var parseFailureReasons = []error{
+	apperrors.ErrSkippedRowNameMissing,
+	apperrors.ErrSkippedRowEmpty,
+	apperrors.ErrCannotProcessRow,
+}
+
+// ParseReport summarises how the rows of an uploaded file were handled, so users can see
+// which players were dropped and which columns their view exported that we do not use.
+type ParseReport struct {
+	RowsSeen            int                `json:"rowsSeen"`
+	RowsParsed          int                `json:"rowsParsed"`
+	RowsSkipped         int                `json:"rowsSkipped"`
+	RowsEmpty           int                `json:"rowsEmpty"`
+	RowsMasked          int                `json:"rowsMasked"`
+	HeaderLanguage      string             `json:"headerLanguage"`
+	ExportProfile       ExportProfileMatch `json:"exportProfile"`
+	FailedRows          []ParseFailure     `json:"failedRows,omitempty"`
+	UnrecognisedHeaders []string           `json:"unrecognisedHeaders,omitempty"`
+}
+
+// ParseFailure describes a single row that did not produce a player.
+type ParseFailure struct {
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+// RecordResult folds a worker result into the report. It is called from the single
+// results collector goroutine and is therefore not synchronised.
+func (r *ParseReport) RecordResult(result PlayerParseResult) {
+	r.RowsSeen++
+
+	if result.Err == nil {
+		r.RowsParsed++
+		if result.Player.AttributeMasked {
+			r.RowsMasked++
+		}
+		return
+	}
+
+	// Spacer and repeated header rows are routine in FM exports and are not worth reporting
+	if errors.Is(result.Err, apperrors.ErrSkippedRowEmpty) {
+		r.RowsEmpty++
+		return
+	}
+
+	r.RowsSkipped++
+	if len(r.FailedRows) < maxParseReportFailures {
+		r.FailedRows = append(r.FailedRows, ParseFailure{
+			Reason: parseFailureReason(result.Err),
+			Detail: result.Err.Error(),
+		})
+	}
+}
+
+// parseFailureReason returns the message of the sentinel error behind err, or err itself
+// when it does not wrap a known row error.
+func parseFailureReason(err error) string {
+	for _, reason := range parseFailureReasons {
+		if errors.Is(err, reason) {
+			return reason.Error()
+		}
+	}
+	return err.Error()
+}
+
+// findUnrecognisedHeaders returns the non-blank canonical headers that are neither player
+// info columns, FM attributes nor performance stats. Such columns are still kept on
+// Player.Attributes but take no part in ratings or percentiles.
+func findUnrecognisedHeaders(headers []string) []string {
+	var unrecognised []string
+	seen := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		header = strings.TrimSpace(header)
+		if header == "" || seen[header] {
+			continue
+		}
+		seen[header] = true
+		if isCanonicalHeader(header) {
+			continue
+		}
+		unrecognised = append(unrecognised, header)
+	}
+	return unrecognised
+}
+
+// isPerformanceStatKey reports whether key is one of PerformanceStatKeys.
+func isPerformanceStatKey(key string) bool {
+	for _, perfKey := range PerformanceStatKeys {
+		if key == perfKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+func TestParseReportRecordResult(t *testing.T) {
+	var report ParseReport
+
+	report.RecordResult(PlayerParseResult{Player: Player{Name: "Clear"}})
+	report.RecordResult(PlayerParseResult{Player: Player{Name: "Scouted", AttributeMasked: true}})
+	report.RecordResult(PlayerParseResult{Err: apperrors.ErrSkippedRowEmpty})
+	report.RecordResult(PlayerParseResult{Err: apperrors.WrapErrSkippedRowNameMissing("GK, Test FC")})
+	report.RecordResult(PlayerParseResult{Err: errors.New("unexpected worker failure")})
+
+	if report.RowsSeen != 5 || report.RowsParsed != 2 || report.RowsMasked != 1 ||
+		report.RowsEmpty != 1 || report.RowsSkipped != 2 {
+		t.Fatalf("unexpected counters: %+v", report)
+	}
+	if len(report.FailedRows) != 2 {
+		t.Fatalf("expected 2 failed rows, got %d", len(report.FailedRows))
+	}
+
+	nameMissing := report.FailedRows[0]
+	if nameMissing.Reason != apperrors.ErrSkippedRowNameMissing.Error() {
+		t.Errorf("Reason = %q, want sentinel message", nameMissing.Reason)
+	}
+	if nameMissing.Detail == nameMissing.Reason {
+		t.Error("Detail should include the row's first cells, not just the reason")
+	}
+	if report.FailedRows[1].Reason != "unexpected worker failure" {
+		t.Errorf("Reason = %q, want the raw error for unknown failures", report.FailedRows[1].Reason)
+	}
+}
+
+func TestParseReportCapsFailureSample(t *testing.T) {
+	var report ParseReport
+	for i := 0; i < maxParseReportFailures+10; i++ {
+		report.RecordResult(PlayerParseResult{Err: apperrors.WrapErrSkippedRowNameMissing("x")})
+	}
+
+	if report.RowsSkipped != maxParseReportFailures+10 {
+		t.Errorf("RowsSkipped = %d, want %d", report.RowsSkipped, maxParseReportFailures+10)
+	}
+	if len(report.FailedRows) != maxParseReportFailures {
+		t.Errorf("len(FailedRows) = %d, want %d", len(report.FailedRows), maxParseReportFailures)
+	}
+}
+
+func TestFindUnrecognisedHeaders(t *testing.T) {
+	headers := []string{"Name", "UID", "Acc", "L Th", "Mins", "xG/90", "", "Height", "Inf", "Best Pos", "Best Pos", "My Custom"}
+
+	got := findUnrecognisedHeaders(headers)
+	want := []string{"Best Pos", "My Custom"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findUnrecognisedHeaders() = %v, want %v", got, want)
+	}
+}
@@ -115,14 +115,15 @@ func detectCurrencySymbol(rawValue string) string {
 // FastParseMonetaryValue parses monetary values, handling ranges and decimals correctly.
 func FastParseMonetaryValue(rawValue string) (originalDisplay string, numericValue int64, detectedSymbol string) {
 	originalDisplay = rawValue
-	cleanValue := rawValue
-	detectedSymbol = detectCurrencySymbol(rawValue)
+	// Wage period suffixes ("p/w", "p/m") are dropped first; ParseWageValue reads them separately
+	cleanValue, _ := splitWagePeriod(rawValue)
+	detectedSymbol = detectCurrencySymbol(cleanValue)
 
 	// Remove all currency symbols and whitespace
 	for _, sym := range worldCurrencies {
 		cleanValue = strings.ReplaceAll(cleanValue, sym, "")
 	}
-	cleanValue = strings.TrimSpace(cleanValue)
+	cleanValue = normalizeMonetarySeparators(strings.TrimSpace(cleanValue))
 
 	// If it's a range (e.g., '£140M - £183M'), extract the upper bound
 	if strings.Contains(cleanValue, "-") {
@@ -162,6 +163,31 @@ func FastParseMonetaryValue(rawValue string) (originalDisplay string, numericVal
 	return originalDisplay, numericValue, detectedSymbol
 }
 
+// normalizeMonetarySeparators removes thousands separators ("1,500,000") and turns a decimal
+// comma ("1,5M") into a point. A comma counts as a thousands separator when exactly three
+// digits follow it.
+func normalizeMonetarySeparators(value string) string {
+	if !strings.Contains(value, ",") {
+		return value
+	}
+	var builder strings.Builder
+	builder.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] != ',' {
+			builder.WriteByte(value[i])
+			continue
+		}
+		digits := 0
+		for j := i + 1; j < len(value) && value[j] >= '0' && value[j] <= '9'; j++ {
+			digits++
+		}
+		if digits != 3 {
+			builder.WriteByte('.')
+		}
+	}
+	return builder.String()
+}
+
 // ParseMonetaryValueGo parses monetary values with optimized byte operations
 func ParseMonetaryValueGo(rawValue string) (originalDisplay string, numericValue int64, detectedSymbol string) {
 	return FastParseMonetaryValue(rawValue)
@@ -231,10 +257,11 @@ tokenLoop:
 						localHeadersForWorker = make([]string, len(currentHeaders))
 						copy(localHeadersForWorker, currentHeaders)
 						*headersSnapshot = localHeadersForWorker
-						LogDebug("Headers found (tbody start), launching %d workers with %d headers", numWorkers, len(localHeadersForWorker))
+						workerHeaders, exportProfile := resolveExportHeaders(localHeadersForWorker)
+						LogDebug("Headers found (tbody start, profile %s), launching %d workers with %d headers", exportProfile.Key, numWorkers, len(workerHeaders))
 						wg.Add(numWorkers)
 						for i := 0; i < numWorkers; i++ {
-							go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, localHeadersForWorker)
+							go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, workerHeaders)
 						}
 						workersStarted = true
 					}
@@ -301,10 +328,11 @@ tokenLoop:
 						localHeadersForWorker = make([]string, len(currentHeaders))
 						copy(localHeadersForWorker, currentHeaders)
 						*headersSnapshot = localHeadersForWorker
-						LogDebug("Headers found (tr end), launching %d workers with %d headers", numWorkers, len(localHeadersForWorker))
+						workerHeaders, exportProfile := resolveExportHeaders(localHeadersForWorker)
+						LogDebug("Headers found (tr end, profile %s), launching %d workers with %d headers", exportProfile.Key, numWorkers, len(workerHeaders))
 						wg.Add(numWorkers)
 						for i := 0; i < numWorkers; i++ {
-							go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, localHeadersForWorker)
+							go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, workerHeaders)
 						}
 						workersStarted = true
 					}
@@ -322,10 +350,11 @@ tokenLoop:
 					localHeadersForWorker = make([]string, len(currentHeaders))
 					copy(localHeadersForWorker, currentHeaders)
 					*headersSnapshot = localHeadersForWorker
-					LogDebug("Headers found (table end), launching %d workers with %d headers", numWorkers, len(localHeadersForWorker))
+					workerHeaders, exportProfile := resolveExportHeaders(localHeadersForWorker)
+					LogDebug("Headers found (table end, profile %s), launching %d workers with %d headers", exportProfile.Key, numWorkers, len(workerHeaders))
 					wg.Add(numWorkers)
 					for i := 0; i < numWorkers; i++ {
-						go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, localHeadersForWorker)
+						go PlayerParserWorker(i, rowCellsChan, resultsChan, wg, workerHeaders)
 					}
 					workersStarted = true
 				}
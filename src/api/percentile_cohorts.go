@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "api/errors"
+)
+
+// PercentileCohortRequest is the request body for percentiles against a custom cohort.
+type PercentileCohortRequest struct {
+	Cohort     string  `json:"cohort"`               // Filter expression selecting the comparison players
+	PlayerUIDs []int64 `json:"playerUids,omitempty"` // Players to rank; empty ranks every player, a page at a time
+	Limit      int     `json:"limit,omitempty"`      // Page size when ranking every player
+	Cursor     string  `json:"cursor,omitempty"`     // nextCursor of the previous page
+}
+
+// PercentileCohortResponse holds each requested player's percentile per performance stat
+// against the cohort, keyed by UID. Players need not belong to the cohort themselves.
+type PercentileCohortResponse struct {
+	Cohort      string                       `json:"cohort"`     // The expression in canonical form
+	CohortHash  string                       `json:"cohortHash"` // Identifies the cohort in the percentile cache
+	CohortSize  int                          `json:"cohortSize"`
+	Percentiles map[int64]map[string]float64 `json:"percentiles"`
+	NotFound    []int64                      `json:"notFound,omitempty"`
+	Ambiguous   []int64                      `json:"ambiguous,omitempty"`
+	Cached      bool                         `json:"cached"` // Whether the cohort came from the percentile cache
+
+	// Set when ranking every player: players are paged by UID, and those exported without a
+	// UID cannot be keyed and are only counted.
+	Total      int    `json:"total,omitempty"`      // UIDs across all pages
+	NextCursor string `json:"nextCursor,omitempty"` // Empty on the last page
+	WithoutUID int    `json:"withoutUid,omitempty"`
+}
+
+// percentileCohort is the distribution of performance stats within a cohort: for each stat,
+// the sorted values of the cohort members who have it.
+type percentileCohort struct {
+	size         int
+	sortedValues map[string][]float64
+}
+
+// cohortHash identifies a cohort by its expression, so that spellings of the same expression
+// share a cache entry.
+func cohortHash(expression *PlayerFilterExpression) string {
+	sum := sha256.Sum256([]byte(expression.Canonical()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// buildPercentileCohort collects the stat distributions of the players matching expression.
+func buildPercentileCohort(ctx context.Context, players []Player, expression *PlayerFilterExpression) *percentileCohort {
+	members := playerExpressionFilter.FilterPlayersAsync(ctx, players, PlayerFilter{Expression: expression})
+
+	cohort := &percentileCohort{size: len(members), sortedValues: make(map[string][]float64, len(PerformanceStatKeys))}
+	for _, statKey := range PerformanceStatKeys {
+		values := make([]float64, 0, len(members))
+		for i := range members {
+			if val, ok := members[i].PerformanceStatsNumeric[statKey]; ok && !math.IsNaN(val) {
+				values = append(values, val)
+			}
+		}
+		if len(values) > 0 {
+			sort.Float64s(values)
+			cohort.sortedValues[statKey] = values
+		}
+	}
+	return cohort
+}
+
+// percentiles ranks a player's performance stats within the cohort. As with the fixed
+// comparison groups, -1 marks a stat the player or the cohort has no data for.
+func (c *percentileCohort) percentiles(player *Player) map[string]float64 {
+	percentiles := make(map[string]float64, len(PerformanceStatKeys))
+	for _, statKey := range PerformanceStatKeys {
+		sortedValues, hasData := c.sortedValues[statKey]
+		if val, ok := player.PerformanceStatsNumeric[statKey]; ok && hasData && !math.IsNaN(val) {
+			percentiles[statKey] = calculatePercentileValue(val, sortedValues)
+		} else {
+			percentiles[statKey] = -1
+		}
+	}
+	return percentiles
+}
+
+// cohortCacheKey returns the cache key of a dataset's cohort. Cohorts are kept apart from the
+// percentiles: entries, whose division filter parameters could otherwise spell the same key.
+func cohortCacheKey(datasetID, hash string) string {
+	return fmt.Sprintf("cohort:%s:%s", datasetID, hash)
+}
+
+// loadPercentileCohort returns the cohort's distribution, from the cache when it has been
+// built before. Entries are dropped when the dataset changes.
+func loadPercentileCohort(ctx context.Context, datasetID string, players []Player, expression *PlayerFilterExpression) (cohort *percentileCohort, hash string, cached bool) {
+	hash = cohortHash(expression)
+	cacheKey := cohortCacheKey(datasetID, hash)
+	if cachedData, found := getFromMemCache(cacheKey); found {
+		if cohort, ok := cachedData.(*percentileCohort); ok {
+			return cohort, hash, true
+		}
+	}
+
+	cohort = buildPercentileCohort(ctx, players, expression)
+	setInMemCacheForDataset(cacheKey, cohort, 10*time.Minute)
+	return cohort, hash, false
+}
+
+// cohortPlayerPage returns up to limit of the players' UIDs after cursor in ascending order,
+// with the indices of the players holding each, the number of UIDs and the number of players
+// without a UID.
+func cohortPlayerPage(players []Player, limit int, cursor string) (page []int64, indices map[int64][]int, nextCursor string, total, withoutUID int, err error) {
+	after := int64(0)
+	if cursor != "" {
+		if after, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+			return nil, nil, "", 0, 0, fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+
+	indices = make(map[int64][]int, len(players))
+	for i := range players {
+		if players[i].UID <= 0 {
+			withoutUID++
+			continue
+		}
+		indices[players[i].UID] = append(indices[players[i].UID], i)
+	}
+	uids := make([]int64, 0, len(indices))
+	for uid := range indices {
+		uids = append(uids, uid)
+	}
+	slices.Sort(uids)
+
+	start := sort.Search(len(uids), func(i int) bool { return uids[i] > after })
+	end := min(start+limit, len(uids))
+	if end < len(uids) {
+		nextCursor = strconv.FormatInt(uids[end-1], 10)
+	}
+	return uids[start:end], indices, nextCursor, len(uids), withoutUID, nil
+}
+
+// percentileCohortHandler handles POST /api/percentiles-cohort/{datasetId}: percentiles of
+// some or all players against a cohort defined by a filter expression, such as
+// "age < 23 AND pos:ST AND Mins >= 900". All players are returned a page at a time.
+func percentileCohortHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/percentiles-cohort/"), "/")
+	if len(pathParts) == 0 || pathParts[0] == "" {
+		http.Error(w, "Dataset ID is missing in the request path", http.StatusBadRequest)
+		return
+	}
+	datasetID := pathParts[0]
+
+	var req PercentileCohortRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.PlayerUIDs) > maxPercentileBatchSize {
+		http.Error(w, fmt.Sprintf("At most %d players can be requested at once", maxPercentileBatchSize), http.StatusBadRequest)
+		return
+	}
+	if req.Limit < 0 {
+		http.Error(w, "limit cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if req.Limit == 0 || req.Limit > maxPercentileBatchSize {
+		req.Limit = maxPercentileBatchSize
+	}
+
+	players, _, _, found := loadPlayersWithPercentiles(ctx, datasetID, "", "")
+	if !found {
+		logWarn(ctx, "Player data not found", "dataset_id", datasetID)
+		http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
+		return
+	}
+
+	expression, err := CompilePlayerFilterExpression(req.Cohort, players)
+	if err != nil {
+		logWarn(ctx, "Cohort expression rejected", "dataset_id", datasetID, "cohort", req.Cohort, "error", err)
+		http.Error(w, "Invalid cohort: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cohort, hash, cached := loadPercentileCohort(ctx, datasetID, players, expression)
+	logInfo(ctx, "Processing cohort percentiles request",
+		"dataset_id", datasetID,
+		"cohort", expression.Canonical(),
+		"cohort_hash", hash,
+		"cohort_size", cohort.size,
+		"cohort_cached", cached,
+		"player_count", len(req.PlayerUIDs))
+
+	response := PercentileCohortResponse{
+		Cohort:     expression.Canonical(),
+		CohortHash: hash,
+		CohortSize: cohort.size,
+		Cached:     cached,
+	}
+	if len(req.PlayerUIDs) == 0 {
+		page, indices, nextCursor, total, withoutUID, err := cohortPlayerPage(players, req.Limit, req.Cursor)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.Percentiles = make(map[int64]map[string]float64, len(page))
+		for _, uid := range page {
+			if len(indices[uid]) > 1 {
+				response.Ambiguous = append(response.Ambiguous, uid)
+				continue
+			}
+			response.Percentiles[uid] = cohort.percentiles(&players[indices[uid][0]])
+		}
+		response.Total, response.NextCursor, response.WithoutUID = total, nextCursor, withoutUID
+	} else {
+		response.Percentiles = make(map[int64]map[string]float64, len(req.PlayerUIDs))
+		seen := make(map[int64]bool, len(req.PlayerUIDs))
+		for _, uid := range req.PlayerUIDs {
+			if seen[uid] {
+				continue
+			}
+			seen[uid] = true
+
+			index, _, err := findPlayer(players, uid, "")
+			switch {
+			case errors.Is(err, apperrors.ErrAmbiguousPlayer):
+				response.Ambiguous = append(response.Ambiguous, uid)
+			case err != nil:
+				response.NotFound = append(response.NotFound, uid)
+			default:
+				response.Percentiles[uid] = cohort.percentiles(&players[index])
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logError(ctx, "Error encoding cohort percentiles response", "dataset_id", datasetID, "error", err)
+	}
+}
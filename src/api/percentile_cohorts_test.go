@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFilterExpressionCanonical(t *testing.T) {
+	players := filterExpressionTestPlayers()
+
+	canonical := func(expression string) string {
+		t.Helper()
+		compiled, err := CompilePlayerFilterExpression(expression, players)
+		if err != nil {
+			t.Fatalf("%s: compile failed: %v", expression, err)
+		}
+		return compiled.Canonical()
+	}
+
+	want := `age < 23 AND (pos:ST OR club:"North FC") AND value <= 1500000 AND pct:Attackers:xG/90 > 50`
+	for _, expression := range []string{
+		want,
+		`age<23 and (POS:ST or Club:"North FC") and value<=1.5m AND pct:Attackers:xG/90>50`,
+		`  age <  23 AND ( pos : ST OR club:"North FC" ) AND value <= 1500k And PCT:Attackers:xG/90 > 50.0`,
+	} {
+		if got := canonical(expression); got != want {
+			t.Errorf("%s: canonical form %q, want %q", expression, got, want)
+		}
+	}
+}
+
+func TestPercentileCohortHandler(t *testing.T) {
+	InitStore()
+	InitInMemoryCache()
+
+	datasetID := "percentile-cohort-test"
+	striker := func(uid int64, age, division string, position string, minutes, xG float64) Player {
+		return Player{
+			UID: uid, Name: division + " " + age, Age: age, Division: division, ShortPositions: []string{position},
+			PerformanceStatsNumeric: map[string]float64{"Mins": minutes, "xG/90": xG},
+		}
+	}
+	SetPlayerData(datasetID, []Player{
+		striker(1, "21", "Serie A", "ST", 1200, 0.5),
+		striker(2, "22", "Eredivisie", "ST", 1000, 0.3),
+		striker(3, "20", "Serie A", "ST", 400, 0.9),       // Too few minutes
+		striker(4, "28", "Serie A", "ST", 2000, 0.7),      // Too old
+		striker(5, "21", "Serie A", "MC", 1500, 0.1),      // Not a striker
+		striker(6, "19", "Championship", "ST", 1800, 0.8), // Another league
+	}, "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	request := func(body PercentileCohortRequest) (PercentileCohortResponse, *httptest.ResponseRecorder) {
+		encoded, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		percentileCohortHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles-cohort/"+datasetID, bytes.NewReader(encoded)))
+		var response PercentileCohortResponse
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return response, w
+	}
+
+	cohort := `age < 23 AND pos:ST AND (division:"Serie A" OR division:Eredivisie) AND Mins >= 900`
+	response, w := request(PercentileCohortRequest{Cohort: cohort, PlayerUIDs: []int64{1, 2, 4, 5, 99, 1}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.CohortSize != 2 || response.Cached {
+		t.Errorf("Expected a freshly built cohort of 2, got size %d, cached %v", response.CohortSize, response.Cached)
+	}
+	// The cohort's xG/90 values are 0.3 and 0.5; non-members are ranked against them too
+	for uid, want := range map[int64]float64{1: 75, 2: 25, 4: 100, 5: 0} {
+		if got := response.Percentiles[uid]["xG/90"]; got != want {
+			t.Errorf("UID %d: xG/90 percentile %v, want %v", uid, got, want)
+		}
+	}
+	if got := response.Percentiles[1]["Av Rat"]; got != -1 {
+		t.Errorf("Expected -1 for a stat the cohort lacks, got %v", got)
+	}
+	if len(response.NotFound) != 1 || response.NotFound[0] != 99 {
+		t.Errorf("Expected UID 99 not found, got %v", response.NotFound)
+	}
+
+	// Another spelling of the same cohort is served from the cache, for every player
+	respelled, _ := request(PercentileCohortRequest{Cohort: `Mins>=900 and age<23 and pos:st and (division:"Serie A" or division:"Eredivisie")`})
+	if respelled.CohortSize != 2 || len(respelled.Percentiles) != 6 || respelled.Percentiles[6]["xG/90"] != 100 {
+		t.Errorf("Unexpected all-player response: %+v", respelled)
+	}
+	// A player list whose division filter parameters spell the cohort's hash leaves the cohort cached
+	w = httptest.NewRecorder()
+	playerDataHandler(w, httptest.NewRequest(http.MethodGet,
+		"/api/players/"+datasetID+"?divisionFilter=cohort&targetDivision="+response.CohortHash, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for the player list, got %d: %s", w.Code, w.Body.String())
+	}
+	again, _ := request(PercentileCohortRequest{Cohort: "  " + cohort})
+	if !again.Cached || again.CohortHash != response.CohortHash {
+		t.Errorf("Expected the cohort from the cache under hash %s, got cached %v under %s", response.CohortHash, again.Cached, again.CohortHash)
+	}
+
+	for _, body := range []PercentileCohortRequest{{Cohort: ""}, {Cohort: "Mnis >= 900"}} {
+		if _, w := request(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", body.Cohort, w.Code)
+		}
+	}
+}
+
+func TestPercentileCohortHandlerPagesAllPlayers(t *testing.T) {
+	InitStore()
+	InitInMemoryCache()
+
+	datasetID := "percentile-cohort-page-test"
+	player := func(uid int64, name string, xG float64) Player {
+		return Player{UID: uid, Name: name, Age: "24", PerformanceStatsNumeric: map[string]float64{"xG/90": xG}}
+	}
+	SetPlayerData(datasetID, []Player{
+		player(3, "Third", 0.5),
+		player(1, "First", 0.2),
+		player(2, "Second", 0.4),
+		player(0, "Unkeyed", 0.1),
+		player(0, "Also unkeyed", 0.9),
+		player(2, "Second namesake", 0.6),
+	}, "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	request := func(body PercentileCohortRequest) (PercentileCohortResponse, int) {
+		t.Helper()
+		encoded, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		percentileCohortHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles-cohort/"+datasetID, bytes.NewReader(encoded)))
+		var response PercentileCohortResponse
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return response, w.Code
+	}
+
+	first, code := request(PercentileCohortRequest{Cohort: "age < 30", Limit: 2})
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(first.Percentiles) != 1 || first.Percentiles[1] == nil || !reflect.DeepEqual(first.Ambiguous, []int64{2}) ||
+		first.NextCursor != "2" || first.Total != 3 || first.WithoutUID != 2 {
+		t.Errorf("Unexpected first page: %+v", first)
+	}
+	if _, found := first.Percentiles[0]; found {
+		t.Error("Players without a UID were keyed under UID 0")
+	}
+
+	second, _ := request(PercentileCohortRequest{Cohort: "age < 30", Limit: 2, Cursor: first.NextCursor})
+	if len(second.Percentiles) != 1 || second.Percentiles[3]["xG/90"] == 0 || second.NextCursor != "" {
+		t.Errorf("Unexpected last page: %+v", second)
+	}
+
+	for _, body := range []PercentileCohortRequest{{Cohort: "age < 30", Cursor: "abc"}, {Cohort: "age < 30", Limit: -1}} {
+		if _, code := request(body); code != http.StatusBadRequest {
+			t.Errorf("%+v: expected 400, got %d", body, code)
+		}
+	}
+}
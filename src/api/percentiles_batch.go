@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apperrors "api/errors"
+)
+
+// maxPercentileBatchSize caps the number of players in one batch percentiles request.
+const maxPercentileBatchSize = 500
+
+// batchPercentileProcessor calculates percentiles for batch requests missing the cache.
+var batchPercentileProcessor = CreateConcurrentPercentileProcessor(0)
+
+// PercentileBatchRequest is the request body for batch percentile recalculation.
+type PercentileBatchRequest struct {
+	PlayerUIDs     []int64 `json:"playerUids"`
+	DivisionFilter string  `json:"divisionFilter"`
+	TargetDivision string  `json:"targetDivision"`
+}
+
+// PercentileBatchResponse holds the percentile maps of a batch, keyed by player UID. UIDs
+// matching no player, or several, are listed instead.
+type PercentileBatchResponse struct {
+	Percentiles map[int64]map[string]map[string]float64 `json:"percentiles"`
+	NotFound    []int64                                 `json:"notFound,omitempty"`
+	Ambiguous   []int64                                 `json:"ambiguous,omitempty"`
+	Cached      int                                     `json:"cached"` // Players whose percentiles were not calculated for this request
+}
+
+// percentilesBatchHandler handles POST /api/percentiles-batch/{datasetId}. Backends that can
+// query players answer whole-dataset percentiles from the values stored at upload. Otherwise
+// the batch reads the dataset's players with percentiles as the player list does, sharing its
+// percentiles: cache entry, and a cache miss is calculated once for the whole batch by
+// batchPercentileProcessor. Stored and cached percentiles both count as cached.
+func percentilesBatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/percentiles-batch/"), "/")
+	if len(pathParts) == 0 || pathParts[0] == "" {
+		http.Error(w, "Dataset ID is missing in the request path", http.StatusBadRequest)
+		return
+	}
+	datasetID := pathParts[0]
+
+	var req PercentileBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error parsing request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.PlayerUIDs) == 0 {
+		http.Error(w, "playerUids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.PlayerUIDs) > maxPercentileBatchSize {
+		http.Error(w, fmt.Sprintf("At most %d players can be requested at once", maxPercentileBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	logInfo(ctx, "Processing batch percentiles request",
+		"dataset_id", datasetID,
+		"player_count", len(req.PlayerUIDs),
+		"division_filter", req.DivisionFilter,
+		"target_division", req.TargetDivision)
+
+	if response, ok := storedPercentileBatch(ctx, datasetID, req); ok {
+		logDebug(ctx, "Batch percentiles read from stored players",
+			"dataset_id", datasetID,
+			"not_found", len(response.NotFound),
+			"ambiguous", len(response.Ambiguous))
+		writePercentileBatchResponse(ctx, w, r, datasetID, response)
+		return
+	}
+
+	players, _, cacheHit, found := loadPlayersWithPercentilesUsing(ctx, datasetID, req.DivisionFilter, req.TargetDivision,
+		batchPercentileProcessor.ProcessPercentilesWithDivisionFilterAsync)
+	if !found {
+		logWarn(ctx, "Player data not found", "dataset_id", datasetID)
+		http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
+		return
+	}
+
+	response := PercentileBatchResponse{Percentiles: make(map[int64]map[string]map[string]float64, len(req.PlayerUIDs))}
+	seen := make(map[int64]bool, len(req.PlayerUIDs))
+	for _, uid := range req.PlayerUIDs {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+
+		index, _, err := findPlayer(players, uid, "")
+		switch {
+		case errors.Is(err, apperrors.ErrAmbiguousPlayer):
+			response.Ambiguous = append(response.Ambiguous, uid)
+		case err != nil:
+			response.NotFound = append(response.NotFound, uid)
+		default:
+			response.Percentiles[uid] = players[index].PerformancePercentiles
+		}
+	}
+	if cacheHit {
+		response.Cached = len(response.Percentiles)
+	}
+
+	logDebug(ctx, "Batch percentiles ready",
+		"dataset_id", datasetID,
+		"percentile_cache_hit", cacheHit,
+		"not_found", len(response.NotFound),
+		"ambiguous", len(response.Ambiguous))
+
+	writePercentileBatchResponse(ctx, w, r, datasetID, response)
+}
+
+// writePercentileBatchResponse writes a batch percentiles response as JSON.
+func writePercentileBatchResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, datasetID string, response PercentileBatchResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logError(ctx, "Error encoding batch percentiles response", "dataset_id", datasetID, "error", err)
+	}
+}
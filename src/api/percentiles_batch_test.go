@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPercentilesBatchHandler(t *testing.T) {
+	InitStore()
+	InitInMemoryCache()
+
+	datasetID := "percentiles-batch-test"
+	players := append(namesakeTestPlayers(), Player{UID: 1003, Name: "Duplicate Entry", Club: "Ajax"})
+	SetPlayerData(datasetID, players, "£")
+
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	request := func(body PercentileBatchRequest) PercentileBatchResponse {
+		t.Helper()
+		encoded, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		percentilesBatchHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles-batch/"+datasetID, bytes.NewReader(encoded)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response PercentileBatchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	batch := PercentileBatchRequest{PlayerUIDs: []int64{1002, 1001, 9999, 1003, 1002}, DivisionFilter: "all"}
+	response := request(batch)
+	if len(response.Percentiles) != 2 || response.Cached != 0 {
+		t.Fatalf("Expected 2 calculated players, got %+v", response)
+	}
+	if !reflect.DeepEqual(response.NotFound, []int64{9999}) || !reflect.DeepEqual(response.Ambiguous, []int64{1003}) {
+		t.Errorf("Unexpected unresolved UIDs: not found %v, ambiguous %v", response.NotFound, response.Ambiguous)
+	}
+
+	// The batch agrees with a calculation over the whole dataset, as the single-player endpoint makes
+	expected, _, _ := GetPlayerData(datasetID)
+	expected = OptimizedDeepCopyPlayers(expected)
+	CalculatePlayerPerformancePercentilesWithDivisionFilter(expected, DivisionFilterAll, "")
+	for i, uid := range []int64{1001, 1002} {
+		if !reflect.DeepEqual(expected[i].PerformancePercentiles, response.Percentiles[uid]) {
+			t.Errorf("UID %d: batch percentiles %v differ from calculated %v", uid, response.Percentiles[uid], expected[i].PerformancePercentiles)
+		}
+	}
+
+	// The calculation is cached in memory once for the batch, under the key the player list reads,
+	// rather than saved per player
+	if _, found := getFromMemCache(percentileCacheKey(datasetID, batch.DivisionFilter, batch.TargetDivision)); !found {
+		t.Error("Expected the batch calculation under the percentiles: cache key")
+	}
+	if _, _, cacheHit, _ := loadPlayersWithPercentiles(context.Background(), datasetID, batch.DivisionFilter, batch.TargetDivision); !cacheHit {
+		t.Error("Expected the player list to reuse the batch calculation")
+	}
+	cached := request(batch)
+	if cached.Cached != 2 || !reflect.DeepEqual(cached.Percentiles, response.Percentiles) {
+		t.Errorf("Expected both players from the cache, got %+v", cached)
+	}
+	stored, _, _ := GetPlayerData(datasetID)
+	for i := range stored {
+		cacheKey := generatePercentilesCacheKey(context.Background(), datasetID, &stored[i], "all", "", stored)
+		if _, err := storage.Retrieve("cache_percentiles_" + cacheKey); err == nil {
+			t.Errorf("UID %d: batch saved a per-player percentile cache record", stored[i].UID)
+		}
+	}
+
+	for _, body := range []string{`{"playerUids": []}`, `not json`} {
+		w := httptest.NewRecorder()
+		percentilesBatchHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles-batch/"+datasetID, bytes.NewReader([]byte(body))))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
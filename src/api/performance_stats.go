@@ -140,6 +140,19 @@ const (
 	DivisionFilterTop5
 )
 
+// parseDivisionFilter reads the divisionFilter request parameter: "same", "top5", or "all"
+// and anything else for every division.
+func parseDivisionFilter(value string) DivisionFilter {
+	switch value {
+	case "same":
+		return DivisionFilterSame
+	case "top5":
+		return DivisionFilterTop5
+	default:
+		return DivisionFilterAll
+	}
+}
+
 // TopDivisions lists the top 5 divisions for filtering
 var TopDivisions = []string{
 	"Premier League",
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	apperrors "api/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PlayerDetailResponse is the JSON response for /api/players/{datasetId}/{uid}.
+type PlayerDetailResponse struct {
+	Player         Player     `json:"player"`
+	CurrencySymbol string     `json:"currencySymbol"`
+	BaseCurrency   string     `json:"baseCurrency,omitempty"`
+	WagePeriod     WagePeriod `json:"wagePeriod"` // Period of the player's wage amount
+}
+
+// PlayerCandidate summarises one of several players matching a lookup, enough for a client
+// to pick the right one and repeat the request by UID.
+type PlayerCandidate struct {
+	UID      int64  `json:"uid"`
+	Name     string `json:"name"`
+	Club     string `json:"club"`
+	Age      string `json:"age"`
+	Position string `json:"position"`
+}
+
+// AmbiguousPlayerResponse is the 409 response for a lookup matching several players.
+type AmbiguousPlayerResponse struct {
+	Error      string            `json:"error"`
+	Candidates []PlayerCandidate `json:"candidates"`
+}
+
+// findPlayer returns the index of the player with the given UID or, when uid is 0, with the
+// given name. Names are not unique, so a name matching several players is an
+// ErrAmbiguousPlayer error rather than the first match; matches lists the players found.
+func findPlayer(players []Player, uid int64, name string) (index int, matches []int, err error) {
+	for i := range players {
+		if (uid != 0 && players[i].UID == uid) || (uid == 0 && players[i].Name == name) {
+			matches = append(matches, i)
+		}
+	}
+
+	lookup := fmt.Sprintf("name %q", name)
+	if uid != 0 {
+		lookup = fmt.Sprintf("UID %d", uid)
+	}
+	switch len(matches) {
+	case 0:
+		if uid != 0 {
+			return -1, nil, apperrors.WrapErrPlayerNotFound(uid)
+		}
+		return -1, nil, apperrors.WrapErrPlayerNameNotFound(name)
+	case 1:
+		return matches[0], matches, nil
+	default:
+		return -1, matches, apperrors.WrapErrAmbiguousPlayer(lookup, len(matches))
+	}
+}
+
+// writePlayerLookupError answers a failed findPlayer: 409 with the candidates when the lookup
+// was ambiguous, 404 otherwise.
+func writePlayerLookupError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, players []Player, matches []int) {
+	if !errors.Is(err, apperrors.ErrAmbiguousPlayer) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	response := AmbiguousPlayerResponse{Error: err.Error(), Candidates: make([]PlayerCandidate, 0, len(matches))}
+	for _, index := range matches {
+		player := &players[index]
+		response.Candidates = append(response.Candidates, PlayerCandidate{
+			UID:      player.UID,
+			Name:     player.Name,
+			Club:     player.Club,
+			Age:      player.Age,
+			Position: player.Position,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	w.WriteHeader(http.StatusConflict)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logError(ctx, "Error encoding ambiguous player response", "error", err)
+	}
+}
+
+// playerDetailHandler serves GET /api/players/{datasetId}/{uid}: one player with ratings and
+// percentiles as the player list calculates them. It accepts the list's divisionFilter,
+// targetDivision, currency and wagePeriod parameters.
+func playerDetailHandler(w http.ResponseWriter, r *http.Request, datasetID, uidStr string) {
+	ctx := r.Context()
+
+	uid, err := strconv.ParseInt(uidStr, 10, 64)
+	if err != nil || uid <= 0 {
+		http.Error(w, "Player UID must be a positive number", http.StatusBadRequest)
+		return
+	}
+	SetSpanAttributes(ctx, attribute.Int64("player.uid", uid))
+
+	queryValues := r.URL.Query()
+	requestedCurrency := queryValues.Get("currency")
+	wagePeriod, _ := ParseWagePeriod(queryValues.Get("wagePeriod"))
+	divisionFilterStr, targetDivision := queryValues.Get("divisionFilter"), queryValues.Get("targetDivision")
+
+	player, currencySymbol, ok, err := storedPlayerByUID(ctx, datasetID, divisionFilterStr, targetDivision, uid)
+	if ok && err != nil {
+		logDebug(ctx, "Player lookup failed", "dataset_id", datasetID, "uid", uid, "error", err)
+		writePlayerLookupError(ctx, w, r, err, nil, nil)
+		return
+	}
+	if !ok {
+		players, symbol, _, found := loadPlayersWithPercentiles(ctx, datasetID, divisionFilterStr, targetDivision)
+		if !found {
+			logWarn(ctx, "Player data not found", "dataset_id", datasetID)
+			http.Error(w, "Player data not found for the given ID.", http.StatusNotFound)
+			return
+		}
+
+		index, matches, err := findPlayer(players, uid, "")
+		if err != nil {
+			logDebug(ctx, "Player lookup failed", "dataset_id", datasetID, "uid", uid, "error", err)
+			writePlayerLookupError(ctx, w, r, err, players, matches)
+			return
+		}
+		player, currencySymbol = players[index], symbol
+	}
+
+	baseCurrency := datasetBaseCurrency(datasetID, currencySymbol)
+	converted, currencySymbol, err := convertPlayersForRequest([]Player{player}, currencySymbol, baseCurrency, requestedCurrency)
+	if err != nil {
+		logWarn(ctx, "Currency conversion rejected", "dataset_id", datasetID, "currency", requestedCurrency, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	converted = convertPlayersWagePeriod(converted, currencySymbol, wagePeriod)
+	response := PlayerDetailResponse{Player: converted[0], CurrencySymbol: currencySymbol, BaseCurrency: baseCurrency, WagePeriod: wagePeriod}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logError(ctx, "Error encoding player detail response", "dataset_id", datasetID, "uid", uid, "error", err)
+	}
+}
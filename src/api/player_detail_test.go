@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+// namesakeTestPlayers returns two players sharing a name, told apart by UID and finishing.
+func namesakeTestPlayers() []Player {
+	return []Player{
+		{
+			UID: 1001, Name: "Jordan Henderson", Club: "Ajax", Age: "34", Position: "D/DM/M (C)",
+			PositionGroups: []string{"Midfielders"}, NumericAttributes: map[string]int{"Fin": 8},
+			PerformanceStatsNumeric: map[string]float64{"xG/90": 0.05},
+		},
+		{
+			UID: 1002, Name: "Jordan Henderson", Club: "Hartlepool", Age: "19", Position: "ST (C)",
+			PositionGroups: []string{"Attackers"}, NumericAttributes: map[string]int{"Fin": 15},
+			PerformanceStatsNumeric: map[string]float64{"xG/90": 0.6},
+		},
+		{
+			UID: 1003, Name: "Other Striker", Club: "Ajax", Age: "25", Position: "ST (C)",
+			PositionGroups: []string{"Attackers"}, NumericAttributes: map[string]int{"Fin": 12},
+			PerformanceStatsNumeric: map[string]float64{"xG/90": 0.3},
+		},
+	}
+}
+
+func TestFindPlayer(t *testing.T) {
+	players := namesakeTestPlayers()
+
+	if index, _, err := findPlayer(players, 1002, ""); err != nil || index != 1 {
+		t.Errorf("Lookup by UID: got index %d, %v", index, err)
+	}
+	if index, _, err := findPlayer(players, 1003, "Jordan Henderson"); err != nil || index != 2 {
+		t.Errorf("The UID should take precedence over the name: got index %d, %v", index, err)
+	}
+	if index, _, err := findPlayer(players, 0, "Other Striker"); err != nil || index != 2 {
+		t.Errorf("Lookup by unique name: got index %d, %v", index, err)
+	}
+	if _, matches, err := findPlayer(players, 0, "Jordan Henderson"); !errors.Is(err, apperrors.ErrAmbiguousPlayer) || len(matches) != 2 {
+		t.Errorf("Expected an ambiguous lookup with 2 matches, got %v, %v", matches, err)
+	}
+	for _, tc := range []struct {
+		uid  int64
+		name string
+	}{{9999, ""}, {0, "Nobody"}} {
+		if _, _, err := findPlayer(players, tc.uid, tc.name); !errors.Is(err, apperrors.ErrPlayerNotFound) {
+			t.Errorf("%d/%q: expected ErrPlayerNotFound, got %v", tc.uid, tc.name, err)
+		}
+	}
+}
+
+func TestPlayerDetailEndpoint(t *testing.T) {
+	InitStore()
+
+	datasetID := "player-detail-test"
+	SetPlayerData(datasetID, namesakeTestPlayers(), "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	w := httptest.NewRecorder()
+	playerDataHandler(w, httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"/1002", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PlayerDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Player.UID != 1002 || response.Player.Club != "Hartlepool" || response.CurrencySymbol != "£" {
+		t.Errorf("Unexpected player detail: %+v", response)
+	}
+	if _, found := response.Player.PerformancePercentiles["Global"]["xG/90"]; !found {
+		t.Errorf("Expected global percentiles, got %v", response.Player.PerformancePercentiles)
+	}
+
+	for path, status := range map[string]int{
+		"/api/players/" + datasetID + "/9999":  http.StatusNotFound,
+		"/api/players/" + datasetID + "/abc":   http.StatusBadRequest,
+		"/api/players/missing-dataset/1002":    http.StatusNotFound,
+		"/api/players/" + datasetID + "/-1002": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		playerDataHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != status {
+			t.Errorf("%s: expected %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestPercentilesHandlerByUID(t *testing.T) {
+	InitStore()
+
+	datasetID := "percentiles-uid-test"
+	SetPlayerData(datasetID, namesakeTestPlayers(), "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	request := func(body PercentileRequest) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		percentilesHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles/"+datasetID, bytes.NewReader(encoded)))
+		return w
+	}
+
+	w := request(PercentileRequest{PlayerName: "Jordan Henderson", DivisionFilter: "all"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 for a shared name, got %d: %s", w.Code, w.Body.String())
+	}
+	var ambiguous AmbiguousPlayerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &ambiguous); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0].UID != 1001 || ambiguous.Candidates[1].UID != 1002 {
+		t.Errorf("Unexpected candidates: %+v", ambiguous.Candidates)
+	}
+
+	// Each namesake gets their own percentiles, including from the cache
+	xG := make(map[int64]float64)
+	for _, uid := range []int64{1001, 1002, 1001} {
+		w := request(PercentileRequest{PlayerUID: uid, PlayerName: "Jordan Henderson", DivisionFilter: "all"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("UID %d: expected 200, got %d: %s", uid, w.Code, w.Body.String())
+		}
+		var percentiles map[string]map[string]float64
+		if err := json.Unmarshal(w.Body.Bytes(), &percentiles); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if previous, seen := xG[uid]; seen && previous != percentiles["Global"]["xG/90"] {
+			t.Errorf("UID %d: percentile changed from %v to %v", uid, previous, percentiles["Global"]["xG/90"])
+		}
+		xG[uid] = percentiles["Global"]["xG/90"]
+	}
+	if xG[1001] >= xG[1002] {
+		t.Errorf("Expected the striker's xG/90 percentile above the midfielder's, got %v", xG)
+	}
+
+	if w := request(PercentileRequest{DivisionFilter: "all"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a player, got %d", w.Code)
+	}
+	if w := request(PercentileRequest{PlayerUID: 9999}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown UID, got %d", w.Code)
+	}
+}
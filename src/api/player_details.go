@@ -0,0 +1,299 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// playerDetailDateLayout is the ISO format typed dates are stored and compared in.
+const playerDetailDateLayout = "2006-01-02"
+
+// Plausibility bounds for normalized physical values; anything outside is treated as unparsed.
+const (
+	minPlayerHeightCm = 100
+	maxPlayerHeightCm = 250
+	minPlayerWeightKg = 30
+	maxPlayerWeightKg = 200
+)
+
+// Preferred foot values stored on Player.PreferredFoot.
+const (
+	PreferredFootLeft   = "Left"
+	PreferredFootRight  = "Right"
+	PreferredFootEither = "Either"
+)
+
+// eitherFootThreshold is the rating both feet need before a player counts as two-footed.
+const eitherFootThreshold = 15
+
+// playerDetailHeaders are the columns parsePlayerDetails reads from Player.Attributes.
+// They stay in Attributes for display and are mirrored into typed fields.
+var playerDetailHeaders = map[string]bool{
+	"Height": true, "Weight": true, "Preferred Foot": true,
+	"Expires": true, "Contract Expires": true, "DoB": true, "Date of Birth": true,
+}
+
+// footRatingDescriptors maps FM's text foot ratings onto the middle of their 1-20 band.
+var footRatingDescriptors = map[string]int{
+	"very weak":     3,
+	"weak":          6,
+	"reasonable":    10,
+	"fairly strong": 13,
+	"strong":        16,
+	"very strong":   19,
+}
+
+var (
+	feetInchesPattern  = regexp.MustCompile(`^(\d+)\s*(?:'|ft|feet)\s*(?:(\d+(?:\.\d+)?)\s*(?:"|''|in|inches)?)?$`)
+	stonePoundsPattern = regexp.MustCompile(`^(\d+)\s*st\s*(?:(\d+)\s*(?:lbs?)?)?$`)
+	leadingNumberRegex = regexp.MustCompile(`^\d+(?:[.,]\d+)?`)
+	numericDatePattern = regexp.MustCompile(`^(\d{1,4})[/.\-](\d{1,2})[/.\-](\d{1,4})$`)
+	ageInDoBPattern    = regexp.MustCompile(`\((\d+)\s*(?:years?\s*old|yo)?\)`)
+)
+
+// textDateLayouts are the spelled-out date forms FM uses depending on locale settings.
+var textDateLayouts = []string{
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Jan 2 2006",
+	"January 2 2006",
+}
+
+// parsePlayerDetails fills the typed age, physical, foot and date fields from the raw columns.
+// It is called from EnhancePlayerWithCalculations so merged and reloaded players are re-derived too.
+func parsePlayerDetails(player *Player) {
+	player.AgeYears = parseAgeYears(player.Age)
+	player.HeightCm = parseHeightCm(player.Attributes["Height"])
+	player.WeightKg = parseWeightKg(player.Attributes["Weight"])
+	player.LeftFoot = parseFootRating(player.Attributes["Left Foot"])
+	player.RightFoot = parseFootRating(player.Attributes["Right Foot"])
+
+	player.PreferredFoot = normalizePreferredFoot(player.Attributes["Preferred Foot"])
+	if player.PreferredFoot == "" {
+		player.PreferredFoot = derivePreferredFoot(player.LeftFoot, player.RightFoot)
+	}
+
+	player.ContractExpiry = ""
+	if expiry, ok := parseFMDate(firstAttribute(player, "Expires", "Contract Expires")); ok {
+		player.ContractExpiry = expiry.Format(playerDetailDateLayout)
+	}
+
+	dobValue := firstAttribute(player, "DoB", "Date of Birth")
+	player.DateOfBirth = ""
+	if dob, ok := parseFMDate(dobValue); ok {
+		player.DateOfBirth = dob.Format(playerDetailDateLayout)
+	}
+	// FM's DoB column reads "13/4/2000 (24 years old)", which covers views without an Age column
+	if player.AgeYears == 0 {
+		if match := ageInDoBPattern.FindStringSubmatch(dobValue); match != nil {
+			player.AgeYears, _ = strconv.Atoi(match[1])
+		}
+	}
+}
+
+// firstAttribute returns the first non-empty attribute among keys.
+func firstAttribute(player *Player, keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(player.Attributes[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseAgeYears reads the leading whole number of an age cell such as "24" or "24 years".
+func parseAgeYears(age string) int {
+	age = strings.TrimSpace(age)
+	end := 0
+	for end < len(age) && age[end] >= '0' && age[end] <= '9' {
+		end++
+	}
+	years, err := strconv.Atoi(age[:end])
+	if err != nil {
+		return 0
+	}
+	return years
+}
+
+// parseHeightCm normalizes "188 cm", "1.88 m", "6'2\"" and "6 ft 2 in" to whole centimetres.
+// It returns 0 for masked, empty or implausible values.
+func parseHeightCm(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "-" {
+		return 0
+	}
+
+	var cm float64
+	switch {
+	case feetInchesPattern.MatchString(value):
+		match := feetInchesPattern.FindStringSubmatch(value)
+		feet, _ := strconv.ParseFloat(match[1], 64)
+		inches := 0.0
+		if match[2] != "" {
+			inches, _ = strconv.ParseFloat(match[2], 64)
+		}
+		cm = (feet*12 + inches) * 2.54
+	case strings.HasSuffix(value, "cm"):
+		cm = parseLeadingFloat(value)
+	case strings.HasSuffix(value, "m"):
+		cm = parseLeadingFloat(value) * 100
+	default:
+		cm = parseLeadingFloat(value)
+		if cm > 0 && cm < 3 { // Bare metres, e.g. "1.88"
+			cm *= 100
+		}
+	}
+
+	rounded := int(math.Round(cm))
+	if rounded < minPlayerHeightCm || rounded > maxPlayerHeightCm {
+		return 0
+	}
+	return rounded
+}
+
+// parseWeightKg normalizes "82 kg", "181 lbs" and "12 st 13 lbs" to whole kilograms.
+// It returns 0 for masked, empty or implausible values.
+func parseWeightKg(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "-" {
+		return 0
+	}
+
+	const kgPerPound = 0.45359237
+	var kg float64
+	switch {
+	case stonePoundsPattern.MatchString(value):
+		match := stonePoundsPattern.FindStringSubmatch(value)
+		stones, _ := strconv.ParseFloat(match[1], 64)
+		pounds := 0.0
+		if match[2] != "" {
+			pounds, _ = strconv.ParseFloat(match[2], 64)
+		}
+		kg = (stones*14 + pounds) * kgPerPound
+	case strings.HasSuffix(value, "lb") || strings.HasSuffix(value, "lbs"):
+		kg = parseLeadingFloat(value) * kgPerPound
+	default: // "kg" or a bare number
+		kg = parseLeadingFloat(value)
+	}
+
+	rounded := int(math.Round(kg))
+	if rounded < minPlayerWeightKg || rounded > maxPlayerWeightKg {
+		return 0
+	}
+	return rounded
+}
+
+// parseLeadingFloat parses the number at the start of value, accepting a decimal comma.
+func parseLeadingFloat(value string) float64 {
+	match := leadingNumberRegex.FindString(value)
+	if match == "" {
+		return 0
+	}
+	parsed, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+// parseFootRating converts a foot column to FM's 1-20 scale. Numeric exports pass through;
+// text descriptors such as "Fairly Strong" map to the middle of their band.
+func parseFootRating(value string) int {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "-" {
+		return 0
+	}
+	if rating, err := fastParseInt(value); err == nil {
+		if rating < 1 || rating > 20 {
+			return 0
+		}
+		return rating
+	}
+	return footRatingDescriptors[value]
+}
+
+// normalizePreferredFoot maps an explicit Preferred Foot column to Left, Right or Either.
+func normalizePreferredFoot(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "":
+		return ""
+	case strings.HasPrefix(value, "left"):
+		return PreferredFootLeft
+	case strings.HasPrefix(value, "right"):
+		return PreferredFootRight
+	case value == "either" || value == "both":
+		return PreferredFootEither
+	}
+	return ""
+}
+
+// derivePreferredFoot infers the preferred foot from the two ratings when the export has
+// no Preferred Foot column. Players strong on both feet count as Either.
+func derivePreferredFoot(leftFoot, rightFoot int) string {
+	if leftFoot == 0 || rightFoot == 0 {
+		return ""
+	}
+	switch {
+	case leftFoot >= eitherFootThreshold && rightFoot >= eitherFootThreshold:
+		return PreferredFootEither
+	case leftFoot > rightFoot:
+		return PreferredFootLeft
+	case rightFoot > leftFoot:
+		return PreferredFootRight
+	}
+	return PreferredFootEither
+}
+
+// parseFMDate parses the date formats FM exports for contract expiry and date of birth.
+// Numeric dates are read day-first, as FM does outside the US, unless only the month-first
+// reading is valid. Trailing text such as "(24 years old)" is ignored.
+func parseFMDate(value string) (time.Time, bool) {
+	if idx := strings.Index(value, "("); idx >= 0 {
+		value = value[:idx]
+	}
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		return time.Time{}, false
+	}
+
+	if match := numericDatePattern.FindStringSubmatch(value); match != nil {
+		first, _ := strconv.Atoi(match[1])
+		second, _ := strconv.Atoi(match[2])
+		third, _ := strconv.Atoi(match[3])
+		if len(match[1]) == 4 {
+			return buildFMDate(first, second, third)
+		}
+		if len(match[3]) != 4 {
+			return time.Time{}, false
+		}
+		if first <= 12 && second > 12 {
+			return buildFMDate(third, first, second)
+		}
+		return buildFMDate(third, second, first)
+	}
+
+	for _, layout := range textDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// buildFMDate validates the parts of a date, rejecting values time.Date would normalize.
+func buildFMDate(year, month, day int) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 {
+		return time.Time{}, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
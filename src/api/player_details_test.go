@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseHeightCm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"188 cm", 188},
+		{"188cm", 188},
+		{"1.88 m", 188},
+		{"1,88 m", 188},
+		{"1.88", 188},
+		{"6'2\"", 188},
+		{"6'2", 188},
+		{"6 ft 2 in", 188},
+		{"5'10", 178},
+		{"6'", 183},
+		{"-", 0},
+		{"", 0},
+		{"12 cm", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseHeightCm(tt.input); got != tt.want {
+			t.Errorf("parseHeightCm(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseWeightKg(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"82 kg", 82},
+		{"82kg", 82},
+		{"82", 82},
+		{"181 lbs", 82},
+		{"181lb", 82},
+		{"12 st 13 lbs", 82},
+		{"13st", 83},
+		{"-", 0},
+		{"5 kg", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseWeightKg(tt.input); got != tt.want {
+			t.Errorf("parseWeightKg(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseFMDate(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOk bool
+	}{
+		{"30/6/2026", "2026-06-30", true},
+		{"30.06.2026", "2026-06-30", true},
+		{"6/30/2026", "2026-06-30", true},
+		{"1/7/2026", "2026-07-01", true}, // Ambiguous dates are read day-first
+		{"2026-06-30", "2026-06-30", true},
+		{"30 Jun 2026", "2026-06-30", true},
+		{"June 30, 2026", "2026-06-30", true},
+		{"13/4/2000 (24 years old)", "2000-04-13", true},
+		{"31/2/2026", "", false},
+		{"30/6/26", "", false},
+		{"-", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseFMDate(tt.input)
+		if ok != tt.wantOk {
+			t.Errorf("parseFMDate(%q) ok = %v, want %v", tt.input, ok, tt.wantOk)
+			continue
+		}
+		if ok && got.Format(playerDetailDateLayout) != tt.want {
+			t.Errorf("parseFMDate(%q) = %s, want %s", tt.input, got.Format(playerDetailDateLayout), tt.want)
+		}
+	}
+}
+
+func TestPreferredFoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		column    string
+		leftFoot  string
+		rightFoot string
+		want      string
+	}{
+		{"explicit column wins", "Left Only", "Very Strong", "Very Strong", PreferredFootLeft},
+		{"derived from descriptors", "", "Very Strong", "Weak", PreferredFootLeft},
+		{"derived from numeric ratings", "", "8", "18", PreferredFootRight},
+		{"two-footed", "", "Strong", "Very Strong", PreferredFootEither},
+		{"unknown without ratings", "", "", "Strong", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := Player{Attributes: map[string]string{
+				"Preferred Foot": tt.column,
+				"Left Foot":      tt.leftFoot,
+				"Right Foot":     tt.rightFoot,
+			}}
+			parsePlayerDetails(&player)
+			if player.PreferredFoot != tt.want {
+				t.Errorf("PreferredFoot = %q, want %q", player.PreferredFoot, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayerDetailsFromUploadedRow(t *testing.T) {
+	headers := []string{"Name", "Age", "Position", "Height", "Weight", "Left Foot", "Right Foot", "Expires", "DoB"}
+	cells := []string{"Tall Defender", "24", "D (C)", "6'3\"", "190 lbs", "Very Strong", "Reasonable", "30/6/2026", "13/4/2000 (24 years old)"}
+
+	player, err := parseCellsToPlayer(cells, headers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	EnhancePlayerWithCalculations(&player)
+
+	if player.AgeYears != 24 || player.HeightCm != 191 || player.WeightKg != 86 {
+		t.Errorf("Unexpected physical fields: age=%d height=%d weight=%d", player.AgeYears, player.HeightCm, player.WeightKg)
+	}
+	if player.LeftFoot != 19 || player.RightFoot != 10 || player.PreferredFoot != PreferredFootLeft {
+		t.Errorf("Unexpected foot fields: left=%d right=%d preferred=%q", player.LeftFoot, player.RightFoot, player.PreferredFoot)
+	}
+	if player.ContractExpiry != "2026-06-30" || player.DateOfBirth != "2000-04-13" {
+		t.Errorf("Unexpected dates: expiry=%q dob=%q", player.ContractExpiry, player.DateOfBirth)
+	}
+	if player.Attributes["Height"] != "6'3\"" {
+		t.Errorf("Expected the raw Height column to be kept, got %q", player.Attributes["Height"])
+	}
+
+	protoPlayer, err := player.ToProto(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to convert to protobuf: %v", err)
+	}
+	converted, err := PlayerFromProto(context.Background(), protoPlayer)
+	if err != nil {
+		t.Fatalf("Failed to convert from protobuf: %v", err)
+	}
+	if converted.HeightCm != player.HeightCm || converted.PreferredFoot != player.PreferredFoot ||
+		converted.ContractExpiry != player.ContractExpiry || converted.DateOfBirth != player.DateOfBirth {
+		t.Errorf("Typed fields lost in protobuf round trip: %+v", converted)
+	}
+}
+
+func TestPlayerListDetailFilters(t *testing.T) {
+	InitStore()
+
+	datasetID := "player-detail-filters-test"
+	players := []Player{
+		{UID: 1, Name: "Tall Veteran", HeightCm: 192, WeightKg: 90, DateOfBirth: "1990-02-01", ContractExpiry: "2026-06-30"},
+		{UID: 2, Name: "Light Prospect", HeightCm: 175, WeightKg: 65, DateOfBirth: "2005-08-20", ContractExpiry: "2029-06-30"},
+		{UID: 3, Name: "Unmeasured", DateOfBirth: "2000-01-01"},
+		{UID: 4, Name: "Undated", HeightCm: 185, WeightKg: 80},
+	}
+	SetPlayerData(datasetID, players, "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	for query, want := range map[string][]string{
+		"minHeight=185":                     {"Tall Veteran", "Undated"},
+		"minWeight=70&maxWeight=85":         {"Undated"},
+		"maxWeight=70":                      {"Light Prospect"},
+		"bornAfter=2000-01-01":              {"Light Prospect"},
+		"bornBefore=13/4/2000":              {"Tall Veteran", "Unmeasured"},
+		"bornAfter=1989-12-31&maxWeight=95": {"Tall Veteran", "Light Prospect"},
+		"contractExpiresBefore=2027-01-01":  {"Tall Veteran"},
+	} {
+		w := httptest.NewRecorder()
+		playerDataHandler(w, httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?"+query, nil))
+		var list struct {
+			Players []Player `json:"players"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s: expected a player list, got %d: %s", query, w.Code, w.Body.String())
+		}
+		got := make([]string, len(list.Players))
+		for i := range list.Players {
+			got[i] = list.Players[i].Name
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	apperrors "api/errors"
+)
+
+// Limits that keep a filter expression cheap to compile and evaluate.
+const (
+	maxFilterExpressionLength = 2000
+	maxFilterExpressionDepth  = 32
+)
+
+// playerExpressionFilter runs filter expressions for /api/players on the chunked worker pool.
+var playerExpressionFilter = CreateAsyncPlayerFilter(0, 500)
+
+// PlayerFilterExpression is a compiled filter expression such as
+//
+//	Fin>=15 AND (pos:ST OR pos:AMC) AND age<=23 AND xG/90>0.4
+//
+// Comparisons take a numeric field on the left and a number on the right, with the operators
+// <, <=, >, >=, = and !=. Numeric fields are:
+//
+//	age, overall, value, wage, height, weight   value is the transfer value estimate, wage is weekly
+//	PAC, SHO, ... POS                           FIFA-style categories
+//	Fin, Pac, ...                               attributes, by their export names
+//	xG/90, "Av Rat", ...                        performance stats
+//	role:"Winger - Attack"                      a role-specific overall
+//	pct:xG/90, pct:Attackers:xG/90              a percentile, globally or within a position group
+//
+// Text conditions are pos:ST, group:Attackers, club:NAME, division:NAME, nat:ENG (name, ISO or
+// FIFA code) and foot:left. Names with spaces or other punctuation are double-quoted. Terms are
+// combined with AND, OR, NOT and parentheses. Numbers may carry a k, m or b suffix, as in
+// value<=2.5m. A player missing a compared value, such as a stat their export lacks, does not
+// match the comparison.
+type PlayerFilterExpression struct {
+	source    string
+	canonical string
+	match     func(player *Player) bool
+}
+
+// Matches reports whether a player satisfies the expression.
+func (e *PlayerFilterExpression) Matches(player *Player) bool {
+	return e.match(player)
+}
+
+// String returns the expression as written.
+func (e *PlayerFilterExpression) String() string {
+	return e.source
+}
+
+// Canonical returns the expression in a single spelling, so that expressions differing only in
+// spacing, keyword case or number notation compare equal: "age<23 and pos:ST" and
+// "age < 23 AND pos:ST" are both "age < 23 AND pos:ST".
+func (e *PlayerFilterExpression) Canonical() string {
+	return e.canonical
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenName
+	filterTokenString
+	filterTokenNumber
+	filterTokenOperator
+	filterTokenColon
+	filterTokenOpenParen
+	filterTokenCloseParen
+)
+
+type filterToken struct {
+	kind     filterTokenKind
+	text     string
+	number   float64
+	position int // 1-based offset in the expression, for error messages
+}
+
+// isFilterNameRune reports whether r may continue an unquoted name, which allows stat names
+// such as xG/90 and NP-xG/90.
+func isFilterNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_/%.-", r)
+}
+
+// isDigitLedFilterName reports whether runes open with a name that starts with digits, such as
+// the goalkeeping attribute 1v1, rather than with a number. Digits followed by a letter are a
+// name unless the letter is a lone k, m or b amount suffix.
+func isDigitLedFilterName(runes []rune) bool {
+	digits := 0
+	for digits < len(runes) && unicode.IsDigit(runes[digits]) {
+		digits++
+	}
+	if digits == len(runes) || !unicode.IsLetter(runes[digits]) {
+		return false
+	}
+	suffixed := strings.ContainsRune("kmbKMB", runes[digits]) &&
+		(digits+1 == len(runes) || !isFilterNameRune(runes[digits+1]))
+	return !suffixed
+}
+
+// lexFilterExpression splits an expression into tokens.
+func lexFilterExpression(input string) ([]filterToken, error) {
+	runes := []rune(input)
+	tokens := make([]filterToken, 0, len(runes)/2)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenOpenParen, text: "(", position: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenCloseParen, text: ")", position: start})
+			i++
+		case r == ':':
+			tokens = append(tokens, filterToken{kind: filterTokenColon, text: ":", position: start})
+			i++
+		case strings.ContainsRune("<>=!", r):
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				operator += "="
+			}
+			i += len(operator)
+			switch operator {
+			case "==":
+				operator = "="
+			case "!":
+				return nil, apperrors.WrapErrInvalidFilterExpression(start, `expected "!=", use NOT to negate a term`)
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: operator, position: start})
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, apperrors.WrapErrInvalidFilterExpression(start, "unterminated quoted name")
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: string(runes[i+1 : end]), position: start})
+			i = end + 1
+		case unicode.IsDigit(r) && isDigitLedFilterName(runes[i:]):
+			end := i + 1
+			for end < len(runes) && isFilterNameRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenName, text: string(runes[i:end]), position: start})
+			i = end
+		case unicode.IsDigit(r) || r == '.' || (r == '-' && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')):
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			text := string(runes[i:end])
+			number, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, apperrors.WrapErrInvalidFilterExpression(start, fmt.Sprintf("invalid number %q", text))
+			}
+			if end < len(runes) {
+				switch unicode.ToLower(runes[end]) {
+				case 'k':
+					number *= 1e3
+					end++
+				case 'm':
+					number *= 1e6
+					end++
+				case 'b':
+					number *= 1e9
+					end++
+				}
+			}
+			if end < len(runes) && isFilterNameRune(runes[end]) {
+				return nil, apperrors.WrapErrInvalidFilterExpression(start, fmt.Sprintf("invalid number %q", string(runes[i:end+1])))
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, text: string(runes[i:end]), number: number, position: start})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && isFilterNameRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenName, text: string(runes[i:end]), position: start})
+			i = end
+		default:
+			return nil, apperrors.WrapErrInvalidFilterExpression(start, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEOF, position: len(runes) + 1}), nil
+}
+
+// canonicalFilterExpression renders tokens in the spelling returned by Canonical.
+func canonicalFilterExpression(tokens []filterToken) string {
+	var canonical strings.Builder
+	for i, token := range tokens {
+		if token.kind == filterTokenEOF {
+			break
+		}
+		if i > 0 && token.kind != filterTokenColon && token.kind != filterTokenCloseParen &&
+			tokens[i-1].kind != filterTokenColon && tokens[i-1].kind != filterTokenOpenParen {
+			canonical.WriteByte(' ')
+		}
+		switch token.kind {
+		case filterTokenString:
+			canonical.WriteString(`"` + token.text + `"`)
+		case filterTokenNumber:
+			canonical.WriteString(strconv.FormatFloat(token.number, 'f', -1, 64))
+		case filterTokenName:
+			switch {
+			case slices.ContainsFunc([]string{"AND", "OR", "NOT"}, func(keyword string) bool { return strings.EqualFold(token.text, keyword) }):
+				canonical.WriteString(strings.ToUpper(token.text))
+			case tokens[i+1].kind == filterTokenColon && (i == 0 || tokens[i-1].kind != filterTokenColon):
+				canonical.WriteString(strings.ToLower(token.text)) // Qualifiers are case-insensitive, pct: groups are not
+			default:
+				canonical.WriteString(token.text)
+			}
+		default:
+			canonical.WriteString(token.text)
+		}
+	}
+	return canonical.String()
+}
+
+// filterParser compiles tokens into a predicate by recursive descent. Field names are checked
+// against the dataset being filtered, so a misspelt attribute is an error rather than a filter
+// that silently matches nobody.
+type filterParser struct {
+	tokens  []filterToken
+	next    int
+	depth   int
+	players []Player
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() filterToken {
+	token := p.tokens[p.next]
+	if token.kind != filterTokenEOF {
+		p.next++
+	}
+	return token
+}
+
+// keyword reports whether the next token is the given keyword, matched case-insensitively.
+func (p *filterParser) keyword(word string) bool {
+	token := p.peek()
+	return token.kind == filterTokenName && strings.EqualFold(token.text, word)
+}
+
+func (p *filterParser) parseOr() (func(*Player) bool, error) {
+	terms := make([]func(*Player) bool, 0, 2)
+	for {
+		term, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.keyword("OR") {
+			break
+		}
+		p.advance()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return func(player *Player) bool {
+		for _, term := range terms {
+			if term(player) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *filterParser) parseAnd() (func(*Player) bool, error) {
+	terms := make([]func(*Player) bool, 0, 2)
+	for {
+		term, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+		if !p.keyword("AND") {
+			break
+		}
+		p.advance()
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return func(player *Player) bool {
+		for _, term := range terms {
+			if !term(player) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (p *filterParser) parseNot() (func(*Player) bool, error) {
+	if !p.keyword("NOT") {
+		return p.parseTerm()
+	}
+	p.advance()
+	term, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return func(player *Player) bool { return !term(player) }, nil
+}
+
+func (p *filterParser) parseTerm() (func(*Player) bool, error) {
+	token := p.advance()
+	switch token.kind {
+	case filterTokenOpenParen:
+		p.depth++
+		if p.depth > maxFilterExpressionDepth {
+			return nil, apperrors.WrapErrInvalidFilterExpression(token.position, "parentheses are nested too deeply")
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.advance(); closing.kind != filterTokenCloseParen {
+			return nil, apperrors.WrapErrInvalidFilterExpression(closing.position, `expected ")"`)
+		}
+		p.depth--
+		return inner, nil
+	case filterTokenName, filterTokenString:
+		if token.kind == filterTokenName && p.peek().kind == filterTokenColon {
+			p.advance()
+			return p.parseQualified(token)
+		}
+		value, err := p.resolveField(token)
+		if err != nil {
+			return nil, err
+		}
+		return p.parseComparison(value)
+	case filterTokenEOF:
+		return nil, apperrors.WrapErrInvalidFilterExpression(token.position, "unexpected end of expression")
+	default:
+		return nil, apperrors.WrapErrInvalidFilterExpression(token.position, fmt.Sprintf("unexpected %q", token.text))
+	}
+}
+
+// parseName reads the name after a qualifier such as pos: or role:.
+func (p *filterParser) parseName() (filterToken, error) {
+	token := p.advance()
+	switch token.kind {
+	case filterTokenName, filterTokenString, filterTokenNumber:
+		return token, nil
+	default:
+		return token, apperrors.WrapErrInvalidFilterExpression(token.position, "expected a name")
+	}
+}
+
+// parseQualified compiles a qualified term: a text condition such as pos:ST, or a role or
+// percentile comparison.
+func (p *filterParser) parseQualified(qualifier filterToken) (func(*Player) bool, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(qualifier.text) {
+	case "role", "pct", "percentile":
+		value, err := p.qualifiedValue(qualifier, name)
+		if err != nil {
+			return nil, err
+		}
+		return p.parseComparison(value)
+	case "pos", "position":
+		position := strings.ToUpper(name.text)
+		if _, known := ShortPositionOrderMap[position]; !known {
+			return nil, apperrors.WrapErrInvalidFilterExpression(name.position, fmt.Sprintf("unknown position %q", name.text))
+		}
+		return func(player *Player) bool { return slices.Contains(player.ShortPositions, position) }, nil
+	case "group":
+		return func(player *Player) bool {
+			return slices.ContainsFunc(player.PositionGroups, func(group string) bool { return strings.EqualFold(group, name.text) })
+		}, nil
+	case "club":
+		return func(player *Player) bool { return strings.EqualFold(player.Club, name.text) }, nil
+	case "division":
+		return func(player *Player) bool { return strings.EqualFold(player.Division, name.text) }, nil
+	case "nat", "nationality":
+		return func(player *Player) bool {
+			return strings.EqualFold(player.Nationality, name.text) || strings.EqualFold(player.NationalityISO, name.text) ||
+				strings.EqualFold(player.NationalityFIFACode, name.text)
+		}, nil
+	case "foot":
+		foot := normalizePreferredFoot(name.text)
+		if foot == "" {
+			return nil, apperrors.WrapErrInvalidFilterExpression(name.position, fmt.Sprintf("unknown foot %q", name.text))
+		}
+		return func(player *Player) bool { return player.PreferredFoot == foot }, nil
+	default:
+		return nil, apperrors.WrapErrInvalidFilterExpression(qualifier.position, fmt.Sprintf("unknown qualifier %q", qualifier.text))
+	}
+}
+
+// qualifiedValue compiles a numeric qualified field: role:NAME, pct:STAT or pct:GROUP:STAT.
+func (p *filterParser) qualifiedValue(qualifier, name filterToken) (func(*Player) (float64, bool), error) {
+	switch strings.ToLower(qualifier.text) {
+	case "role":
+		if !p.anyPlayer(func(player *Player) bool { return playerRoleIndex(player, name.text) >= 0 }) {
+			return nil, apperrors.WrapErrInvalidFilterExpression(name.position, fmt.Sprintf("unknown role %q", name.text))
+		}
+		return func(player *Player) (float64, bool) {
+			if index := playerRoleIndex(player, name.text); index >= 0 {
+				return float64(player.RoleSpecificOveralls[index].Score), true
+			}
+			return 0, false
+		}, nil
+	case "pct", "percentile":
+		group, stat := "Global", name
+		if p.peek().kind == filterTokenColon {
+			p.advance()
+			var err error
+			if stat, err = p.parseName(); err != nil {
+				return nil, err
+			}
+			group = name.text
+		}
+		if !p.hasPercentile(group, stat.text) {
+			return nil, apperrors.WrapErrInvalidFilterExpression(stat.position, fmt.Sprintf("no %s percentile for %q", group, stat.text))
+		}
+		return func(player *Player) (float64, bool) {
+			value, found := player.PerformancePercentiles[group][stat.text]
+			return value, found && value >= 0 // -1 marks a stat the player has no data for
+		}, nil
+	default:
+		return nil, apperrors.WrapErrInvalidFilterExpression(qualifier.position, fmt.Sprintf("%q is not a numeric qualifier", qualifier.text))
+	}
+}
+
+// parseField reads a numeric field on its own, either a plain name or role: or pct:.
+func (p *filterParser) parseField() (func(*Player) (float64, bool), error) {
+	token := p.advance()
+	switch token.kind {
+	case filterTokenName, filterTokenString:
+		if token.kind == filterTokenName && p.peek().kind == filterTokenColon {
+			p.advance()
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			return p.qualifiedValue(token, name)
+		}
+		return p.resolveField(token)
+	case filterTokenEOF:
+		return nil, apperrors.WrapErrInvalidFilterExpression(token.position, "expected a field")
+	default:
+		return nil, apperrors.WrapErrInvalidFilterExpression(token.position, fmt.Sprintf("unexpected %q, expected a field", token.text))
+	}
+}
+
+// parseComparison reads the operator and number that follow a numeric field.
+func (p *filterParser) parseComparison(value func(*Player) (float64, bool)) (func(*Player) bool, error) {
+	operator := p.advance()
+	if operator.kind != filterTokenOperator {
+		return nil, apperrors.WrapErrInvalidFilterExpression(operator.position, "expected a comparison operator")
+	}
+	operand := p.advance()
+	if operand.kind != filterTokenNumber {
+		return nil, apperrors.WrapErrInvalidFilterExpression(operand.position, "expected a number")
+	}
+	limit := operand.number
+
+	var compare func(float64) bool
+	switch operator.text {
+	case "<":
+		compare = func(v float64) bool { return v < limit }
+	case "<=":
+		compare = func(v float64) bool { return v <= limit }
+	case ">":
+		compare = func(v float64) bool { return v > limit }
+	case ">=":
+		compare = func(v float64) bool { return v >= limit }
+	case "=":
+		compare = func(v float64) bool { return v == limit }
+	case "!=":
+		compare = func(v float64) bool { return v != limit }
+	}
+	return func(player *Player) bool {
+		v, found := value(player)
+		return found && compare(v)
+	}, nil
+}
+
+// resolveField looks up a numeric field by name. FIFA categories and attributes are matched
+// case-sensitively, since DRI and Dri are different values.
+func (p *filterParser) resolveField(token filterToken) (func(*Player) (float64, bool), error) {
+	name := token.text
+	if slices.Contains(fifaStatNames, name) {
+		return func(player *Player) (float64, bool) { return float64(fifaStatValue(player, name)), true }, nil
+	}
+
+	switch strings.ToLower(name) {
+	case "age":
+		return func(player *Player) (float64, bool) {
+			age, err := strconv.Atoi(player.Age)
+			return float64(age), err == nil
+		}, nil
+	case "overall":
+		return func(player *Player) (float64, bool) { return float64(player.Overall), true }, nil
+	case "value", "transfervalue":
+		return func(player *Player) (float64, bool) { return float64(playerTransferValueEstimate(player)), true }, nil
+	case "wage", "salary":
+		return func(player *Player) (float64, bool) { return float64(player.WageAmount), true }, nil
+	case "height":
+		return func(player *Player) (float64, bool) { return float64(player.HeightCm), player.HeightCm > 0 }, nil
+	case "weight":
+		return func(player *Player) (float64, bool) { return float64(player.WeightKg), player.WeightKg > 0 }, nil
+	}
+
+	if p.anyPlayer(func(player *Player) bool { _, found := player.NumericAttributes[name]; return found }) {
+		return playerAttributeField(name), nil
+	}
+	if slices.Contains(PerformanceStatKeys, name) ||
+		p.anyPlayer(func(player *Player) bool { _, found := player.PerformanceStatsNumeric[name]; return found }) {
+		return func(player *Player) (float64, bool) {
+			value, found := player.PerformanceStatsNumeric[name]
+			return value, found
+		}, nil
+	}
+	return nil, apperrors.WrapErrInvalidFilterExpression(token.position, fmt.Sprintf("unknown field %q", name))
+}
+
+// playerAttributeField reads an attribute, reporting false for players without it.
+func playerAttributeField(name string) func(*Player) (float64, bool) {
+	return func(player *Player) (float64, bool) {
+		value, found := player.NumericAttributes[name]
+		return float64(value), found
+	}
+}
+
+// hasPercentile reports whether percentiles exist for a stat in a group, either for the known
+// performance stats or for any player of the dataset.
+func (p *filterParser) hasPercentile(group, stat string) bool {
+	if slices.Contains(PerformanceStatKeys, stat) &&
+		(group == "Global" || slices.Contains(PositionGroupsForPercentiles, group) || DetailedPositionGroupsForPercentiles[group] != nil) {
+		return true
+	}
+	return p.anyPlayer(func(player *Player) bool { _, found := player.PerformancePercentiles[group][stat]; return found })
+}
+
+// anyPlayer reports whether any player of the dataset satisfies check.
+func (p *filterParser) anyPlayer(check func(player *Player) bool) bool {
+	for i := range p.players {
+		if check(&p.players[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// playerRoleIndex returns the index of a role in the player's role overalls, or -1.
+func playerRoleIndex(player *Player, roleName string) int {
+	for i := range player.RoleSpecificOveralls {
+		if player.RoleSpecificOveralls[i].RoleName == roleName {
+			return i
+		}
+	}
+	return -1
+}
+
+// CompilePlayerFilterExpression parses an expression into a predicate for the given players.
+// The players are only used to check that the fields it names exist in the dataset.
+func CompilePlayerFilterExpression(expression string, players []Player) (*PlayerFilterExpression, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, apperrors.WrapErrInvalidFilterExpression(1, "expression is empty")
+	}
+	if len(expression) > maxFilterExpressionLength {
+		return nil, apperrors.WrapErrInvalidFilterExpression(maxFilterExpressionLength,
+			fmt.Sprintf("expression is longer than %d characters", maxFilterExpressionLength))
+	}
+
+	tokens, err := lexFilterExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{tokens: tokens, players: players}
+	match, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if trailing := parser.peek(); trailing.kind != filterTokenEOF {
+		return nil, apperrors.WrapErrInvalidFilterExpression(trailing.position, fmt.Sprintf("unexpected %q, expected AND or OR", trailing.text))
+	}
+	return &PlayerFilterExpression{source: expression, canonical: canonicalFilterExpression(tokens), match: match}, nil
+}
+
+// CompilePlayerField resolves a single numeric field, written as it would be on the left of a
+// filter expression comparison, such as Fin, xG/90 or role:"Winger - Attack". The returned
+// function reports false for players missing the value.
+func CompilePlayerField(field string, players []Player) (func(player *Player) (float64, bool), error) {
+	field = strings.TrimSpace(field)
+	if len(field) > maxFilterExpressionLength {
+		return nil, apperrors.WrapErrInvalidFilterExpression(maxFilterExpressionLength,
+			fmt.Sprintf("field is longer than %d characters", maxFilterExpressionLength))
+	}
+
+	tokens, err := lexFilterExpression(field)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{tokens: tokens, players: players}
+	value, err := parser.parseField()
+	if err != nil {
+		return nil, err
+	}
+	if trailing := parser.peek(); trailing.kind != filterTokenEOF {
+		return nil, apperrors.WrapErrInvalidFilterExpression(trailing.position, fmt.Sprintf("unexpected %q after the field", trailing.text))
+	}
+	return value, nil
+}
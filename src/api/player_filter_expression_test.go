@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+func filterExpressionTestPlayers() []Player {
+	return []Player{
+		{
+			Name: "Poacher", Age: "22", Club: "North FC", Nationality: "England", NationalityISO: "GB", NationalityFIFACode: "ENG",
+			ShortPositions: []string{"ST"}, PositionGroups: []string{"Attackers"}, PreferredFoot: PreferredFootRight,
+			NumericAttributes: map[string]int{"Fin": 16, "Pas": 9}, SHO: 80, TransferValueAmount: 3000000, WageAmount: 20000,
+			PerformanceStatsNumeric: map[string]float64{"xG/90": 0.55, "Av Rat": 7.1},
+			PerformancePercentiles:  map[string]map[string]float64{"Global": {"xG/90": 92}, "Attackers": {"xG/90": 85}},
+			RoleSpecificOveralls:    []RoleOverallScore{{RoleName: "ST - Poacher - Attack", Score: 78}},
+		},
+		{
+			Name: "Playmaker", Age: "25", Club: "South FC", Nationality: "Spain", NationalityISO: "ES", NationalityFIFACode: "ESP",
+			ShortPositions: []string{"AMC", "MC"}, PositionGroups: []string{"Midfielders", "Attackers"}, PreferredFoot: PreferredFootLeft,
+			NumericAttributes: map[string]int{"Fin": 13, "Pas": 17, "1v1": 14}, SHO: 70, TransferValueAmount: 8000000, WageAmount: 50000,
+			PerformanceStatsNumeric: map[string]float64{"xG/90": 0.2},
+			PerformancePercentiles:  map[string]map[string]float64{"Global": {"xG/90": -1}},
+		},
+		{
+			Name: "Prospect", Age: "18", Club: "North FC", Nationality: "England", NationalityISO: "GB", NationalityFIFACode: "ENG",
+			ShortPositions: []string{"AMC"}, PositionGroups: []string{"Attackers"}, PreferredFoot: PreferredFootEither,
+			NumericAttributes: map[string]int{"Fin": 15, "Pas": 12}, SHO: 65, TransferValueAmount: 500000, WageAmount: 2000,
+		},
+	}
+}
+
+func TestCompilePlayerFilterExpression(t *testing.T) {
+	players := filterExpressionTestPlayers()
+
+	for _, tc := range []struct {
+		expression string
+		want       []string
+	}{
+		{"Fin>=15 AND (pos:ST OR pos:AMC) AND age<=23 AND xG/90>0.4", []string{"Poacher"}},
+		{"Fin>=15 AND (pos:st OR pos:amc) AND age<=23", []string{"Poacher", "Prospect"}},
+		{"NOT club:\"north fc\"", []string{"Playmaker"}},
+		{"Pas > 10 and value <= 1m or SHO == 80", []string{"Poacher", "Prospect"}},
+		{"wage < 25k AND nat:ENG AND foot:either", []string{"Prospect"}},
+		{"pct:xG/90 >= 50", []string{"Poacher"}},
+		{"pct:Attackers:xG/90 >= 50 OR group:Midfielders", []string{"Poacher", "Playmaker"}},
+		{`role:"ST - Poacher - Attack" > 70`, []string{"Poacher"}},
+		{`"Av Rat" != 7.1`, []string{}},
+		{"NOT NOT xG/90 < 0.3", []string{"Playmaker"}},
+		{"1v1 >= 12 AND wage > 25k", []string{"Playmaker"}},
+	} {
+		expression, err := CompilePlayerFilterExpression(tc.expression, players)
+		if err != nil {
+			t.Errorf("%s: compile failed: %v", tc.expression, err)
+			continue
+		}
+		got := make([]string, 0)
+		for i := range players {
+			if expression.Matches(&players[i]) {
+				got = append(got, players[i].Name)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.expression, got, tc.want)
+		}
+	}
+
+	for _, expression := range []string{
+		"",
+		"Fni >= 15",
+		"dri >= 15",
+		"pos:XYZ",
+		"Fin >=",
+		"Fin 15",
+		"(Fin > 10",
+		"Fin > 10)",
+		"Fin > 10 pos:ST",
+		"!pos:ST",
+		"Fin > 10abc",
+		`club:"unterminated`,
+		`role:"No Such Role" > 1`,
+		"pct:Keepers:xG/90 > 1",
+		"foot:hand",
+		"colour:red",
+	} {
+		if _, err := CompilePlayerFilterExpression(expression, players); !errors.Is(err, apperrors.ErrInvalidFilterExpression) {
+			t.Errorf("%q: expected ErrInvalidFilterExpression, got %v", expression, err)
+		}
+	}
+}
+
+func TestFilterPlayersAsyncWithExpression(t *testing.T) {
+	players := make([]Player, 250)
+	for i := range players {
+		players[i] = Player{Name: fmt.Sprintf("Player %d", i), NumericAttributes: map[string]int{"Fin": i % 20}}
+	}
+	expression, err := CompilePlayerFilterExpression("Fin >= 18", players)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	filtered := CreateAsyncPlayerFilter(4, 10).FilterPlayersAsync(context.Background(), players, PlayerFilter{Expression: expression})
+	if len(filtered) != 24 {
+		t.Fatalf("Expected 24 players, got %d", len(filtered))
+	}
+	for i := 1; i < len(filtered); i++ {
+		var previous, current int
+		fmt.Sscanf(filtered[i-1].Name, "Player %d", &previous)
+		fmt.Sscanf(filtered[i].Name, "Player %d", &current)
+		if previous >= current {
+			t.Fatalf("Chunked filtering lost the players' order: %s before %s", filtered[i-1].Name, filtered[i].Name)
+		}
+	}
+}
+
+func TestPlayersEndpointFilterExpression(t *testing.T) {
+	InitStore()
+
+	datasetID := "filter-expression-test"
+	SetPlayerData(datasetID, filterExpressionTestPlayers(), "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?filter="+url.QueryEscape("Fin>=15 AND pos:AMC"), nil)
+	w := httptest.NewRecorder()
+	playerDataHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PlayerDataWithCurrency
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Players) != 1 || response.Players[0].Name != "Prospect" {
+		t.Errorf("Expected only the prospect, got %+v", response.Players)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?filter="+url.QueryEscape("Fin>=15 AND"), nil)
+	w = httptest.NewRecorder()
+	playerDataHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an incomplete expression, got %d", w.Code)
+	}
+}
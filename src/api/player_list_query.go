@@ -0,0 +1,282 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	apperrors "api/errors"
+)
+
+// maxPlayerPageSize caps the limit parameter of /api/players.
+const maxPlayerPageSize = 1000
+
+// PlayerListResponse is the JSON response for /api/players. Players holds []Player, or one map
+// per player when the request projects fields.
+type PlayerListResponse struct {
+	Players        any        `json:"players"`
+	CurrencySymbol string     `json:"currencySymbol"`
+	BaseCurrency   string     `json:"baseCurrency,omitempty"`
+	WagePeriod     WagePeriod `json:"wagePeriod"`           // Period of the players' wage amounts
+	Total          int        `json:"total"`                // Players matching the filters, across all pages
+	NextCursor     string     `json:"nextCursor,omitempty"` // Empty on the last page
+}
+
+// PlayerListQuery holds the sorting, paging and projection parameters of /api/players.
+type PlayerListQuery struct {
+	Sort       string   // A field as written in filter expressions, or name, club, division or nationality
+	Descending bool     // Set by a :desc suffix on the sort parameter
+	Cursor     string   // nextCursor of the previous page
+	Limit      int      // Players per page; 0 returns every player after the cursor
+	Fields     []string // JSON field names to keep; empty keeps every field
+}
+
+// paginated reports whether the query sorts or pages, as opposed to returning the filtered
+// players in dataset order.
+func (q PlayerListQuery) paginated() bool {
+	return q.Sort != "" || q.Cursor != "" || q.Limit > 0
+}
+
+// playerJSONFields maps the JSON names of Player's fields to their indexes, for projection.
+var playerJSONFields = func() map[string]int {
+	fields := make(map[string]int)
+	playerType := reflect.TypeOf(Player{})
+	for i := 0; i < playerType.NumField(); i++ {
+		name, _, _ := strings.Cut(playerType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// playerTextSortFields are the text fields that can be sorted on, compared case-insensitively.
+var playerTextSortFields = map[string]func(*Player) string{
+	"name":        func(player *Player) string { return player.Name },
+	"club":        func(player *Player) string { return player.Club },
+	"division":    func(player *Player) string { return player.Division },
+	"nationality": func(player *Player) string { return player.Nationality },
+}
+
+// parsePlayerListQuery reads limit, cursor, sort and fields. Sort fields are resolved later
+// against the dataset by compilePlayerSort.
+func parsePlayerListQuery(values url.Values) (PlayerListQuery, error) {
+	query := PlayerListQuery{Cursor: values.Get("cursor")}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPlayerPageSize {
+			return query, apperrors.WrapErrInvalidPlayerQuery("limit", fmt.Sprintf("must be a number from 1 to %d", maxPlayerPageSize))
+		}
+		query.Limit = limit
+	}
+
+	// role:NAME and pct:GROUP:STAT contain colons too, so only a trailing :asc or :desc is a direction
+	query.Sort = strings.TrimSpace(values.Get("sort"))
+	if field, direction, found := cutLast(query.Sort, ":"); found {
+		switch strings.ToLower(direction) {
+		case "desc":
+			query.Sort, query.Descending = strings.TrimSpace(field), true
+		case "asc":
+			query.Sort = strings.TrimSpace(field)
+		}
+	}
+
+	if fieldsStr := values.Get("fields"); fieldsStr != "" {
+		for _, field := range strings.Split(fieldsStr, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if _, known := playerJSONFields[field]; !known {
+				return query, apperrors.WrapErrInvalidPlayerQuery("fields", fmt.Sprintf("unknown field %q", field))
+			}
+			if !slices.Contains(query.Fields, field) {
+				query.Fields = append(query.Fields, field)
+			}
+		}
+	}
+	return query, nil
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// playerSortPosition is where a player falls in a sorted list. Cursors carry the position of
+// the last player of a page rather than an offset, so a page picks up after that player even
+// when the cached percentiles behind the list have been recalculated in the meantime.
+type playerSortPosition struct {
+	Missing bool    `json:"m,omitempty"` // The player has no value for the sort field
+	Number  float64 `json:"v,omitempty"`
+	Text    string  `json:"t,omitempty"`
+	UID     int64   `json:"u"`
+	Name    string  `json:"n"`
+}
+
+// playerCursor is the decoded form of a nextCursor.
+type playerCursor struct {
+	Query    string             `json:"q"` // Signature of the filters and sort it was issued for
+	Position playerSortPosition `json:"p"`
+}
+
+// playerSort orders players by one field, then by UID and name so that every player has a
+// distinct position. Players missing the field come last in either direction.
+type playerSort struct {
+	descending bool
+	number     func(*Player) (float64, bool)
+	text       func(*Player) string
+}
+
+// compilePlayerSort resolves the sort field against the whole dataset rather than the players a
+// request's filters keep, so a field no kept player holds still sorts. Without a sort field
+// players are ordered by UID.
+func compilePlayerSort(query PlayerListQuery, players []Player) (*playerSort, error) {
+	sort := &playerSort{descending: query.Descending}
+	if query.Sort == "" {
+		return sort, nil
+	}
+	if text, found := playerTextSortFields[strings.ToLower(query.Sort)]; found {
+		sort.text = func(player *Player) string { return strings.ToLower(text(player)) }
+		return sort, nil
+	}
+	number, err := CompilePlayerField(query.Sort, players)
+	if err != nil {
+		return nil, apperrors.WrapErrInvalidPlayerQuery("sort", err.Error())
+	}
+	sort.number = number
+	return sort, nil
+}
+
+func (s *playerSort) position(player *Player) playerSortPosition {
+	position := playerSortPosition{UID: player.UID, Name: player.Name}
+	switch {
+	case s.number != nil:
+		value, found := s.number(player)
+		position.Number, position.Missing = value, !found
+	case s.text != nil:
+		position.Text = s.text(player)
+	}
+	return position
+}
+
+func (s *playerSort) compare(a, b playerSortPosition) int {
+	if a.Missing != b.Missing {
+		if a.Missing {
+			return 1
+		}
+		return -1
+	}
+	order := cmp.Compare(a.Number, b.Number)
+	if order == 0 {
+		order = strings.Compare(a.Text, b.Text)
+	}
+	if s.descending {
+		order = -order
+	}
+	if order != 0 {
+		return order
+	}
+	if order = cmp.Compare(a.UID, b.UID); order != 0 {
+		return order
+	}
+	return strings.Compare(a.Name, b.Name)
+}
+
+// playerQuerySignature identifies the filters and sort of a request, so that a cursor is only
+// accepted by the query that issued it. Limit and fields may change between pages.
+func playerQuerySignature(filtersKey string, query PlayerListQuery) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%t", filtersKey, query.Sort, query.Descending)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodePlayerCursor(cursor playerCursor) string {
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodePlayerCursor(value, signature string) (playerSortPosition, error) {
+	var cursor playerCursor
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(decoded, &cursor)
+	}
+	if err != nil {
+		return playerSortPosition{}, apperrors.WrapErrInvalidPlayerQuery("cursor", "malformed cursor")
+	}
+	if cursor.Query != signature {
+		return playerSortPosition{}, apperrors.WrapErrInvalidPlayerQuery("cursor", "cursor was issued for a different filter or sort")
+	}
+	return cursor.Position, nil
+}
+
+// pagePlayers sorts the filtered players by sort, compiled for the query by compilePlayerSort,
+// and returns the page after the query's cursor, with the cursor for the next page when more
+// players follow.
+func pagePlayers(players []Player, query PlayerListQuery, sort *playerSort, signature string) ([]Player, string, error) {
+	if !query.paginated() {
+		return players, "", nil
+	}
+
+	positions := make([]playerSortPosition, len(players))
+	order := make([]int, len(players))
+	for i := range players {
+		positions[i] = sort.position(&players[i])
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return sort.compare(positions[a], positions[b]) })
+
+	start := 0
+	if query.Cursor != "" {
+		after, err := decodePlayerCursor(query.Cursor, signature)
+		if err != nil {
+			return nil, "", err
+		}
+		start, _ = slices.BinarySearchFunc(order, after, func(index int, target playerSortPosition) int {
+			if sort.compare(positions[index], target) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+	end := len(order)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+	}
+
+	page := make([]Player, 0, end-start)
+	for _, index := range order[start:end] {
+		page = append(page, players[index])
+	}
+	nextCursor := ""
+	if end < len(order) {
+		nextCursor = encodePlayerCursor(playerCursor{Query: signature, Position: positions[order[end-1]]})
+	}
+	return page, nextCursor, nil
+}
+
+// projectPlayers keeps only the requested JSON fields of each player.
+func projectPlayers(players []Player, fields []string) []map[string]any {
+	projected := make([]map[string]any, len(players))
+	for i := range players {
+		value := reflect.ValueOf(&players[i]).Elem()
+		player := make(map[string]any, len(fields))
+		for _, field := range fields {
+			player[field] = value.Field(playerJSONFields[field]).Interface()
+		}
+		projected[i] = player
+	}
+	return projected
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+func TestParsePlayerListQuery(t *testing.T) {
+	query, err := parsePlayerListQuery(url.Values{
+		"sort":   {`role:"ST - Poacher - Attack":DESC`},
+		"limit":  {"25"},
+		"fields": {"name, uid,name,,Overall"},
+	})
+	if err != nil {
+		t.Fatalf("parsePlayerListQuery failed: %v", err)
+	}
+	want := PlayerListQuery{Sort: `role:"ST - Poacher - Attack"`, Descending: true, Limit: 25, Fields: []string{"name", "uid", "Overall"}}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("Got %+v, want %+v", query, want)
+	}
+
+	if query, _ := parsePlayerListQuery(url.Values{"sort": {"pct:Attackers:xG/90"}}); query.Sort != "pct:Attackers:xG/90" || query.Descending {
+		t.Errorf("A percentile sort without a direction was split: %+v", query)
+	}
+
+	for _, values := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"1001"}},
+		{"limit": {"ten"}},
+		{"fields": {"name,salary"}},
+	} {
+		if _, err := parsePlayerListQuery(values); !errors.Is(err, apperrors.ErrInvalidPlayerQuery) {
+			t.Errorf("%v: expected ErrInvalidPlayerQuery, got %v", values, err)
+		}
+	}
+}
+
+func TestPagePlayers(t *testing.T) {
+	players := filterExpressionTestPlayers()
+	players[0].UID, players[1].UID, players[2].UID = 30, 10, 20
+	players = append(players, Player{UID: 5, Name: "Unknown"}) // No attributes, so no finishing value
+
+	names := func(page []Player) []string {
+		got := make([]string, len(page))
+		for i := range page {
+			got[i] = page[i].Name
+		}
+		return got
+	}
+
+	pageOf := func(query PlayerListQuery) ([]Player, string, error) {
+		sort, err := compilePlayerSort(query, players)
+		if err != nil {
+			return nil, "", err
+		}
+		return pagePlayers(players, query, sort, "signature")
+	}
+
+	query := PlayerListQuery{Sort: "Fin", Descending: true, Limit: 2}
+	page, cursor, err := pageOf(query)
+	if err != nil {
+		t.Fatalf("pagePlayers failed: %v", err)
+	}
+	if got := names(page); !reflect.DeepEqual(got, []string{"Poacher", "Prospect"}) || cursor == "" {
+		t.Fatalf("First page: got %v with cursor %q", got, cursor)
+	}
+	query.Cursor = cursor
+	page, cursor, err = pageOf(query)
+	if err != nil {
+		t.Fatalf("pagePlayers failed: %v", err)
+	}
+	if got := names(page); !reflect.DeepEqual(got, []string{"Playmaker", "Unknown"}) || cursor != "" {
+		t.Errorf("Second page: got %v with cursor %q, players missing the field should come last", got, cursor)
+	}
+
+	// Without a sort field pages follow UID order
+	page, _, _ = pageOf(PlayerListQuery{Limit: 10})
+	if got := names(page); !reflect.DeepEqual(got, []string{"Unknown", "Playmaker", "Prospect", "Poacher"}) {
+		t.Errorf("Unsorted pages: got %v", got)
+	}
+	page, _, _ = pageOf(PlayerListQuery{Sort: "club", Limit: 10})
+	if got := names(page); !reflect.DeepEqual(got, []string{"Unknown", "Prospect", "Poacher", "Playmaker"}) {
+		t.Errorf("Club sort: got %v", got)
+	}
+
+	if _, err := compilePlayerSort(PlayerListQuery{Sort: "Fni", Limit: 2}, players); !errors.Is(err, apperrors.ErrInvalidPlayerQuery) {
+		t.Errorf("Expected ErrInvalidPlayerQuery for an unknown sort field, got %v", err)
+	}
+	for _, badCursor := range []string{"not-a-cursor", encodePlayerCursor(playerCursor{Query: "other"})} {
+		query.Cursor = badCursor
+		if _, _, err := pageOf(query); !errors.Is(err, apperrors.ErrInvalidPlayerQuery) {
+			t.Errorf("Expected ErrInvalidPlayerQuery for cursor %q, got %v", badCursor, err)
+		}
+	}
+}
+
+func TestPlayersEndpointPagination(t *testing.T) {
+	InitStore()
+
+	datasetID := "player-list-query-test"
+	players := make([]Player, 7)
+	for i := range players {
+		players[i] = Player{UID: int64(100 + i), Name: fmt.Sprintf("Player %d", i), NumericAttributes: map[string]int{"Fin": i % 3}}
+	}
+	SetPlayerData(datasetID, players, "£")
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	type projectedPage struct {
+		Players []struct {
+			UID  int64  `json:"uid"`
+			Name string `json:"name"`
+		} `json:"players"`
+		Total      int    `json:"total"`
+		NextCursor string `json:"nextCursor"`
+	}
+	fetch := func(query url.Values) (projectedPage, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		playerDataHandler(w, httptest.NewRequest(http.MethodGet, "/api/players/"+datasetID+"?"+query.Encode(), nil))
+		var page projectedPage
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return page, w
+	}
+
+	// Walk every page, dropping the cached percentiles between requests
+	var seen []int64
+	query := url.Values{"sort": {"Fin:desc"}, "limit": {"3"}, "fields": {"uid,name"}}
+	for pages := 0; ; pages++ {
+		page, w := fetch(query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if page.Total != len(players) {
+			t.Errorf("Expected a total of %d, got %d", len(players), page.Total)
+		}
+		var raw struct {
+			Players []map[string]any `json:"players"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &raw)
+		for i, player := range page.Players {
+			if len(raw.Players[i]) != 2 {
+				t.Errorf("Expected only uid and name, got %v", raw.Players[i])
+			}
+			seen = append(seen, player.UID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if pages > len(players) {
+			t.Fatal("Pagination did not terminate")
+		}
+		invalidateDatasetCache(datasetID)
+		query.Set("cursor", page.NextCursor)
+	}
+	if want := []int64{102, 105, 101, 104, 100, 103, 106}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("Pages returned %v, want %v", seen, want)
+	}
+
+	// A cursor belongs to the filters and sort that issued it
+	query.Set("sort", "Fin:asc")
+	if _, w := fetch(query); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a cursor from another sort, got %d", w.Code)
+	}
+	if _, w := fetch(url.Values{"sort": {"Fni"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown sort field, got %d", w.Code)
+	}
+
+	// Sort fields are resolved against the whole dataset, so filters keeping no player with the
+	// field, or no player at all, still sort
+	players[6].NumericAttributes["Han"] = 14
+	SetPlayerData(datasetID, players, "£")
+	for _, query := range []url.Values{
+		{"sort": {"Han:desc"}, "maxAge": {"30"}},
+		{"sort": {"Fin"}, "filter": {"Fin > 5"}},
+	} {
+		page, w := fetch(query)
+		if w.Code != http.StatusOK || len(page.Players) != 0 || page.Total != 0 {
+			t.Errorf("%v: expected an empty page, got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
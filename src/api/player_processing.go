@@ -69,6 +69,33 @@ func fastParseFloat(s string) (float64, error) {
 	return strconv.ParseFloat(s, 64)
 }
 
+// fmAttributeKeys are the FM attribute columns that can be masked by scouting knowledge.
+var fmAttributeKeys = map[string]bool{
+	// Physical
+	"Acc": true, "Pac": true, "Str": true, "Sta": true, "Nat": true, "Bal": true, "Jum": true, "Agi": true,
+	// Mental
+	"Agg": true, "Ant": true, "Bra": true, "Cmp": true, "Cnt": true, "Dec": true, "Det": true, "Fla": true,
+	"Ldr": true, "OtB": true, "Pos": true, "Tea": true, "Vis": true, "Wor": true,
+	// Technical
+	"Cor": true, "Cro": true, "Dri": true, "Fin": true, "Fir": true, "Fre": true, "Hea": true, "Lon": true,
+	"L Th": true, "Mar": true, "Pas": true, "Pen": true, "Tck": true, "Tec": true,
+	// Goalkeeping
+	"Aer": true, "Cmd": true, "Com": true, "Ecc": true, "Han": true, "Kic": true, "1v1": true, "Ref": true,
+	"TRO": true, "Thr": true, "Pun": true,
+	// Other potential FM attributes
+	"Left Foot": true, "Right Foot": true,
+}
+
+// playerInfoHeaders are the non-attribute columns parseCellsToPlayer maps onto Player fields
+// or deliberately ignores. Keep in sync with the switch in parseCellsToPlayer.
+var playerInfoHeaders = map[string]bool{
+	"UID": true, "uid": true, "Uid": true, "ID": true, "id": true, "Id": true, "Player ID": true,
+	"PlayerId": true, "player_id": true, "unique_id": true, "UniqueId": true,
+	"Name": true, "Position": true, "Age": true, "Club": true, "Division": true,
+	"Transfer Value": true, "Wage": true, "Personality": true, "Media Handling": true,
+	"Inf": true, "Rec": true, "Salary": true,
+}
+
 // parseCellsToPlayer converts a slice of string cells (a row from the HTML table)
 // into a Player struct, based on the provided headers.
 func parseCellsToPlayer(cells, headers []string) (Player, error) {
@@ -135,9 +162,10 @@ func parseCellsToPlayer(cells, headers []string) (Player, error) {
 			originalDisplay, numericValue, _ := ParseMonetaryValueGo(cellValue)
 			player.TransferValue = originalDisplay
 			player.TransferValueAmount = numericValue
+			player.TransferValueMin, player.TransferValueMax, player.TransferValueEstimate = ParseTransferValueRange(cellValue)
 			isAnAttributeField = false
 		case "Wage":
-			player.Wage, player.WageAmount, _ = ParseMonetaryValueGo(cellValue)
+			player.Wage, player.WageAmount, player.WagePeriod, _ = ParseWageValue(cellValue)
 			isAnAttributeField = false
 		case "Personality":
 			player.Personality = cellValue
@@ -283,24 +311,13 @@ func EnhancePlayerWithCalculations(player *Player) {
 		if isNumericTechnicalAttribute {
 			// Check for masked attributes like "10-15" or completely masked "-"
 			switch {
-			case valStr == "-":
-				// Completely masked attribute
-				player.NumericAttributes[key] = 0 // Default to 0 for completely masked
-				player.AttributeMasked = true
 			case strings.Contains(valStr, "-"):
-				parts := strings.Split(valStr, "-")
-				if len(parts) == 2 {
-					val1, err1 := fastParseInt(strings.TrimSpace(parts[0]))
-					val2, err2 := fastParseInt(strings.TrimSpace(parts[1]))
-
-					if err1 == nil && err2 == nil {
-						player.NumericAttributes[key] = (val1 + val2) / 2
-						player.AttributeMasked = true
-					} else {
-						player.NumericAttributes[key] = 0 // Default on parsing error
-					}
-				} else {
-					player.NumericAttributes[key] = 0 // Default if split is not two parts
+				// Ranges keep their midpoint as the expected value and their bounds for rating bands.
+				// A completely masked "-" stays 0 so calculations skip it.
+				expected, _, _, ok := parseMaskedAttribute(valStr)
+				player.NumericAttributes[key] = expected // 0 on parsing error
+				if ok {
+					player.AttributeMasked = true
 				}
 			default:
 				valInt, err := fastParseInt(valStr)
@@ -371,6 +388,11 @@ func EnhancePlayerWithCalculations(player *Player) {
 		}
 	}
 
+	player.AttributeMin, player.AttributeMax = maskedAttributeBounds(player)
+
+	// Typed height, weight, foot and date fields, derived from the raw columns above
+	parsePlayerDetails(player)
+
 	// Parse positions
 	player.ParsedPositions = ParsePlayerPositionsGo(player.Position)          // from positions.go
 	player.PositionGroups = GetPlayerPositionGroupsGo(player.ParsedPositions) // from positions.go
@@ -643,23 +665,6 @@ func EnhancePlayerWithCalculations(player *Player) {
 	// This is more comprehensive than only checking technical attributes above
 	player.AttributeMasked = false
 
-	// Define FM attribute keys that should be checked for masking
-	fmAttributeKeys := map[string]bool{
-		// Physical
-		"Acc": true, "Pac": true, "Str": true, "Sta": true, "Nat": true, "Bal": true, "Jum": true, "Agi": true,
-		// Mental
-		"Agg": true, "Ant": true, "Bra": true, "Cmp": true, "Cnt": true, "Dec": true, "Det": true, "Fla": true,
-		"Ldr": true, "OtB": true, "Pos": true, "Tea": true, "Vis": true, "Wor": true,
-		// Technical
-		"Cor": true, "Cro": true, "Dri": true, "Fin": true, "Fir": true, "Fre": true, "Hea": true, "Lon": true,
-		"L Th": true, "Mar": true, "Pas": true, "Pen": true, "Tck": true, "Tec": true,
-		// Goalkeeping
-		"Aer": true, "Cmd": true, "Com": true, "Ecc": true, "Han": true, "Kic": true, "1v1": true, "Ref": true,
-		"TRO": true, "Thr": true, "Pun": true,
-		// Other potential FM attributes
-		"Left Foot": true, "Right Foot": true,
-	}
-
 	for key, value := range player.Attributes {
 		// Only check FM attributes, not performance stats
 		if !fmAttributeKeys[key] {
@@ -685,6 +690,8 @@ func EnhancePlayerWithCalculations(player *Player) {
 			}
 		}
 	}
+
+	applyRatingBands(player)
 }
 
 // RecalculatePlayerRatings recalculates all ratings for a player based on the current calculation method setting
@@ -828,23 +835,6 @@ func RecalculatePlayerRatings(player *Player) {
 	// This ensures the flag is updated even during recalculations
 	player.AttributeMasked = false
 
-	// Define FM attribute keys that should be checked for masking
-	fmAttributeKeys := map[string]bool{
-		// Physical
-		"Acc": true, "Pac": true, "Str": true, "Sta": true, "Nat": true, "Bal": true, "Jum": true, "Agi": true,
-		// Mental
-		"Agg": true, "Ant": true, "Bra": true, "Cmp": true, "Cnt": true, "Dec": true, "Det": true, "Fla": true,
-		"Ldr": true, "OtB": true, "Pos": true, "Tea": true, "Vis": true, "Wor": true,
-		// Technical
-		"Cor": true, "Cro": true, "Dri": true, "Fin": true, "Fir": true, "Fre": true, "Hea": true, "Lon": true,
-		"L Th": true, "Mar": true, "Pas": true, "Pen": true, "Tck": true, "Tec": true,
-		// Goalkeeping
-		"Aer": true, "Cmd": true, "Com": true, "Ecc": true, "Han": true, "Kic": true, "1v1": true, "Ref": true,
-		"TRO": true, "Thr": true, "Pun": true,
-		// Other potential FM attributes
-		"Left Foot": true, "Right Foot": true,
-	}
-
 	for key, value := range player.Attributes {
 		// Only check FM attributes, not performance stats
 		if !fmAttributeKeys[key] {
@@ -870,6 +860,8 @@ func RecalculatePlayerRatings(player *Player) {
 			}
 		}
 	}
+
+	applyRatingBands(player)
 }
 
 // RecalculateAllPlayersRatings recalculates ratings for all players in a slice
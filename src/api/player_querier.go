@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	apperrors "api/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// storedPlayerQuerier returns the storage as a PlayerQuerier when a request for the dataset
+// can be answered from the backend's player tables: the backend supports queries, the request
+// uses the whole-dataset percentiles stored at upload, and the dataset is not already held in
+// memory or in the percentile cache, where reading it is cheaper than querying.
+func storedPlayerQuerier(datasetID, divisionFilterStr, targetDivision string) (PlayerQuerier, bool) {
+	querier, ok := storage.(PlayerQuerier)
+	if !ok || parseDivisionFilter(divisionFilterStr) != DivisionFilterAll || isDatasetInMemory(datasetID) {
+		return nil, false
+	}
+	if _, cached := getFromMemCache(percentileCacheKey(datasetID, divisionFilterStr, targetDivision)); cached {
+		return nil, false
+	}
+	return querier, true
+}
+
+// hasStoredPercentiles reports whether the upload's percentile calculation has been stored for
+// a player. Until it has, percentiles are calculated over the loaded dataset instead.
+func hasStoredPercentiles(player *Player) bool {
+	_, found := player.PerformancePercentiles["Global"]
+	return found
+}
+
+// playerListStoredQuery narrows a player list request to the filters the player tables can
+// apply. The list handler still applies every filter to the players returned. Amount filters
+// are only narrowed when no currency conversion is requested, since the tables hold amounts
+// in the dataset's currency.
+func playerListStoredQuery(values url.Values, salaryPeriod WagePeriod) PlayerQuery {
+	var query PlayerQuery
+	if minAge, err := strconv.Atoi(values.Get("minAge")); err == nil && minAge > 0 {
+		query.MinAge = minAge
+	}
+	if maxAge, err := strconv.Atoi(values.Get("maxAge")); err == nil && maxAge > 0 {
+		query.MaxAge = maxAge
+	}
+	if values.Get("currency") != "" {
+		return query
+	}
+	if minValue, err := strconv.ParseInt(values.Get("minTransferValue"), 10, 64); err == nil && minValue > 0 {
+		query.MinTransferValue = minValue
+	}
+	if maxValue, err := strconv.ParseInt(values.Get("maxTransferValue"), 10, 64); err == nil && maxValue > 0 {
+		query.MaxTransferValue = maxValue
+	}
+	if maxSalary, err := strconv.ParseInt(values.Get("maxSalary"), 10, 64); err == nil && maxSalary > 0 {
+		query.MaxWage = ConvertWageAmount(maxSalary, salaryPeriod, WagePeriodWeekly)
+	}
+	return query
+}
+
+// queryStoredPlayers returns the players of a dataset matching query, with ratings
+// recalculated, from the backend's player tables. ok is false when the request must load the
+// dataset instead.
+func queryStoredPlayers(ctx context.Context, querier PlayerQuerier, datasetID string, query PlayerQuery) (players []Player, currencySymbol string, ok bool) {
+	details, found := lookupDatasetDetails(datasetID)
+	if !found {
+		return nil, "", false
+	}
+
+	ctx, span := StartSpan(ctx, "storage.query_players")
+	defer span.End()
+	players, err := querier.QueryPlayers(datasetID, query)
+	if err != nil {
+		logWarn(ctx, "Player query failed, loading the dataset instead", "dataset_id", datasetID, "error", err)
+		return nil, "", false
+	}
+	for i := range players {
+		if !hasStoredPercentiles(&players[i]) {
+			return nil, "", false
+		}
+	}
+	SetSpanAttributes(ctx, attribute.Int("dataset.queried_player_count", len(players)))
+	return RecalculateAllPlayersRatings(players), details.CurrencySymbol, true
+}
+
+// storedPlayerListSort resolves a player list sort for the player tables. Fields that need no
+// dataset to resolve, such as name or age, resolve as usual, and attributes are looked up in
+// the tables and sorted there. ok is false for other fields, which are resolved against the
+// loaded dataset.
+func storedPlayerListSort(ctx context.Context, querier PlayerQuerier, datasetID string, listQuery PlayerListQuery) (sort *playerSort, sortBy string, ok bool) {
+	if sort, err := compilePlayerSort(listQuery, nil); err == nil {
+		return sort, "", true
+	}
+	found, err := querier.HasAttribute(datasetID, listQuery.Sort)
+	if err != nil {
+		logWarn(ctx, "Sort field lookup failed, loading the dataset instead", "dataset_id", datasetID, "sort", listQuery.Sort, "error", err)
+		return nil, "", false
+	}
+	if !found {
+		return nil, "", false
+	}
+	return &playerSort{descending: listQuery.Descending, number: playerAttributeField(listQuery.Sort)}, listQuery.Sort, true
+}
+
+// storedPlayerByUID looks a player up in the backend's player tables, with ratings
+// recalculated. ok is false when the request must load the dataset instead, including when
+// the UID is shared by several players, so the caller can list them. A missing player is ok
+// with an ErrPlayerNotFound error.
+func storedPlayerByUID(ctx context.Context, datasetID, divisionFilterStr, targetDivision string, uid int64) (player Player, currencySymbol string, ok bool, err error) {
+	querier, ok := storedPlayerQuerier(datasetID, divisionFilterStr, targetDivision)
+	if !ok {
+		return Player{}, "", false, nil
+	}
+	details, found := lookupDatasetDetails(datasetID)
+	if !found {
+		return Player{}, "", false, nil
+	}
+
+	player, err = querier.PlayerByUID(datasetID, uid)
+	switch {
+	case errors.Is(err, apperrors.ErrPlayerNotFound):
+		return Player{}, details.CurrencySymbol, true, err
+	case err != nil:
+		if !errors.Is(err, apperrors.ErrAmbiguousPlayer) {
+			logWarn(ctx, "Player lookup failed, loading the dataset instead", "dataset_id", datasetID, "uid", uid, "error", err)
+		}
+		return Player{}, "", false, nil
+	case !hasStoredPercentiles(&player):
+		return Player{}, "", false, nil
+	}
+	RecalculatePlayerRatings(&player)
+	return player, details.CurrencySymbol, true, nil
+}
+
+// playerListPlayers are the players a player list request filters, with the list's sort
+// resolved against the whole dataset.
+type playerListPlayers struct {
+	players        []Player
+	currencySymbol string
+	sort           *playerSort
+	cacheHit       bool // Whether the players came from the percentiles: cache
+}
+
+// loadPlayerListPlayers returns the players a player list request filters. When the backend
+// supports it they are read with the request's filters and attribute sort applied in the
+// player tables, and otherwise as loadPlayersWithPercentiles returns them. Filter expressions
+// are compiled against the whole dataset, so requests carrying one always load it. err is an
+// invalid sort field.
+func loadPlayerListPlayers(ctx context.Context, datasetID, divisionFilterStr, targetDivision string, values url.Values, listQuery PlayerListQuery, salaryPeriod WagePeriod) (list playerListPlayers, found bool, err error) {
+	if querier, ok := storedPlayerQuerier(datasetID, divisionFilterStr, targetDivision); ok && values.Get("filter") == "" {
+		if sort, sortBy, ok := storedPlayerListSort(ctx, querier, datasetID, listQuery); ok {
+			query := playerListStoredQuery(values, salaryPeriod)
+			query.SortBy, query.Descending = sortBy, listQuery.Descending
+			if players, currencySymbol, ok := queryStoredPlayers(ctx, querier, datasetID, query); ok {
+				return playerListPlayers{players: players, currencySymbol: currencySymbol, sort: sort}, true, nil
+			}
+		}
+	}
+
+	players, currencySymbol, cacheHit, found := loadPlayersWithPercentiles(ctx, datasetID, divisionFilterStr, targetDivision)
+	if !found {
+		return playerListPlayers{}, false, nil
+	}
+	sort, err := compilePlayerSort(listQuery, players)
+	if err != nil {
+		return playerListPlayers{}, true, err
+	}
+	return playerListPlayers{players: players, currencySymbol: currencySymbol, sort: sort, cacheHit: cacheHit}, true, nil
+}
+
+// storedPercentileBatch answers a batch percentiles request with the percentiles stored at
+// upload, reading the requested players in one query. None are calculated, so every player
+// found counts as cached. ok is false when the request must load the dataset instead.
+func storedPercentileBatch(ctx context.Context, datasetID string, req PercentileBatchRequest) (response PercentileBatchResponse, ok bool) {
+	querier, ok := storedPlayerQuerier(datasetID, req.DivisionFilter, req.TargetDivision)
+	if !ok {
+		return PercentileBatchResponse{}, false
+	}
+	if _, found := lookupDatasetDetails(datasetID); !found {
+		return PercentileBatchResponse{}, false
+	}
+
+	uids := make([]int64, 0, len(req.PlayerUIDs))
+	seen := make(map[int64]bool, len(req.PlayerUIDs))
+	for _, uid := range req.PlayerUIDs {
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+	players, err := querier.QueryPlayers(datasetID, PlayerQuery{UIDs: uids})
+	if err != nil {
+		logWarn(ctx, "Player query failed, loading the dataset instead", "dataset_id", datasetID, "error", err)
+		return PercentileBatchResponse{}, false
+	}
+
+	matches := make(map[int64][]*Player, len(players))
+	for i := range players {
+		if !hasStoredPercentiles(&players[i]) {
+			return PercentileBatchResponse{}, false
+		}
+		matches[players[i].UID] = append(matches[players[i].UID], &players[i])
+	}
+
+	response = PercentileBatchResponse{Percentiles: make(map[int64]map[string]map[string]float64, len(uids))}
+	for _, uid := range uids {
+		switch len(matches[uid]) {
+		case 0:
+			response.NotFound = append(response.NotFound, uid)
+		case 1:
+			response.Percentiles[uid] = matches[uid][0].PerformancePercentiles
+		default:
+			response.Ambiguous = append(response.Ambiguous, uid)
+		}
+	}
+	response.Cached = len(response.Percentiles)
+	return response, true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// datasetReadCountingSQLStorage counts full reads of one dataset from SQL storage.
+type datasetReadCountingSQLStorage struct {
+	*SQLStorage
+	datasetID    string
+	datasetReads int
+}
+
+func (s *datasetReadCountingSQLStorage) Retrieve(datasetID string) (DatasetData, error) {
+	if datasetID == s.datasetID {
+		s.datasetReads++
+	}
+	return s.SQLStorage.Retrieve(datasetID)
+}
+
+func TestPlayerRequestsQueryStoredPlayers(t *testing.T) {
+	InitStore()
+	originalStorage := storage
+	defer func() { storage = originalStorage }()
+	datasetID := "player-querier-test"
+	counting := &datasetReadCountingSQLStorage{SQLStorage: createTestSQLStorage(t), datasetID: datasetID}
+	storage = counting
+
+	players := sqlTestPlayers()
+	for i := range players {
+		players[i].PerformancePercentiles = map[string]map[string]float64{"Global": {"Pace": float64(10 * (i + 1))}}
+	}
+	players = append(players, Player{UID: 40, Name: "Namesake", Club: "East FC", Age: "27",
+		PerformancePercentiles: map[string]map[string]float64{"Global": {"Pace": 5}}})
+	players = append(players, Player{UID: 40, Name: "Namesake", Club: "West FC", Age: "29",
+		PerformancePercentiles: map[string]map[string]float64{"Global": {"Pace": 6}}})
+	if err := storeDatasetData(datasetID, DatasetData{Players: players, CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	t.Cleanup(func() { _ = DeleteDataset(datasetID) })
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		playerDataHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/players/" + datasetID + "?minAge=24&maxTransferValue=3000000&sort=name")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for the player list, got %d: %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		Players []Player `json:"players"`
+		Total   int      `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode player list: %v", err)
+	}
+	if list.Total != 3 || list.Players[0].Name != "Keeper" || list.Players[0].PerformancePercentiles["Global"]["Pace"] != 10 {
+		t.Errorf("Unexpected player list: %+v", list)
+	}
+
+	// Attribute sorts are resolved and ordered in the player tables, even when the filters keep
+	// no player with the attribute
+	for query, want := range map[string][]string{
+		"?sort=Ref:desc&limit=2": {"Keeper", "Winger"},
+		"?maxAge=20&sort=Ref":    {"Winger"},
+	} {
+		w := get("/api/players/" + datasetID + query)
+		list.Players = nil
+		if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("%s: expected a player list, got %d: %s", query, w.Code, w.Body.String())
+		}
+		got := make([]string, len(list.Players))
+		for i := range list.Players {
+			got[i] = list.Players[i].Name
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", query, got, want)
+		}
+	}
+
+	w = get("/api/players/" + datasetID + "/30")
+	var detail PlayerDetailResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected the player detail, got %d: %s", w.Code, w.Body.String())
+	}
+	if detail.Player.Name != "Striker" || detail.CurrencySymbol != "£" || detail.Player.PerformancePercentiles["Global"]["Pace"] != 30 {
+		t.Errorf("Unexpected player detail: %+v", detail)
+	}
+	if w := get("/api/players/" + datasetID + "/99"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing player, got %d", w.Code)
+	}
+
+	encoded, _ := json.Marshal(PercentileBatchRequest{PlayerUIDs: []int64{30, 99, 40, 10, 30}})
+	w = httptest.NewRecorder()
+	percentilesBatchHandler(w, httptest.NewRequest(http.MethodPost, "/api/percentiles-batch/"+datasetID, bytes.NewReader(encoded)))
+	var batch PercentileBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &batch); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected batch percentiles, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(batch.Percentiles) != 2 || batch.Percentiles[30]["Global"]["Pace"] != 30 ||
+		!reflect.DeepEqual(batch.NotFound, []int64{99}) || !reflect.DeepEqual(batch.Ambiguous, []int64{40}) || batch.Cached != 2 {
+		t.Errorf("Unexpected batch percentiles: %+v", batch)
+	}
+
+	if counting.datasetReads != 0 {
+		t.Errorf("Player requests loaded the dataset %d times, expected queries only", counting.datasetReads)
+	}
+
+	// Unknown sort fields are reported from the loaded dataset
+	if w := get("/api/players/" + datasetID + "?sort=Fni"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown sort field, got %d", w.Code)
+	}
+
+	// Shared UIDs load the dataset so the candidates can be listed
+	if w := get("/api/players/" + datasetID + "/40"); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a shared UID, got %d: %s", w.Code, w.Body.String())
+	}
+}
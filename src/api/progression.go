@@ -0,0 +1,455 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "api/errors"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Result sizes of the progression endpoint.
+const (
+	defaultProgressionLimit = 100
+	maxProgressionLimit     = 5000
+)
+
+// progressionMetricOverall and progressionMetricTransferValue are the metrics the progression
+// endpoint sorts by besides FIFA categories, attributes and roles.
+const (
+	progressionMetricOverall       = "Overall"
+	progressionMetricTransferValue = "transferValue"
+)
+
+// fifaStatNames lists the FIFA-style categories compared between snapshots.
+var fifaStatNames = []string{"PAC", "SHO", "PAS", "DRI", "DEF", "PHY", "GK", "DIV", "HAN", "REF", "KIC", "SPD", "POS"}
+
+// PlayerProgression is the change of one player between two datasets. Delta maps only hold
+// values that changed and are present in both datasets.
+type PlayerProgression struct {
+	UID                int64          `json:"uid"`
+	Name               string         `json:"name"`
+	Position           string         `json:"position"`
+	Age                string         `json:"age"`
+	PositionGroups     []string       `json:"positionGroups"`
+	FromClub           string         `json:"fromClub"`
+	ToClub             string         `json:"toClub"`
+	ClubChanged        bool           `json:"clubChanged"`
+	OverallFrom        int            `json:"overallFrom"`
+	OverallTo          int            `json:"overallTo"`
+	OverallDelta       int            `json:"overallDelta"`
+	TransferValueFrom  int64          `json:"transferValueFrom"`
+	TransferValueTo    int64          `json:"transferValueTo"`
+	TransferValueDelta int64          `json:"transferValueDelta"`
+	AttributeDeltas    map[string]int `json:"attributeDeltas"`
+	StatDeltas         map[string]int `json:"statDeltas"`
+	RoleDeltas         map[string]int `json:"roleDeltas"`
+	SortDelta          int64          `json:"sortDelta"` // Delta of the requested sort metric
+}
+
+// ProgressionResponse compares the players of two datasets joined by UID.
+type ProgressionResponse struct {
+	FromDatasetID  string              `json:"fromDatasetId"`
+	ToDatasetID    string              `json:"toDatasetId"`
+	CurrencySymbol string              `json:"currencySymbol"`
+	Metric         string              `json:"metric"`
+	Order          string              `json:"order"` // "risers" or "fallers"
+	MatchedPlayers int                 `json:"matchedPlayers"`
+	OnlyInFrom     int                 `json:"onlyInFrom"` // Players that left the exported pool
+	OnlyInTo       int                 `json:"onlyInTo"`   // Players that joined the exported pool
+	Players        []PlayerProgression `json:"players"`
+}
+
+// progressionFilter narrows the players a progression response returns.
+type progressionFilter struct {
+	PositionGroup string
+	MaxAge        int // -1 when unset
+}
+
+func (f progressionFilter) matches(player *Player) bool {
+	if f.PositionGroup != "" {
+		inGroup := false
+		for _, group := range player.PositionGroups {
+			if strings.EqualFold(group, f.PositionGroup) {
+				inGroup = true
+				break
+			}
+		}
+		if !inGroup {
+			return false
+		}
+	}
+	if f.MaxAge != -1 {
+		age, err := strconv.Atoi(strings.TrimSpace(player.Age))
+		if err != nil || age > f.MaxAge {
+			return false
+		}
+	}
+	return true
+}
+
+// fifaStatValue returns a FIFA-style category of a player by name.
+func fifaStatValue(player *Player, stat string) int {
+	switch stat {
+	case "PAC":
+		return player.PAC
+	case "SHO":
+		return player.SHO
+	case "PAS":
+		return player.PAS
+	case "DRI":
+		return player.DRI
+	case "DEF":
+		return player.DEF
+	case "PHY":
+		return player.PHY
+	case "GK":
+		return player.GK
+	case "DIV":
+		return player.DIV
+	case "HAN":
+		return player.HAN
+	case "REF":
+		return player.REF
+	case "KIC":
+		return player.KIC
+	case "SPD":
+		return player.SPD
+	case "POS":
+		return player.POS
+	}
+	return 0
+}
+
+// comparePlayerProgression computes the deltas of a player between two datasets.
+func comparePlayerProgression(from, to *Player) PlayerProgression {
+	progression := PlayerProgression{
+		UID:               to.UID,
+		Name:              to.Name,
+		Position:          to.Position,
+		Age:               to.Age,
+		PositionGroups:    to.PositionGroups,
+		FromClub:          from.Club,
+		ToClub:            to.Club,
+		ClubChanged:       from.Club != to.Club,
+		OverallFrom:       from.Overall,
+		OverallTo:         to.Overall,
+		OverallDelta:      to.Overall - from.Overall,
+		TransferValueFrom: playerTransferValueEstimate(from),
+		TransferValueTo:   playerTransferValueEstimate(to),
+		AttributeDeltas:   make(map[string]int),
+		StatDeltas:        make(map[string]int),
+		RoleDeltas:        make(map[string]int),
+	}
+	progression.TransferValueDelta = progression.TransferValueTo - progression.TransferValueFrom
+
+	for attr, toValue := range to.NumericAttributes {
+		if fromValue, ok := from.NumericAttributes[attr]; ok && toValue != fromValue {
+			progression.AttributeDeltas[attr] = toValue - fromValue
+		}
+	}
+
+	// Goalkeepers and outfield players carry different categories; the others are zero
+	for _, stat := range fifaStatNames {
+		fromValue, toValue := fifaStatValue(from, stat), fifaStatValue(to, stat)
+		if fromValue != 0 && toValue != 0 && toValue != fromValue {
+			progression.StatDeltas[stat] = toValue - fromValue
+		}
+	}
+
+	fromRoles := make(map[string]int, len(from.RoleSpecificOveralls))
+	for _, role := range from.RoleSpecificOveralls {
+		fromRoles[role.RoleName] = role.Score
+	}
+	for _, role := range to.RoleSpecificOveralls {
+		if fromScore, ok := fromRoles[role.RoleName]; ok && role.Score != fromScore {
+			progression.RoleDeltas[role.RoleName] = role.Score - fromScore
+		}
+	}
+
+	return progression
+}
+
+// progressionMetricDelta returns the delta a progression is sorted by. Metrics are Overall,
+// transferValue, a FIFA category, an attribute or a role name, tried in that order.
+func progressionMetricDelta(progression *PlayerProgression, metric string) int64 {
+	switch metric {
+	case progressionMetricOverall:
+		return int64(progression.OverallDelta)
+	case progressionMetricTransferValue:
+		return progression.TransferValueDelta
+	}
+	if delta, ok := progression.StatDeltas[metric]; ok {
+		return int64(delta)
+	}
+	if delta, ok := progression.AttributeDeltas[metric]; ok {
+		return int64(delta)
+	}
+	return int64(progression.RoleDeltas[metric])
+}
+
+// validateProgressionMetric checks that metric names Overall, transferValue, a FIFA category,
+// or an attribute or role held by a player of either dataset. Names are case-sensitive, as
+// filter expressions resolve them.
+func validateProgressionMetric(metric string, pair snapshotPair) error {
+	if metric == progressionMetricOverall || metric == progressionMetricTransferValue || slices.Contains(fifaStatNames, metric) {
+		return nil
+	}
+	for _, players := range [][]Player{pair.FromPlayers, pair.ToPlayers} {
+		for i := range players {
+			if _, found := players[i].NumericAttributes[metric]; found {
+				return nil
+			}
+			if playerRoleIndex(&players[i], metric) >= 0 {
+				return nil
+			}
+		}
+	}
+	return apperrors.WrapErrUnknownProgressionMetric(metric)
+}
+
+// snapshotPair holds the players of two datasets being compared, typically two snapshots of
+// the same save.
+type snapshotPair struct {
+	FromPlayers    []Player
+	FromSymbol     string
+	FromCurrency   string // ISO code of the from dataset's amounts; derived from FromSymbol when empty
+	ToPlayers      []Player
+	ToSymbol       string
+	ToCurrency     string // ISO code of the to dataset's amounts; derived from ToSymbol when empty
+	CurrencySymbol string // Common symbol once inCurrency has run
+}
+
+// inCurrency converts both sides to currency, or to the first dataset's currency when it is
+// empty, so values are compared like for like.
+func (p snapshotPair) inCurrency(currency string) (snapshotPair, error) {
+	fromCurrency, toCurrency := p.FromCurrency, p.ToCurrency
+	if fromCurrency == "" {
+		fromCurrency = currencyCodeForSymbol(p.FromSymbol)
+	}
+	if toCurrency == "" {
+		toCurrency = currencyCodeForSymbol(p.ToSymbol)
+	}
+	if strings.TrimSpace(currency) == "" && (toCurrency != fromCurrency || p.ToSymbol != p.FromSymbol) {
+		currency = fromCurrency
+	}
+	fromPlayers, currencySymbol, err := convertPlayersForRequest(p.FromPlayers, p.FromSymbol, fromCurrency, currency)
+	if err != nil {
+		return snapshotPair{}, err
+	}
+	toPlayers, _, err := convertPlayersForRequest(p.ToPlayers, p.ToSymbol, toCurrency, currency)
+	if err != nil {
+		return snapshotPair{}, err
+	}
+	if strings.TrimSpace(currency) != "" {
+		fromCurrency, _ = resolveCurrencyCode(currency)
+		toCurrency = fromCurrency
+	}
+	return snapshotPair{
+		FromPlayers:    fromPlayers,
+		FromSymbol:     currencySymbol,
+		FromCurrency:   fromCurrency,
+		ToPlayers:      toPlayers,
+		ToSymbol:       currencySymbol,
+		ToCurrency:     toCurrency,
+		CurrencySymbol: currencySymbol,
+	}, nil
+}
+
+// playersByUID indexes players by UID. Players without a UID cannot be matched across exports
+// and are left out.
+func playersByUID(players []Player) map[int64]*Player {
+	byUID := make(map[int64]*Player, len(players))
+	for i := range players {
+		if players[i].UID != 0 {
+			byUID[players[i].UID] = &players[i]
+		}
+	}
+	return byUID
+}
+
+// parseSnapshotPairPath reads the two dataset IDs of /api/{route}/{fromDatasetId}/{toDatasetId},
+// writing an error response when they are missing or invalid.
+func parseSnapshotPairPath(w http.ResponseWriter, r *http.Request, route string) (string, string, bool) {
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, route), "/"), "/")
+	if len(pathParts) != 2 {
+		http.Error(w, "Expected "+route+"/{fromDatasetId}/{toDatasetId}", http.StatusBadRequest)
+		return "", "", false
+	}
+	for _, id := range pathParts {
+		if err := validateID(id, maxDatasetIDLength); err != nil || isReservedDatasetID(id) {
+			http.Error(w, "Invalid dataset ID", http.StatusBadRequest)
+			return "", "", false
+		}
+	}
+	return pathParts[0], pathParts[1], true
+}
+
+// loadSnapshotPair loads the players of both datasets, writing a 404 when either is missing.
+func loadSnapshotPair(w http.ResponseWriter, fromID, toID string) (snapshotPair, bool) {
+	fromPlayers, fromSymbol, found := GetPlayerData(fromID)
+	if !found {
+		http.Error(w, "Dataset not found: "+fromID, http.StatusNotFound)
+		return snapshotPair{}, false
+	}
+	toPlayers, toSymbol, found := GetPlayerData(toID)
+	if !found {
+		http.Error(w, "Dataset not found: "+toID, http.StatusNotFound)
+		return snapshotPair{}, false
+	}
+	return snapshotPair{
+		FromPlayers:  fromPlayers,
+		FromSymbol:   fromSymbol,
+		FromCurrency: datasetBaseCurrency(fromID, fromSymbol),
+		ToPlayers:    toPlayers,
+		ToSymbol:     toSymbol,
+		ToCurrency:   datasetBaseCurrency(toID, toSymbol),
+	}, true
+}
+
+// compareDatasetProgression joins the players of two datasets by UID and returns their
+// deltas sorted by metric, biggest risers first or, with fallers set, biggest fallers first.
+func compareDatasetProgression(pair snapshotPair, currency, metric string, fallers bool, filter progressionFilter) (ProgressionResponse, error) {
+	if err := validateProgressionMetric(metric, pair); err != nil {
+		return ProgressionResponse{}, err
+	}
+	pair, err := pair.inCurrency(currency)
+	if err != nil {
+		return ProgressionResponse{}, err
+	}
+
+	// Ratings depend on the current calculation method, so both sides are recalculated with it
+	fromPlayers := RecalculateAllPlayersRatings(pair.FromPlayers)
+	toPlayers := RecalculateAllPlayersRatings(pair.ToPlayers)
+	fromByUID := playersByUID(fromPlayers)
+
+	response := ProgressionResponse{
+		CurrencySymbol: pair.CurrencySymbol,
+		Metric:         metric,
+		Order:          "risers",
+		Players:        make([]PlayerProgression, 0),
+	}
+	if fallers {
+		response.Order = "fallers"
+	}
+
+	matched := make(map[int64]bool, len(fromByUID))
+	for i := range toPlayers {
+		to := &toPlayers[i]
+		from, found := fromByUID[to.UID]
+		if to.UID == 0 || !found {
+			response.OnlyInTo++
+			continue
+		}
+		matched[to.UID] = true
+		if !filter.matches(to) {
+			continue
+		}
+		progression := comparePlayerProgression(from, to)
+		progression.SortDelta = progressionMetricDelta(&progression, metric)
+		response.Players = append(response.Players, progression)
+	}
+	response.MatchedPlayers = len(matched)
+	response.OnlyInFrom = len(fromPlayers) - len(matched)
+
+	sort.SliceStable(response.Players, func(i, j int) bool {
+		a, b := response.Players[i].SortDelta, response.Players[j].SortDelta
+		if a != b {
+			if fallers {
+				return a < b
+			}
+			return a > b
+		}
+		return response.Players[i].UID < response.Players[j].UID
+	})
+	return response, nil
+}
+
+// progressionHandler compares two datasets, typically two snapshots of the same save:
+//
+//	GET /api/progression/{fromDatasetId}/{toDatasetId}
+//
+// Query parameters: metric (Overall, transferValue, a FIFA category, an attribute or a role
+// name held by a player of either dataset; default Overall; unknown metrics are rejected),
+// order (risers or fallers), positionGroup, maxAge, limit and currency.
+func progressionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.progression")
+	defer span.End()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fromID, toID, ok := parseSnapshotPairPath(w, r, "/api/progression")
+	if !ok {
+		return
+	}
+	SetSpanAttributes(ctx,
+		attribute.String("dataset.from_id", fromID),
+		attribute.String("dataset.to_id", toID),
+	)
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	switch {
+	case metric == "" || strings.EqualFold(metric, progressionMetricOverall):
+		metric = progressionMetricOverall
+	case strings.EqualFold(metric, progressionMetricTransferValue):
+		metric = progressionMetricTransferValue
+	}
+	var fallers bool
+	switch query.Get("order") {
+	case "", "risers":
+	case "fallers":
+		fallers = true
+	default:
+		http.Error(w, "Invalid order: must be risers or fallers", http.StatusBadRequest)
+		return
+	}
+	limit := defaultProgressionLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid limit: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxProgressionLimit)
+	}
+	filter := progressionFilter{PositionGroup: query.Get("positionGroup"), MaxAge: -1}
+	if maxAgeStr := query.Get("maxAge"); maxAgeStr != "" {
+		maxAge, err := strconv.Atoi(maxAgeStr)
+		if err != nil || maxAge < 0 {
+			http.Error(w, "Invalid maxAge: must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.MaxAge = maxAge
+	}
+
+	pair, ok := loadSnapshotPair(w, fromID, toID)
+	if !ok {
+		return
+	}
+
+	response, err := compareDatasetProgression(pair, query.Get("currency"), metric, fallers, filter)
+	if err != nil {
+		logWarn(ctx, "Progression request rejected", "from_dataset_id", fromID, "to_dataset_id", toID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response.FromDatasetID, response.ToDatasetID = fromID, toID
+	if len(response.Players) > limit {
+		response.Players = response.Players[:limit]
+	}
+
+	SetSpanAttributes(ctx,
+		attribute.Int("progression.matched", response.MatchedPlayers),
+		attribute.Int("progression.returned", len(response.Players)),
+	)
+	writeDatasetsResponse(ctx, w, r, response)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+func progressionTestPlayer(uid int64, name, club, age string, groups []string, pace, finishing int, value int64) Player {
+	return Player{
+		UID:                 uid,
+		Name:                name,
+		Club:                club,
+		Age:                 age,
+		PositionGroups:      groups,
+		TransferValueAmount: value,
+		NumericAttributes:   map[string]int{"Acc": pace, "Pac": pace, "Fin": finishing, "Dri": 10, "Pas": 10},
+	}
+}
+
+func TestCompareDatasetProgression(t *testing.T) {
+	from := []Player{
+		progressionTestPlayer(1, "Riser", "Youth FC", "18", []string{"Attackers"}, 10, 8, 100000),
+		progressionTestPlayer(2, "Faller", "Old FC", "33", []string{"Attackers"}, 16, 15, 900000),
+		progressionTestPlayer(3, "Defender", "Youth FC", "19", []string{"Defenders"}, 12, 4, 200000),
+		progressionTestPlayer(4, "Released", "Youth FC", "20", []string{"Midfielders"}, 10, 10, 50000),
+	}
+	to := []Player{
+		progressionTestPlayer(1, "Riser", "Big FC", "19", []string{"Attackers"}, 13, 12, 2500000),
+		progressionTestPlayer(2, "Faller", "Old FC", "34", []string{"Attackers"}, 12, 14, 400000),
+		progressionTestPlayer(3, "Defender", "Youth FC", "20", []string{"Defenders"}, 12, 5, 200000),
+		progressionTestPlayer(5, "Newgen", "Youth FC", "16", []string{"Attackers"}, 9, 9, 10000),
+	}
+
+	pair := snapshotPair{FromPlayers: from, FromSymbol: "£", ToPlayers: to, ToSymbol: "£"}
+	response, err := compareDatasetProgression(pair, "", "Fin", false, progressionFilter{MaxAge: -1})
+	if err != nil {
+		t.Fatalf("compareDatasetProgression failed: %v", err)
+	}
+	if response.MatchedPlayers != 3 || response.OnlyInFrom != 1 || response.OnlyInTo != 1 {
+		t.Errorf("Unexpected join counts: matched %d, only in from %d, only in to %d",
+			response.MatchedPlayers, response.OnlyInFrom, response.OnlyInTo)
+	}
+	if len(response.Players) != 3 || response.Players[0].UID != 1 || response.Players[2].UID != 2 {
+		t.Fatalf("Expected risers ordered by finishing delta, got %+v", response.Players)
+	}
+	riser := response.Players[0]
+	if riser.AttributeDeltas["Fin"] != 4 || riser.AttributeDeltas["Pac"] != 3 || riser.SortDelta != 4 {
+		t.Errorf("Unexpected attribute deltas: %+v", riser.AttributeDeltas)
+	}
+	if _, unchanged := riser.AttributeDeltas["Dri"]; unchanged {
+		t.Error("Unchanged attributes should be left out of the deltas")
+	}
+	if !riser.ClubChanged || riser.FromClub != "Youth FC" || riser.ToClub != "Big FC" || riser.TransferValueDelta != 2400000 {
+		t.Errorf("Unexpected club or value change: %+v", riser)
+	}
+	if riser.StatDeltas["PAC"] <= 0 {
+		t.Errorf("Expected a PAC rise, got %+v", riser.StatDeltas)
+	}
+
+	response, _ = compareDatasetProgression(pair, "", progressionMetricTransferValue, true,
+		progressionFilter{PositionGroup: "attackers", MaxAge: 21})
+	if len(response.Players) != 1 || response.Players[0].UID != 1 || response.Order != "fallers" {
+		t.Errorf("Expected only the young attacker, got %+v", response.Players)
+	}
+
+	response, _ = compareDatasetProgression(pair, "", progressionMetricTransferValue, true, progressionFilter{MaxAge: -1})
+	if response.Players[0].UID != 2 {
+		t.Errorf("Expected the biggest value faller first, got %+v", response.Players[0])
+	}
+
+	if _, err := compareDatasetProgression(pair, "", "Pace", false, progressionFilter{MaxAge: -1}); !errors.Is(err, apperrors.ErrUnknownProgressionMetric) {
+		t.Errorf("Expected an unknown metric to be rejected, got %v", err)
+	}
+}
+
+func TestProgressionHandler(t *testing.T) {
+	InitStore()
+
+	for id, players := range map[string][]Player{
+		"progression-test-jan": {progressionTestPlayer(7, "Prospect", "Youth FC", "17", []string{"Midfielders"}, 9, 9, 100000)},
+		"progression-test-jun": {progressionTestPlayer(7, "Prospect", "Youth FC", "17", []string{"Midfielders"}, 11, 12, 300000)},
+	} {
+		if err := storeDatasetData(id, DatasetData{Players: players, CurrencySymbol: "£"}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		t.Cleanup(func() { _ = DeleteDataset(id) })
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/progression/progression-test-jan/progression-test-jun?metric=Fin&limit=10", nil)
+	w := httptest.NewRecorder()
+	progressionHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response ProgressionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse progression: %v", err)
+	}
+	if response.FromDatasetID != "progression-test-jan" || len(response.Players) != 1 || response.Players[0].SortDelta != 3 {
+		t.Errorf("Unexpected progression: %+v", response)
+	}
+
+	for target, want := range map[string]int{
+		"/api/progression/progression-test-jan/missing-dataset":                           http.StatusNotFound,
+		"/api/progression/progression-test-jan":                                           http.StatusBadRequest,
+		"/api/progression/progression-test-jan/progression-test-jun?order=up":             http.StatusBadRequest,
+		"/api/progression/progression-test-jan/progression-test-jun?metric=Finishing":     http.StatusBadRequest,
+		"/api/progression/progression-test-jan/progression-test-jun?metric=fin":           http.StatusBadRequest,
+		"/api/progression/progression-test-jan/progression-test-jun?metric=PAC":           http.StatusOK,
+		"/api/progression/progression-test-jan/progression-test-jun?metric=transfervalue": http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		progressionHandler(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", target, want, w.Code)
+		}
+	}
+}
@@ -26,6 +26,8 @@ type RoleOverallScore struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RoleName      string                 `protobuf:"bytes,1,opt,name=role_name,json=roleName,proto3" json:"role_name,omitempty"`
 	Score         int32                  `protobuf:"varint,2,opt,name=score,proto3" json:"score,omitempty"`
+	MinScore      int32                  `protobuf:"varint,3,opt,name=min_score,json=minScore,proto3" json:"min_score,omitempty"`
+	MaxScore      int32                  `protobuf:"varint,4,opt,name=max_score,json=maxScore,proto3" json:"max_score,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,6 +76,20 @@ func (x *RoleOverallScore) GetScore() int32 {
 	return 0
 }
 
+func (x *RoleOverallScore) GetMinScore() int32 {
+	if x != nil {
+		return x.MinScore
+	}
+	return 0
+}
+
+func (x *RoleOverallScore) GetMaxScore() int32 {
+	if x != nil {
+		return x.MaxScore
+	}
+	return 0
+}
+
 // PerformancePercentileMap represents nested map structure for performance percentiles
 type PerformancePercentileMap struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -161,6 +177,25 @@ type Player struct {
 	RoleSpecificOveralls    []*RoleOverallScore                  `protobuf:"bytes,37,rep,name=role_specific_overalls,json=roleSpecificOveralls,proto3" json:"role_specific_overalls,omitempty"`
 	TransferValueAmount     int64                                `protobuf:"varint,38,opt,name=transfer_value_amount,json=transferValueAmount,proto3" json:"transfer_value_amount,omitempty"`
 	WageAmount              int64                                `protobuf:"varint,39,opt,name=wage_amount,json=wageAmount,proto3" json:"wage_amount,omitempty"`
+	AgeYears                int32                                `protobuf:"varint,40,opt,name=age_years,json=ageYears,proto3" json:"age_years,omitempty"`
+	HeightCm                int32                                `protobuf:"varint,41,opt,name=height_cm,json=heightCm,proto3" json:"height_cm,omitempty"`
+	WeightKg                int32                                `protobuf:"varint,42,opt,name=weight_kg,json=weightKg,proto3" json:"weight_kg,omitempty"`
+	PreferredFoot           string                               `protobuf:"bytes,43,opt,name=preferred_foot,json=preferredFoot,proto3" json:"preferred_foot,omitempty"`
+	LeftFoot                int32                                `protobuf:"varint,44,opt,name=left_foot,json=leftFoot,proto3" json:"left_foot,omitempty"`
+	RightFoot               int32                                `protobuf:"varint,45,opt,name=right_foot,json=rightFoot,proto3" json:"right_foot,omitempty"`
+	ContractExpiry          string                               `protobuf:"bytes,46,opt,name=contract_expiry,json=contractExpiry,proto3" json:"contract_expiry,omitempty"`
+	DateOfBirth             string                               `protobuf:"bytes,47,opt,name=date_of_birth,json=dateOfBirth,proto3" json:"date_of_birth,omitempty"`
+	WagePeriod              string                               `protobuf:"bytes,48,opt,name=wage_period,json=wagePeriod,proto3" json:"wage_period,omitempty"`
+	TransferValueMin        int64                                `protobuf:"varint,49,opt,name=transfer_value_min,json=transferValueMin,proto3" json:"transfer_value_min,omitempty"`
+	TransferValueMax        int64                                `protobuf:"varint,50,opt,name=transfer_value_max,json=transferValueMax,proto3" json:"transfer_value_max,omitempty"`
+	TransferValueEstimate   int64                                `protobuf:"varint,51,opt,name=transfer_value_estimate,json=transferValueEstimate,proto3" json:"transfer_value_estimate,omitempty"`
+	AttributeMin            map[string]int32                     `protobuf:"bytes,52,rep,name=attribute_min,json=attributeMin,proto3" json:"attribute_min,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	AttributeMax            map[string]int32                     `protobuf:"bytes,53,rep,name=attribute_max,json=attributeMax,proto3" json:"attribute_max,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	OverallMin              int32                                `protobuf:"varint,54,opt,name=overall_min,json=overallMin,proto3" json:"overall_min,omitempty"`
+	OverallMax              int32                                `protobuf:"varint,55,opt,name=overall_max,json=overallMax,proto3" json:"overall_max,omitempty"`
+	StatMin                 map[string]int32                     `protobuf:"bytes,56,rep,name=stat_min,json=statMin,proto3" json:"stat_min,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	StatMax                 map[string]int32                     `protobuf:"bytes,57,rep,name=stat_max,json=statMax,proto3" json:"stat_max,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	RatingConfidence        string                               `protobuf:"bytes,58,opt,name=rating_confidence,json=ratingConfidence,proto3" json:"rating_confidence,omitempty"`
 	unknownFields           protoimpl.UnknownFields
 	sizeCache               protoimpl.SizeCache
 }
@@ -468,12 +503,148 @@ func (x *Player) GetWageAmount() int64 {
 	return 0
 }
 
+func (x *Player) GetAgeYears() int32 {
+	if x != nil {
+		return x.AgeYears
+	}
+	return 0
+}
+
+func (x *Player) GetHeightCm() int32 {
+	if x != nil {
+		return x.HeightCm
+	}
+	return 0
+}
+
+func (x *Player) GetWeightKg() int32 {
+	if x != nil {
+		return x.WeightKg
+	}
+	return 0
+}
+
+func (x *Player) GetPreferredFoot() string {
+	if x != nil {
+		return x.PreferredFoot
+	}
+	return ""
+}
+
+func (x *Player) GetLeftFoot() int32 {
+	if x != nil {
+		return x.LeftFoot
+	}
+	return 0
+}
+
+func (x *Player) GetRightFoot() int32 {
+	if x != nil {
+		return x.RightFoot
+	}
+	return 0
+}
+
+func (x *Player) GetContractExpiry() string {
+	if x != nil {
+		return x.ContractExpiry
+	}
+	return ""
+}
+
+func (x *Player) GetDateOfBirth() string {
+	if x != nil {
+		return x.DateOfBirth
+	}
+	return ""
+}
+
+func (x *Player) GetWagePeriod() string {
+	if x != nil {
+		return x.WagePeriod
+	}
+	return ""
+}
+
+func (x *Player) GetTransferValueMin() int64 {
+	if x != nil {
+		return x.TransferValueMin
+	}
+	return 0
+}
+
+func (x *Player) GetTransferValueMax() int64 {
+	if x != nil {
+		return x.TransferValueMax
+	}
+	return 0
+}
+
+func (x *Player) GetTransferValueEstimate() int64 {
+	if x != nil {
+		return x.TransferValueEstimate
+	}
+	return 0
+}
+
+func (x *Player) GetAttributeMin() map[string]int32 {
+	if x != nil {
+		return x.AttributeMin
+	}
+	return nil
+}
+
+func (x *Player) GetAttributeMax() map[string]int32 {
+	if x != nil {
+		return x.AttributeMax
+	}
+	return nil
+}
+
+func (x *Player) GetOverallMin() int32 {
+	if x != nil {
+		return x.OverallMin
+	}
+	return 0
+}
+
+func (x *Player) GetOverallMax() int32 {
+	if x != nil {
+		return x.OverallMax
+	}
+	return 0
+}
+
+func (x *Player) GetStatMin() map[string]int32 {
+	if x != nil {
+		return x.StatMin
+	}
+	return nil
+}
+
+func (x *Player) GetStatMax() map[string]int32 {
+	if x != nil {
+		return x.StatMax
+	}
+	return nil
+}
+
+func (x *Player) GetRatingConfidence() string {
+	if x != nil {
+		return x.RatingConfidence
+	}
+	return ""
+}
+
 // DatasetData represents a dataset containing player information
 type DatasetData struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Players        []*Player              `protobuf:"bytes,1,rep,name=players,proto3" json:"players,omitempty"`
 	CurrencySymbol string                 `protobuf:"bytes,2,opt,name=currency_symbol,json=currencySymbol,proto3" json:"currency_symbol,omitempty"`
 	CacheData      string                 `protobuf:"bytes,3,opt,name=cache_data,json=cacheData,proto3" json:"cache_data,omitempty"`
+	BaseCurrency   string                 `protobuf:"bytes,4,opt,name=base_currency,json=baseCurrency,proto3" json:"base_currency,omitempty"`
+	ExportProfile  string                 `protobuf:"bytes,5,opt,name=export_profile,json=exportProfile,proto3" json:"export_profile,omitempty"`
+	Metadata       *DatasetMetadata       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -529,19 +700,151 @@ func (x *DatasetData) GetCacheData() string {
 	return ""
 }
 
+func (x *DatasetData) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *DatasetData) GetExportProfile() string {
+	if x != nil {
+		return x.ExportProfile
+	}
+	return ""
+}
+
+func (x *DatasetData) GetMetadata() *DatasetMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// DatasetMetadata describes where a dataset came from and what the user calls it.
+type DatasetMetadata struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Name             string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description      string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	OriginalFilename string                 `protobuf:"bytes,3,opt,name=original_filename,json=originalFilename,proto3" json:"original_filename,omitempty"`
+	UploadedAt       int64                  `protobuf:"varint,4,opt,name=uploaded_at,json=uploadedAt,proto3" json:"uploaded_at,omitempty"`
+	UpdatedAt        int64                  `protobuf:"varint,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	PlayerCount      int32                  `protobuf:"varint,6,opt,name=player_count,json=playerCount,proto3" json:"player_count,omitempty"`
+	FmVersion        string                 `protobuf:"bytes,7,opt,name=fm_version,json=fmVersion,proto3" json:"fm_version,omitempty"`
+	FileHash         string                 `protobuf:"bytes,8,opt,name=file_hash,json=fileHash,proto3" json:"file_hash,omitempty"`
+	Tags             []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DatasetMetadata) Reset() {
+	*x = DatasetMetadata{}
+	mi := &file_src_api_proto_player_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DatasetMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatasetMetadata) ProtoMessage() {}
+
+func (x *DatasetMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_src_api_proto_player_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatasetMetadata.ProtoReflect.Descriptor instead.
+func (*DatasetMetadata) Descriptor() ([]byte, []int) {
+	return file_src_api_proto_player_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DatasetMetadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DatasetMetadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DatasetMetadata) GetOriginalFilename() string {
+	if x != nil {
+		return x.OriginalFilename
+	}
+	return ""
+}
+
+func (x *DatasetMetadata) GetUploadedAt() int64 {
+	if x != nil {
+		return x.UploadedAt
+	}
+	return 0
+}
+
+func (x *DatasetMetadata) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *DatasetMetadata) GetPlayerCount() int32 {
+	if x != nil {
+		return x.PlayerCount
+	}
+	return 0
+}
+
+func (x *DatasetMetadata) GetFmVersion() string {
+	if x != nil {
+		return x.FmVersion
+	}
+	return ""
+}
+
+func (x *DatasetMetadata) GetFileHash() string {
+	if x != nil {
+		return x.FileHash
+	}
+	return ""
+}
+
+func (x *DatasetMetadata) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 var File_src_api_proto_player_proto protoreflect.FileDescriptor
 
 const file_src_api_proto_player_proto_rawDesc = "" +
 	"\n" +
-	"\x1asrc/api/proto/player.proto\x12\x06player\"E\n" +
+	"\x1asrc/api/proto/player.proto\x12\x06player\"\x7f\n" +
 	"\x10RoleOverallScore\x12\x1b\n" +
 	"\trole_name\x18\x01 \x01(\tR\broleName\x12\x14\n" +
-	"\x05score\x18\x02 \x01(\x05R\x05score\"\xaf\x01\n" +
+	"\x05score\x18\x02 \x01(\x05R\x05score\x12\x1b\n" +
+	"\tmin_score\x18\x03 \x01(\x05R\bminScore\x12\x1b\n" +
+	"\tmax_score\x18\x04 \x01(\x05R\bmaxScore\"\xaf\x01\n" +
 	"\x18PerformancePercentileMap\x12S\n" +
 	"\vpercentiles\x18\x01 \x03(\v21.player.PerformancePercentileMap.PercentilesEntryR\vpercentiles\x1a>\n" +
 	"\x10PercentilesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xac\r\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xcf\x15\n" +
 	"\x06Player\x12\x10\n" +
 	"\x03uid\x18\x01 \x01(\x03R\x03uid\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
@@ -585,7 +888,30 @@ const file_src_api_proto_player_proto_rawDesc = "" +
 	"\x16role_specific_overalls\x18% \x03(\v2\x18.player.RoleOverallScoreR\x14roleSpecificOveralls\x122\n" +
 	"\x15transfer_value_amount\x18& \x01(\x03R\x13transferValueAmount\x12\x1f\n" +
 	"\vwage_amount\x18' \x01(\x03R\n" +
-	"wageAmount\x1a=\n" +
+	"wageAmount\x12\x1b\n" +
+	"\tage_years\x18( \x01(\x05R\bageYears\x12\x1b\n" +
+	"\theight_cm\x18) \x01(\x05R\bheightCm\x12\x1b\n" +
+	"\tweight_kg\x18* \x01(\x05R\bweightKg\x12%\n" +
+	"\x0epreferred_foot\x18+ \x01(\tR\rpreferredFoot\x12\x1b\n" +
+	"\tleft_foot\x18, \x01(\x05R\bleftFoot\x12\x1d\n" +
+	"\n" +
+	"right_foot\x18- \x01(\x05R\trightFoot\x12'\n" +
+	"\x0fcontract_expiry\x18. \x01(\tR\x0econtractExpiry\x12\"\n" +
+	"\rdate_of_birth\x18/ \x01(\tR\vdateOfBirth\x12\x1f\n" +
+	"\vwage_period\x180 \x01(\tR\n" +
+	"wagePeriod\x12,\n" +
+	"\x12transfer_value_min\x181 \x01(\x03R\x10transferValueMin\x12,\n" +
+	"\x12transfer_value_max\x182 \x01(\x03R\x10transferValueMax\x126\n" +
+	"\x17transfer_value_estimate\x183 \x01(\x03R\x15transferValueEstimate\x12E\n" +
+	"\rattribute_min\x184 \x03(\v2 .player.Player.AttributeMinEntryR\fattributeMin\x12E\n" +
+	"\rattribute_max\x185 \x03(\v2 .player.Player.AttributeMaxEntryR\fattributeMax\x12\x1f\n" +
+	"\voverall_min\x186 \x01(\x05R\n" +
+	"overallMin\x12\x1f\n" +
+	"\voverall_max\x187 \x01(\x05R\n" +
+	"overallMax\x126\n" +
+	"\bstat_min\x188 \x03(\v2\x1b.player.Player.StatMinEntryR\astatMin\x126\n" +
+	"\bstat_max\x189 \x03(\v2\x1b.player.Player.StatMaxEntryR\astatMax\x12+\n" +
+	"\x11rating_confidence\x18: \x01(\tR\x10ratingConfidence\x1a=\n" +
 	"\x0fAttributesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aD\n" +
@@ -597,12 +923,40 @@ const file_src_api_proto_player_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1ak\n" +
 	"\x1bPerformancePercentilesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
-	"\x05value\x18\x02 \x01(\v2 .player.PerformancePercentileMapR\x05value:\x028\x01\"\x7f\n" +
+	"\x05value\x18\x02 \x01(\v2 .player.PerformancePercentileMapR\x05value:\x028\x01\x1a?\n" +
+	"\x11AttributeMinEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a?\n" +
+	"\x11AttributeMaxEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a:\n" +
+	"\fStatMinEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a:\n" +
+	"\fStatMaxEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x80\x02\n" +
 	"\vDatasetData\x12(\n" +
 	"\aplayers\x18\x01 \x03(\v2\x0e.player.PlayerR\aplayers\x12'\n" +
 	"\x0fcurrency_symbol\x18\x02 \x01(\tR\x0ecurrencySymbol\x12\x1d\n" +
 	"\n" +
-	"cache_data\x18\x03 \x01(\tR\tcacheDataB\vZ\tapi/protob\x06proto3"
+	"cache_data\x18\x03 \x01(\tR\tcacheData\x12#\n" +
+	"\rbase_currency\x18\x04 \x01(\tR\fbaseCurrency\x12%\n" +
+	"\x0eexport_profile\x18\x05 \x01(\tR\rexportProfile\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.player.DatasetMetadataR\bmetadata\"\xa7\x02\n" +
+	"\x0fDatasetMetadata\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12+\n" +
+	"\x11original_filename\x18\x03 \x01(\tR\x10originalFilename\x12\x1f\n" +
+	"\vuploaded_at\x18\x04 \x01(\x03R\n" +
+	"uploadedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\x03R\tupdatedAt\x12!\n" +
+	"\fplayer_count\x18\x06 \x01(\x05R\vplayerCount\x12\x1d\n" +
+	"\n" +
+	"fm_version\x18\a \x01(\tR\tfmVersion\x12\x1b\n" +
+	"\tfile_hash\x18\b \x01(\tR\bfileHash\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tagsB\vZ\tapi/protob\x06proto3"
 
 var (
 	file_src_api_proto_player_proto_rawDescOnce sync.Once
@@ -616,32 +970,42 @@ func file_src_api_proto_player_proto_rawDescGZIP() []byte {
 	return file_src_api_proto_player_proto_rawDescData
 }
 
-var file_src_api_proto_player_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_src_api_proto_player_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_src_api_proto_player_proto_goTypes = []any{
 	(*RoleOverallScore)(nil),         // 0: player.RoleOverallScore
 	(*PerformancePercentileMap)(nil), // 1: player.PerformancePercentileMap
 	(*Player)(nil),                   // 2: player.Player
 	(*DatasetData)(nil),              // 3: player.DatasetData
-	nil,                              // 4: player.PerformancePercentileMap.PercentilesEntry
-	nil,                              // 5: player.Player.AttributesEntry
-	nil,                              // 6: player.Player.NumericAttributesEntry
-	nil,                              // 7: player.Player.PerformanceStatsNumericEntry
-	nil,                              // 8: player.Player.PerformancePercentilesEntry
+	(*DatasetMetadata)(nil),          // 4: player.DatasetMetadata
+	nil,                              // 5: player.PerformancePercentileMap.PercentilesEntry
+	nil,                              // 6: player.Player.AttributesEntry
+	nil,                              // 7: player.Player.NumericAttributesEntry
+	nil,                              // 8: player.Player.PerformanceStatsNumericEntry
+	nil,                              // 9: player.Player.PerformancePercentilesEntry
+	nil,                              // 10: player.Player.AttributeMinEntry
+	nil,                              // 11: player.Player.AttributeMaxEntry
+	nil,                              // 12: player.Player.StatMinEntry
+	nil,                              // 13: player.Player.StatMaxEntry
 }
 var file_src_api_proto_player_proto_depIdxs = []int32{
-	4, // 0: player.PerformancePercentileMap.percentiles:type_name -> player.PerformancePercentileMap.PercentilesEntry
-	5, // 1: player.Player.attributes:type_name -> player.Player.AttributesEntry
-	6, // 2: player.Player.numeric_attributes:type_name -> player.Player.NumericAttributesEntry
-	7, // 3: player.Player.performance_stats_numeric:type_name -> player.Player.PerformanceStatsNumericEntry
-	8, // 4: player.Player.performance_percentiles:type_name -> player.Player.PerformancePercentilesEntry
-	0, // 5: player.Player.role_specific_overalls:type_name -> player.RoleOverallScore
-	2, // 6: player.DatasetData.players:type_name -> player.Player
-	1, // 7: player.Player.PerformancePercentilesEntry.value:type_name -> player.PerformancePercentileMap
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	5,  // 0: player.PerformancePercentileMap.percentiles:type_name -> player.PerformancePercentileMap.PercentilesEntry
+	6,  // 1: player.Player.attributes:type_name -> player.Player.AttributesEntry
+	7,  // 2: player.Player.numeric_attributes:type_name -> player.Player.NumericAttributesEntry
+	8,  // 3: player.Player.performance_stats_numeric:type_name -> player.Player.PerformanceStatsNumericEntry
+	9,  // 4: player.Player.performance_percentiles:type_name -> player.Player.PerformancePercentilesEntry
+	0,  // 5: player.Player.role_specific_overalls:type_name -> player.RoleOverallScore
+	10, // 6: player.Player.attribute_min:type_name -> player.Player.AttributeMinEntry
+	11, // 7: player.Player.attribute_max:type_name -> player.Player.AttributeMaxEntry
+	12, // 8: player.Player.stat_min:type_name -> player.Player.StatMinEntry
+	13, // 9: player.Player.stat_max:type_name -> player.Player.StatMaxEntry
+	2,  // 10: player.DatasetData.players:type_name -> player.Player
+	4,  // 11: player.DatasetData.metadata:type_name -> player.DatasetMetadata
+	1,  // 12: player.Player.PerformancePercentilesEntry.value:type_name -> player.PerformancePercentileMap
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_src_api_proto_player_proto_init() }
@@ -655,7 +1019,7 @@ func file_src_api_proto_player_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_src_api_proto_player_proto_rawDesc), len(file_src_api_proto_player_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
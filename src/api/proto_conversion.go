@@ -21,6 +21,30 @@ func safeIntToInt32(value int) int32 {
 	return int32(value)
 }
 
+// intMapToProto converts an int map to int32, keeping nil maps nil.
+func intMapToProto(values map[string]int) map[string]int32 {
+	if values == nil {
+		return nil
+	}
+	converted := make(map[string]int32, len(values))
+	for key, value := range values {
+		converted[key] = safeIntToInt32(value)
+	}
+	return converted
+}
+
+// intMapFromProto converts an int32 map back to int, keeping empty maps nil.
+func intMapFromProto(values map[string]int32) map[string]int {
+	if len(values) == 0 {
+		return nil
+	}
+	converted := make(map[string]int, len(values))
+	for key, value := range values {
+		converted[key] = int(value)
+	}
+	return converted
+}
+
 // --- RoleOverallScore Conversion Functions ---
 
 // ToProto converts a RoleOverallScore struct to protobuf format
@@ -53,6 +77,8 @@ func (r *RoleOverallScore) ToProto(ctx context.Context) (*proto.RoleOverallScore
 	protoRole := &proto.RoleOverallScore{
 		RoleName: r.RoleName,
 		Score:    safeIntToInt32(r.Score),
+		MinScore: safeIntToInt32(r.MinScore),
+		MaxScore: safeIntToInt32(r.MaxScore),
 	}
 
 	duration := time.Since(start)
@@ -99,6 +125,8 @@ func RoleOverallScoreFromProto(ctx context.Context, protoRole *proto.RoleOverall
 	role := &RoleOverallScore{
 		RoleName: protoRole.GetRoleName(),
 		Score:    int(protoRole.GetScore()),
+		MinScore: int(protoRole.GetMinScore()),
+		MaxScore: int(protoRole.GetMaxScore()),
 	}
 
 	duration := time.Since(start)
@@ -220,6 +248,25 @@ func (p *Player) ToProto(ctx context.Context) (*proto.Player, error) {
 		RoleSpecificOveralls:    protoRoles,
 		TransferValueAmount:     p.TransferValueAmount,
 		WageAmount:              p.WageAmount,
+		AgeYears:                safeIntToInt32(p.AgeYears),
+		HeightCm:                safeIntToInt32(p.HeightCm),
+		WeightKg:                safeIntToInt32(p.WeightKg),
+		PreferredFoot:           p.PreferredFoot,
+		LeftFoot:                safeIntToInt32(p.LeftFoot),
+		RightFoot:               safeIntToInt32(p.RightFoot),
+		ContractExpiry:          p.ContractExpiry,
+		DateOfBirth:             p.DateOfBirth,
+		WagePeriod:              string(p.WagePeriod),
+		TransferValueMin:        p.TransferValueMin,
+		TransferValueMax:        p.TransferValueMax,
+		TransferValueEstimate:   p.TransferValueEstimate,
+		AttributeMin:            intMapToProto(p.AttributeMin),
+		AttributeMax:            intMapToProto(p.AttributeMax),
+		OverallMin:              safeIntToInt32(p.OverallMin),
+		OverallMax:              safeIntToInt32(p.OverallMax),
+		StatMin:                 intMapToProto(p.StatMin),
+		StatMax:                 intMapToProto(p.StatMax),
+		RatingConfidence:        p.RatingConfidence,
 	}
 
 	duration := time.Since(start)
@@ -341,6 +388,25 @@ func PlayerFromProto(ctx context.Context, protoPlayer *proto.Player) (*Player, e
 		RoleSpecificOveralls:    roles,
 		TransferValueAmount:     protoPlayer.GetTransferValueAmount(),
 		WageAmount:              protoPlayer.GetWageAmount(),
+		AgeYears:                int(protoPlayer.GetAgeYears()),
+		HeightCm:                int(protoPlayer.GetHeightCm()),
+		WeightKg:                int(protoPlayer.GetWeightKg()),
+		PreferredFoot:           protoPlayer.GetPreferredFoot(),
+		LeftFoot:                int(protoPlayer.GetLeftFoot()),
+		RightFoot:               int(protoPlayer.GetRightFoot()),
+		ContractExpiry:          protoPlayer.GetContractExpiry(),
+		DateOfBirth:             protoPlayer.GetDateOfBirth(),
+		WagePeriod:              WagePeriod(protoPlayer.GetWagePeriod()),
+		TransferValueMin:        protoPlayer.GetTransferValueMin(),
+		TransferValueMax:        protoPlayer.GetTransferValueMax(),
+		TransferValueEstimate:   protoPlayer.GetTransferValueEstimate(),
+		AttributeMin:            intMapFromProto(protoPlayer.GetAttributeMin()),
+		AttributeMax:            intMapFromProto(protoPlayer.GetAttributeMax()),
+		OverallMin:              int(protoPlayer.GetOverallMin()),
+		OverallMax:              int(protoPlayer.GetOverallMax()),
+		StatMin:                 intMapFromProto(protoPlayer.GetStatMin()),
+		StatMax:                 intMapFromProto(protoPlayer.GetStatMax()),
+		RatingConfidence:        protoPlayer.GetRatingConfidence(),
 	}
 
 	duration := time.Since(start)
@@ -361,6 +427,61 @@ func PlayerFromProto(ctx context.Context, protoPlayer *proto.Player) (*Player, e
 	return player, nil
 }
 
+// --- DatasetMetadata Conversion Functions ---
+
+// datasetMetadataToProto converts dataset metadata to protobuf format, storing times as Unix
+// seconds. Datasets stored before metadata existed have none and stay nil.
+func datasetMetadataToProto(metadata *DatasetMetadata) *proto.DatasetMetadata {
+	if metadata == nil {
+		return nil
+	}
+	return &proto.DatasetMetadata{
+		Name:             metadata.Name,
+		Description:      metadata.Description,
+		OriginalFilename: metadata.OriginalFilename,
+		UploadedAt:       unixSecondsToProto(metadata.UploadedAt),
+		UpdatedAt:        unixSecondsToProto(metadata.UpdatedAt),
+		PlayerCount:      safeIntToInt32(metadata.PlayerCount),
+		FmVersion:        metadata.FMVersion,
+		FileHash:         metadata.FileHash,
+		Tags:             metadata.Tags,
+	}
+}
+
+// datasetMetadataFromProto converts protobuf dataset metadata back to the native struct.
+func datasetMetadataFromProto(protoMetadata *proto.DatasetMetadata) *DatasetMetadata {
+	if protoMetadata == nil {
+		return nil
+	}
+	return &DatasetMetadata{
+		Name:             protoMetadata.GetName(),
+		Description:      protoMetadata.GetDescription(),
+		OriginalFilename: protoMetadata.GetOriginalFilename(),
+		UploadedAt:       unixSecondsFromProto(protoMetadata.GetUploadedAt()),
+		UpdatedAt:        unixSecondsFromProto(protoMetadata.GetUpdatedAt()),
+		PlayerCount:      int(protoMetadata.GetPlayerCount()),
+		FMVersion:        protoMetadata.GetFmVersion(),
+		FileHash:         protoMetadata.GetFileHash(),
+		Tags:             protoMetadata.GetTags(),
+	}
+}
+
+// unixSecondsToProto stores a time as Unix seconds, keeping the zero time as 0.
+func unixSecondsToProto(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// unixSecondsFromProto restores a time stored by unixSecondsToProto in UTC.
+func unixSecondsFromProto(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
 // --- DatasetData Conversion Functions ---
 
 // ToProto converts a PlayerDataWithCurrency struct to protobuf format
@@ -407,6 +528,9 @@ func (d *PlayerDataWithCurrency) ToProto(ctx context.Context) (*proto.DatasetDat
 	protoDataset := &proto.DatasetData{
 		Players:        protoPlayers,
 		CurrencySymbol: d.CurrencySymbol,
+		BaseCurrency:   d.BaseCurrency,
+		ExportProfile:  d.ExportProfile,
+		Metadata:       datasetMetadataToProto(d.Metadata),
 	}
 
 	duration := time.Since(start)
@@ -468,6 +592,9 @@ func DatasetDataFromProto(ctx context.Context, protoDataset *proto.DatasetData)
 	dataset := &PlayerDataWithCurrency{
 		Players:        players,
 		CurrencySymbol: protoDataset.GetCurrencySymbol(),
+		BaseCurrency:   protoDataset.GetBaseCurrency(),
+		ExportProfile:  protoDataset.GetExportProfile(),
+		Metadata:       datasetMetadataFromProto(protoDataset.GetMetadata()),
 	}
 
 	duration := time.Since(start)
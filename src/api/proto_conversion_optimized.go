@@ -33,6 +33,8 @@ func (r *RoleOverallScore) ToProtoOptimized(ctx context.Context) (*proto.RoleOve
 	protoRole := &proto.RoleOverallScore{
 		RoleName: r.RoleName,
 		Score:    safeIntToInt32(r.Score),
+		MinScore: safeIntToInt32(r.MinScore),
+		MaxScore: safeIntToInt32(r.MaxScore),
 	}
 
 	duration := time.Since(start)
@@ -66,6 +68,8 @@ func RoleOverallScoreFromProtoOptimized(ctx context.Context, protoRole *proto.Ro
 	role := &RoleOverallScore{
 		RoleName: protoRole.GetRoleName(),
 		Score:    int(protoRole.GetScore()),
+		MinScore: int(protoRole.GetMinScore()),
+		MaxScore: int(protoRole.GetMaxScore()),
 	}
 
 	duration := time.Since(start)
@@ -173,6 +177,25 @@ func (p *Player) ToProtoOptimized(ctx context.Context) (*proto.Player, error) {
 		RoleSpecificOveralls:    protoRoles,
 		TransferValueAmount:     p.TransferValueAmount,
 		WageAmount:              p.WageAmount,
+		AgeYears:                safeIntToInt32(p.AgeYears),
+		HeightCm:                safeIntToInt32(p.HeightCm),
+		WeightKg:                safeIntToInt32(p.WeightKg),
+		PreferredFoot:           p.PreferredFoot,
+		LeftFoot:                safeIntToInt32(p.LeftFoot),
+		RightFoot:               safeIntToInt32(p.RightFoot),
+		ContractExpiry:          p.ContractExpiry,
+		DateOfBirth:             p.DateOfBirth,
+		WagePeriod:              string(p.WagePeriod),
+		TransferValueMin:        p.TransferValueMin,
+		TransferValueMax:        p.TransferValueMax,
+		TransferValueEstimate:   p.TransferValueEstimate,
+		AttributeMin:            intMapToProto(p.AttributeMin),
+		AttributeMax:            intMapToProto(p.AttributeMax),
+		OverallMin:              safeIntToInt32(p.OverallMin),
+		OverallMax:              safeIntToInt32(p.OverallMax),
+		StatMin:                 intMapToProto(p.StatMin),
+		StatMax:                 intMapToProto(p.StatMax),
+		RatingConfidence:        p.RatingConfidence,
 	}
 
 	duration := time.Since(start)
@@ -281,6 +304,25 @@ func PlayerFromProtoOptimized(ctx context.Context, protoPlayer *proto.Player) (*
 		RoleSpecificOveralls:    roles,
 		TransferValueAmount:     protoPlayer.GetTransferValueAmount(),
 		WageAmount:              protoPlayer.GetWageAmount(),
+		AgeYears:                int(protoPlayer.GetAgeYears()),
+		HeightCm:                int(protoPlayer.GetHeightCm()),
+		WeightKg:                int(protoPlayer.GetWeightKg()),
+		PreferredFoot:           protoPlayer.GetPreferredFoot(),
+		LeftFoot:                int(protoPlayer.GetLeftFoot()),
+		RightFoot:               int(protoPlayer.GetRightFoot()),
+		ContractExpiry:          protoPlayer.GetContractExpiry(),
+		DateOfBirth:             protoPlayer.GetDateOfBirth(),
+		WagePeriod:              WagePeriod(protoPlayer.GetWagePeriod()),
+		TransferValueMin:        protoPlayer.GetTransferValueMin(),
+		TransferValueMax:        protoPlayer.GetTransferValueMax(),
+		TransferValueEstimate:   protoPlayer.GetTransferValueEstimate(),
+		AttributeMin:            intMapFromProto(protoPlayer.GetAttributeMin()),
+		AttributeMax:            intMapFromProto(protoPlayer.GetAttributeMax()),
+		OverallMin:              int(protoPlayer.GetOverallMin()),
+		OverallMax:              int(protoPlayer.GetOverallMax()),
+		StatMin:                 intMapFromProto(protoPlayer.GetStatMin()),
+		StatMax:                 intMapFromProto(protoPlayer.GetStatMax()),
+		RatingConfidence:        protoPlayer.GetRatingConfidence(),
 	}
 
 	duration := time.Since(start)
@@ -335,6 +377,9 @@ func (d *PlayerDataWithCurrency) ToProtoOptimized(ctx context.Context) (*proto.D
 		Players:        protoPlayers,
 		CurrencySymbol: d.CurrencySymbol,
 		CacheData:      "", // PlayerDataWithCurrency doesn't have CacheData field
+		BaseCurrency:   d.BaseCurrency,
+		ExportProfile:  d.ExportProfile,
+		Metadata:       datasetMetadataToProto(d.Metadata),
 	}
 
 	duration := time.Since(start)
@@ -387,6 +432,9 @@ func DatasetDataFromProtoOptimized(ctx context.Context, protoDataset *proto.Data
 	dataset := &PlayerDataWithCurrency{
 		Players:        players,
 		CurrencySymbol: protoDataset.GetCurrencySymbol(),
+		BaseCurrency:   protoDataset.GetBaseCurrency(),
+		ExportProfile:  protoDataset.GetExportProfile(),
+		Metadata:       datasetMetadataFromProto(protoDataset.GetMetadata()),
 	}
 
 	duration := time.Since(start)
@@ -51,12 +51,20 @@ func (s *ProtobufStorage) Store(datasetID string, data DatasetData) error {
 		attribute.String("storage.type", "protobuf"),
 	)
 
+	// Cache entries and save records carry JSON instead of players, which protobuf conversion would drop
+	if data.CacheData != "" {
+		return s.storeWithJSONFallback(ctx, datasetID, data)
+	}
+
 	start := time.Now()
 
 	// Convert DatasetData to PlayerDataWithCurrency for protobuf conversion
 	playerData := &PlayerDataWithCurrency{
 		Players:        data.Players,
 		CurrencySymbol: data.CurrencySymbol,
+		BaseCurrency:   data.BaseCurrency,
+		ExportProfile:  data.ExportProfile,
+		Metadata:       data.Metadata,
 	}
 
 	// Convert to protobuf
@@ -251,6 +259,9 @@ func (s *ProtobufStorage) Retrieve(datasetID string) (DatasetData, error) {
 	result := DatasetData{
 		Players:        playerData.Players,
 		CurrencySymbol: playerData.CurrencySymbol,
+		BaseCurrency:   playerData.BaseCurrency,
+		ExportProfile:  playerData.ExportProfile,
+		Metadata:       playerData.Metadata,
 	}
 
 	SetSpanAttributes(ctx,
@@ -302,6 +313,11 @@ func (s *ProtobufStorage) List() ([]string, error) {
 	return s.backend.List()
 }
 
+// DatasetSize returns the stored size of a dataset when the backend can report it
+func (s *ProtobufStorage) DatasetSize(datasetID string) (int64, error) {
+	return datasetSize(s.backend, datasetID)
+}
+
 // CleanupOldDatasets removes old datasets
 func (s *ProtobufStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
 	return s.backend.CleanupOldDatasets(maxAge, excludeDatasets)
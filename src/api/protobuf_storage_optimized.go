@@ -372,6 +372,9 @@ func (s *OptimizedProtobufStorage) storeWithJSONFallback(ctx context.Context, da
 	datasetData := DatasetData{
 		Players:        data.Players,
 		CurrencySymbol: data.CurrencySymbol,
+		BaseCurrency:   data.BaseCurrency,
+		ExportProfile:  data.ExportProfile,
+		Metadata:       data.Metadata,
 		CacheData:      "", // PlayerDataWithCurrency doesn't have CacheData field
 	}
 	return s.backend.Store(datasetID, datasetData)
@@ -381,10 +384,18 @@ func (s *OptimizedProtobufStorage) storeWithJSONFallback(ctx context.Context, da
 
 // Store implements StorageInterface.Store
 func (s *OptimizedProtobufStorage) Store(datasetID string, data DatasetData) error {
+	// Cache entries and save records carry JSON instead of players, which protobuf conversion would drop
+	if data.CacheData != "" {
+		return s.backend.Store(datasetID, data)
+	}
+
 	// Convert DatasetData to PlayerDataWithCurrency
 	playerData := PlayerDataWithCurrency{
 		Players:        data.Players,
 		CurrencySymbol: data.CurrencySymbol,
+		BaseCurrency:   data.BaseCurrency,
+		ExportProfile:  data.ExportProfile,
+		Metadata:       data.Metadata,
 	}
 
 	ctx := context.Background()
@@ -396,6 +407,10 @@ func (s *OptimizedProtobufStorage) Retrieve(datasetID string) (DatasetData, erro
 	ctx := context.Background()
 	playerData, err := s.RetrieveWithContext(ctx, datasetID)
 	if err != nil {
+		// Cache entries and save records are stored as plain JSON by Store
+		if record, recordErr := s.backend.Retrieve(datasetID); recordErr == nil && record.CacheData != "" {
+			return record, nil
+		}
 		return DatasetData{}, err
 	}
 
@@ -403,6 +418,9 @@ func (s *OptimizedProtobufStorage) Retrieve(datasetID string) (DatasetData, erro
 	return DatasetData{
 		Players:        playerData.Players,
 		CurrencySymbol: playerData.CurrencySymbol,
+		BaseCurrency:   playerData.BaseCurrency,
+		ExportProfile:  playerData.ExportProfile,
+		Metadata:       playerData.Metadata,
 		CacheData:      "",
 	}, nil
 }
@@ -417,6 +435,11 @@ func (s *OptimizedProtobufStorage) List() ([]string, error) {
 	return s.backend.List()
 }
 
+// DatasetSize implements DatasetSizer when the backend can report sizes
+func (s *OptimizedProtobufStorage) DatasetSize(datasetID string) (int64, error) {
+	return datasetSize(s.backend, datasetID)
+}
+
 // CleanupOldDatasets implements StorageInterface.CleanupOldDatasets
 func (s *OptimizedProtobufStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
 	return s.backend.CleanupOldDatasets(maxAge, excludeDatasets)
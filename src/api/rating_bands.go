@@ -0,0 +1,230 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Rating confidence levels reported on Player.RatingConfidence.
+const (
+	RatingConfidenceHigh   = "high"   // The overall is exact: no masked attribute moves it
+	RatingConfidenceMedium = "medium" // Masked attributes move the overall by at most mediumConfidenceBandWidth
+	RatingConfidenceLow    = "low"
+)
+
+// mediumConfidenceBandWidth is the widest overall band still reported as medium confidence.
+const mediumConfidenceBandWidth = 6
+
+// Bounds assumed for an attribute FM shows as "-", i.e. anywhere on its 1-20 scale.
+const (
+	maskedAttributeMin = 1
+	maskedAttributeMax = 20
+)
+
+// topRoleCountForOverall is how many of the best role ratings are averaged into Overall.
+const topRoleCountForOverall = 7
+
+// RatingBand is a rating with the pessimistic and optimistic bounds implied by masked attributes.
+type RatingBand struct {
+	Min      int `json:"min"`
+	Expected int `json:"expected"`
+	Max      int `json:"max"`
+}
+
+// parseMaskedAttribute reads a scouted attribute cell. Ranges such as "10-15" return their
+// midpoint and bounds. A fully masked "-" returns the whole scale and an expected value of 0,
+// which calculations skip rather than count as a real 0. ok is false for unmasked values.
+func parseMaskedAttribute(value string) (expected, minValue, maxValue int, ok bool) {
+	if value == "-" {
+		return 0, maskedAttributeMin, maskedAttributeMax, true
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, 0, false
+	}
+	low, err1 := fastParseInt(strings.TrimSpace(parts[0]))
+	high, err2 := fastParseInt(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, 0, false
+	}
+	if low > high {
+		low, high = high, low
+	}
+	return (low + high) / 2, low, high, true
+}
+
+// maskedAttributeBounds collects the bounds of every masked attribute among those converted to
+// NumericAttributes. It returns nil maps when nothing is masked.
+func maskedAttributeBounds(player *Player) (minAttrs, maxAttrs map[string]int) {
+	for key := range player.NumericAttributes {
+		_, minValue, maxValue, ok := parseMaskedAttribute(player.Attributes[key])
+		if !ok {
+			continue
+		}
+		if minAttrs == nil {
+			minAttrs = make(map[string]int)
+			maxAttrs = make(map[string]int)
+		}
+		minAttrs[key] = minValue
+		maxAttrs[key] = maxValue
+	}
+	return minAttrs, maxAttrs
+}
+
+// attributeBoundMaps returns the player's numeric attributes with every masked attribute
+// replaced by its lower bound, and by its upper bound.
+func attributeBoundMaps(player *Player) (minAttrs, maxAttrs map[string]int) {
+	minAttrs = make(map[string]int, len(player.NumericAttributes))
+	maxAttrs = make(map[string]int, len(player.NumericAttributes))
+	for key, value := range player.NumericAttributes {
+		minAttrs[key] = value
+		maxAttrs[key] = value
+	}
+	for key, value := range player.AttributeMin {
+		minAttrs[key] = value
+	}
+	for key, value := range player.AttributeMax {
+		maxAttrs[key] = value
+	}
+	return minAttrs, maxAttrs
+}
+
+// CalculateOverallBandForRoleGo rates a role with every masked attribute at its lower bound,
+// its expected value and its upper bound, using the current rating scale.
+func CalculateOverallBandForRoleGo(expectedAttrs, minAttrs, maxAttrs, roleSpecificAttrWeights map[string]int) RatingBand {
+	calculate := CalculateOverallForRoleGo
+	if !GetUseScaledRatings() {
+		calculate = CalculateOverallForRoleGoLinear
+	}
+	return RatingBand{
+		Min:      calculate(minAttrs, roleSpecificAttrWeights),
+		Expected: calculate(expectedAttrs, roleSpecificAttrWeights),
+		Max:      calculate(maxAttrs, roleSpecificAttrWeights),
+	}
+}
+
+// CalculateFifaStatBandGo is the FIFA-style category stat counterpart of CalculateOverallBandForRoleGo.
+func CalculateFifaStatBandGo(expectedAttrs, minAttrs, maxAttrs map[string]int, categoryName string) RatingBand {
+	calculate := CalculateFifaStatGo
+	if !GetUseScaledRatings() {
+		calculate = CalculateFifaStatGoLinear
+	}
+	return RatingBand{
+		Min:      calculate(minAttrs, categoryName),
+		Expected: calculate(expectedAttrs, categoryName),
+		Max:      calculate(maxAttrs, categoryName),
+	}
+}
+
+// applyRatingBands fills the rating bands and confidence level from the ratings already
+// calculated on player. Players without masked attributes get a zero-width band. New maps
+// are always assigned because recalculated players share maps with the cached dataset.
+func applyRatingBands(player *Player) {
+	player.StatMin = nil
+	player.StatMax = nil
+
+	// Datasets stored before bounds were kept only carry the raw ranges
+	if len(player.AttributeMin) == 0 && player.AttributeMasked {
+		player.AttributeMin, player.AttributeMax = maskedAttributeBounds(player)
+	}
+
+	if len(player.AttributeMin) == 0 {
+		for i := range player.RoleSpecificOveralls {
+			player.RoleSpecificOveralls[i].MinScore = player.RoleSpecificOveralls[i].Score
+			player.RoleSpecificOveralls[i].MaxScore = player.RoleSpecificOveralls[i].Score
+		}
+		player.OverallMin = player.Overall
+		player.OverallMax = player.Overall
+		player.RatingConfidence = RatingConfidenceHigh
+		return
+	}
+
+	minAttrs, maxAttrs := attributeBoundMaps(player)
+
+	roleWeights := roleWeightsForPlayer(player)
+	minScores := make([]int, 0, len(player.RoleSpecificOveralls))
+	maxScores := make([]int, 0, len(player.RoleSpecificOveralls))
+	for i := range player.RoleSpecificOveralls {
+		role := &player.RoleSpecificOveralls[i]
+		band := CalculateOverallBandForRoleGo(player.NumericAttributes, minAttrs, maxAttrs, roleWeights[role.RoleName])
+		role.MinScore = band.Min
+		role.MaxScore = band.Max
+		minScores = append(minScores, band.Min)
+		maxScores = append(maxScores, band.Max)
+	}
+	player.OverallMin, player.OverallMax = player.Overall, player.Overall
+	if len(minScores) > 0 {
+		player.OverallMin = meanOfTopRoleScores(minScores)
+		player.OverallMax = meanOfTopRoleScores(maxScores)
+	}
+
+	categories := []string{"PAC", "SHO", "PAS", "DRI", "DEF", "PHY"}
+	for _, group := range player.PositionGroups {
+		if group == "Goalkeepers" {
+			categories = []string{"GK", "DIV", "HAN", "REF", "KIC", "SPD", "POS"}
+			break
+		}
+	}
+	player.StatMin = make(map[string]int, len(categories))
+	player.StatMax = make(map[string]int, len(categories))
+	for _, category := range categories {
+		band := CalculateFifaStatBandGo(player.NumericAttributes, minAttrs, maxAttrs, category)
+		player.StatMin[category] = band.Min
+		player.StatMax[category] = band.Max
+	}
+
+	switch width := player.OverallMax - player.OverallMin; {
+	case width == 0:
+		player.RatingConfidence = RatingConfidenceHigh
+	case width <= mediumConfidenceBandWidth:
+		player.RatingConfidence = RatingConfidenceMedium
+	default:
+		player.RatingConfidence = RatingConfidenceLow
+	}
+}
+
+// roleWeightsForPlayer returns the attribute weights of the player's rated roles, keyed by role name.
+func roleWeightsForPlayer(player *Player) map[string]map[string]int {
+	muPrecomputedRoleWeights.RLock()
+	currentPrecomputedWeights := precomputedRoleWeights
+	muPrecomputedRoleWeights.RUnlock()
+
+	weights := make(map[string]map[string]int)
+	for _, shortKey := range player.ShortPositions {
+		for _, roleData := range currentPrecomputedWeights[shortKey] {
+			if _, exists := weights[roleData.RoleName]; !exists {
+				weights[roleData.RoleName] = roleData.Weights
+			}
+		}
+	}
+
+	// Roles rated through the slow path before weights were precomputed are keyed by full name
+	muRoleSpecificOverallWeights.RLock()
+	defer muRoleSpecificOverallWeights.RUnlock()
+	for _, role := range player.RoleSpecificOveralls {
+		if _, exists := weights[role.RoleName]; !exists {
+			if fallbackWeights, found := roleSpecificOverallWeights[role.RoleName]; found {
+				weights[role.RoleName] = fallbackWeights
+			}
+		}
+	}
+	return weights
+}
+
+// meanOfTopRoleScores averages the best topRoleCountForOverall scores, as Overall does.
+func meanOfTopRoleScores(scores []int) int {
+	if len(scores) == 0 {
+		return 0
+	}
+	sorted := make([]int, len(scores))
+	copy(sorted, scores)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	if len(sorted) > topRoleCountForOverall {
+		sorted = sorted[:topRoleCountForOverall]
+	}
+	total := 0
+	for _, score := range sorted {
+		total += score
+	}
+	return total / len(sorted)
+}
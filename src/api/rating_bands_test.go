@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseMaskedAttribute(t *testing.T) {
+	tests := []struct {
+		input                 string
+		wantExpected, wantMin int
+		wantMax               int
+		wantOk                bool
+	}{
+		{"10-15", 12, 10, 15, true},
+		{"15 - 10", 12, 10, 15, true},
+		{"-", 0, maskedAttributeMin, maskedAttributeMax, true},
+		{"14", 0, 0, 0, false},
+		{"a-b", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		expected, minValue, maxValue, ok := parseMaskedAttribute(tt.input)
+		if expected != tt.wantExpected || minValue != tt.wantMin || maxValue != tt.wantMax || ok != tt.wantOk {
+			t.Errorf("parseMaskedAttribute(%q) = %d, %d, %d, %v, want %d, %d, %d, %v", tt.input,
+				expected, minValue, maxValue, ok, tt.wantExpected, tt.wantMin, tt.wantMax, tt.wantOk)
+		}
+	}
+}
+
+func TestCalculateOverallBandForRoleGo(t *testing.T) {
+	weights := map[string]int{"Pas": 5, "Vis": 5, "Tec": 3}
+	expected := map[string]int{"Pas": 15, "Vis": 12, "Tec": 14}
+	minAttrs := map[string]int{"Pas": 15, "Vis": 10, "Tec": 14}
+	maxAttrs := map[string]int{"Pas": 15, "Vis": 15, "Tec": 14}
+
+	band := CalculateOverallBandForRoleGo(expected, minAttrs, maxAttrs, weights)
+	if band.Min > band.Expected || band.Expected > band.Max || band.Min == band.Max {
+		t.Errorf("Expected min < max with expected in between, got %+v", band)
+	}
+
+	exact := CalculateOverallBandForRoleGo(expected, expected, expected, weights)
+	if exact.Min != exact.Expected || exact.Max != exact.Expected {
+		t.Errorf("Expected a zero-width band without masked attributes, got %+v", exact)
+	}
+}
+
+func TestApplyRatingBands(t *testing.T) {
+	muRoleSpecificOverallWeights.Lock()
+	previous := roleSpecificOverallWeights
+	roleSpecificOverallWeights = map[string]map[string]int{"Test Playmaker": {"Pas": 5, "Vis": 5, "Tec": 3}}
+	muRoleSpecificOverallWeights.Unlock()
+	t.Cleanup(func() {
+		muRoleSpecificOverallWeights.Lock()
+		roleSpecificOverallWeights = previous
+		muRoleSpecificOverallWeights.Unlock()
+	})
+
+	newPlayer := func(vision string) *Player {
+		expectedVision, _, _, masked := parseMaskedAttribute(vision)
+		if !masked {
+			expectedVision, _ = fastParseInt(vision)
+		}
+		player := &Player{
+			Attributes:        map[string]string{"Pas": "15", "Vis": vision, "Tec": "14"},
+			NumericAttributes: map[string]int{"Pas": 15, "Vis": expectedVision, "Tec": 14},
+			AttributeMasked:   masked,
+		}
+		player.AttributeMin, player.AttributeMax = maskedAttributeBounds(player)
+		score := CalculateOverallForRoleGo(player.NumericAttributes, roleSpecificOverallWeights["Test Playmaker"])
+		player.RoleSpecificOveralls = []RoleOverallScore{{RoleName: "Test Playmaker", Score: score}}
+		player.Overall = score
+		return player
+	}
+
+	exact := newPlayer("12")
+	applyRatingBands(exact)
+	if exact.RatingConfidence != RatingConfidenceHigh || exact.OverallMin != exact.Overall || exact.OverallMax != exact.Overall {
+		t.Errorf("Expected an exact rating for an unmasked player, got %d-%d (%s)", exact.OverallMin, exact.OverallMax, exact.RatingConfidence)
+	}
+	if exact.StatMin != nil {
+		t.Errorf("Expected no stat bands for an unmasked player, got %v", exact.StatMin)
+	}
+
+	ranged := newPlayer("10-15")
+	applyRatingBands(ranged)
+	if ranged.OverallMin >= ranged.OverallMax || ranged.Overall < ranged.OverallMin || ranged.Overall > ranged.OverallMax {
+		t.Errorf("Expected %d within a non-empty band, got %d-%d", ranged.Overall, ranged.OverallMin, ranged.OverallMax)
+	}
+	role := ranged.RoleSpecificOveralls[0]
+	if role.MinScore != ranged.OverallMin || role.MaxScore != ranged.OverallMax {
+		t.Errorf("Role band %d-%d should match the overall band", role.MinScore, role.MaxScore)
+	}
+	if ranged.StatMin["PAS"] > ranged.StatMax["PAS"] {
+		t.Errorf("Expected PAS min <= max, got %d > %d", ranged.StatMin["PAS"], ranged.StatMax["PAS"])
+	}
+
+	hidden := newPlayer("-")
+	applyRatingBands(hidden)
+	if hidden.OverallMax-hidden.OverallMin <= ranged.OverallMax-ranged.OverallMin {
+		t.Errorf("A fully masked attribute should widen the band beyond a ranged one")
+	}
+	if hidden.RatingConfidence == RatingConfidenceHigh {
+		t.Errorf("Expected reduced confidence for a fully masked attribute, got %s", hidden.RatingConfidence)
+	}
+
+	// Datasets stored before bounds were kept rebuild them from the raw attributes
+	legacy := newPlayer("10-15")
+	legacy.AttributeMin, legacy.AttributeMax = nil, nil
+	applyRatingBands(legacy)
+	if legacy.OverallMin != ranged.OverallMin || legacy.OverallMax != ranged.OverallMax {
+		t.Errorf("Expected legacy bounds %d-%d, got %d-%d", ranged.OverallMin, ranged.OverallMax, legacy.OverallMin, legacy.OverallMax)
+	}
+
+	protoPlayer, err := ranged.ToProto(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to convert to protobuf: %v", err)
+	}
+	converted, err := PlayerFromProto(context.Background(), protoPlayer)
+	if err != nil {
+		t.Fatalf("Failed to convert from protobuf: %v", err)
+	}
+	if converted.OverallMin != ranged.OverallMin || converted.OverallMax != ranged.OverallMax ||
+		converted.RatingConfidence != ranged.RatingConfidence || converted.AttributeMin["Vis"] != 10 ||
+		converted.RoleSpecificOveralls[0].MaxScore != role.MaxScore {
+		t.Errorf("Rating bands lost in protobuf round trip: %+v", converted)
+	}
+}
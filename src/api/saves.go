@@ -0,0 +1,604 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	apperrors "api/errors"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// gameDateLayout is the format of in-game dates on save snapshots.
+const gameDateLayout = "2006-01-02"
+
+// SaveSnapshot links a dataset to a save at an in-game date.
+type SaveSnapshot struct {
+	DatasetID string    `json:"datasetId"`
+	GameDate  string    `json:"gameDate"` // YYYY-MM-DD
+	AddedAt   time.Time `json:"addedAt"`
+}
+
+// Save groups the datasets exported from one FM save over in-game time. Snapshots are kept in
+// chronological order by in-game date; snapshots sharing a date are ordered by when they were added.
+type Save struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	Snapshots   []SaveSnapshot `json:"snapshots"`
+}
+
+// SaveRequest is the body of a save creation.
+type SaveRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SaveSnapshotRequest is the body of a snapshot link.
+type SaveSnapshotRequest struct {
+	DatasetID string `json:"datasetId"`
+	GameDate  string `json:"gameDate"`
+}
+
+// LatestSaveSnapshotResponse is the current state of a save: its most recent snapshot whose
+// dataset still exists.
+type LatestSaveSnapshotResponse struct {
+	SaveID   string         `json:"saveId"`
+	Snapshot SaveSnapshot   `json:"snapshot"`
+	Dataset  DatasetSummary `json:"dataset"`
+}
+
+var (
+	// muSaves serializes changes to save records, which read, modify and store the record.
+	muSaves sync.Mutex
+
+	// datasetSaveIndex maps snapshot dataset IDs to the save holding them, so linking and
+	// deleting a dataset do not read every save record. It is built from storage on first use
+	// and is nil until then. Guarded by muSaves.
+	datasetSaveIndex map[string]string
+)
+
+// saveRecordID is the storage ID of a save record. Saves live in the configured storage next
+// to the datasets, holding their JSON in CacheData the way cache entries do.
+func saveRecordID(saveID string) string {
+	return saveRecordPrefix + saveID
+}
+
+// sortSaveSnapshots orders snapshots by in-game date, then by when they were added.
+func sortSaveSnapshots(snapshots []SaveSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].GameDate != snapshots[j].GameDate {
+			return snapshots[i].GameDate < snapshots[j].GameDate
+		}
+		return snapshots[i].AddedAt.Before(snapshots[j].AddedAt)
+	})
+}
+
+// normalizeGameDate validates an in-game date and returns it in YYYY-MM-DD form.
+func normalizeGameDate(gameDate string) (string, error) {
+	parsed, err := time.Parse(gameDateLayout, strings.TrimSpace(gameDate))
+	if err != nil {
+		return "", apperrors.WrapErrInvalidGameDate(gameDate)
+	}
+	return parsed.Format(gameDateLayout), nil
+}
+
+// loadSave reads a save record from storage.
+func loadSave(saveID string) (Save, error) {
+	data, err := storage.Retrieve(saveRecordID(saveID))
+	if err != nil || data.CacheData == "" {
+		return Save{}, apperrors.WrapErrSaveNotFound(saveID)
+	}
+
+	var save Save
+	if err := json.Unmarshal([]byte(data.CacheData), &save); err != nil {
+		return Save{}, fmt.Errorf("failed to unmarshal save %s: %w", saveID, err)
+	}
+	if save.Snapshots == nil {
+		save.Snapshots = []SaveSnapshot{}
+	}
+	return save, nil
+}
+
+// storeSave writes a save record to storage.
+func storeSave(save Save) error {
+	jsonData, err := json.Marshal(save)
+	if err != nil {
+		return fmt.Errorf("failed to marshal save %s: %w", save.ID, err)
+	}
+	return storage.Store(saveRecordID(save.ID), DatasetData{
+		Players:   []Player{},
+		CacheData: string(jsonData),
+	})
+}
+
+// saveIDForDataset returns the ID of the save holding a dataset, or "" when no save holds it.
+// An index entry whose save no longer holds the dataset, because the record was changed
+// elsewhere, rebuilds the index. Callers hold muSaves.
+func saveIDForDataset(datasetID string) (string, error) {
+	if datasetSaveIndex != nil {
+		saveID, found := datasetSaveIndex[datasetID]
+		if !found || saveHoldsDataset(saveID, datasetID) {
+			return saveID, nil
+		}
+	}
+
+	saves, err := ListSaves()
+	if err != nil {
+		return "", err
+	}
+	datasetSaveIndex = make(map[string]string)
+	for i := range saves {
+		indexSaveSnapshots(saves[i])
+	}
+	return datasetSaveIndex[datasetID], nil
+}
+
+// saveHoldsDataset reports whether the stored save record holds a snapshot of a dataset.
+func saveHoldsDataset(saveID, datasetID string) bool {
+	save, err := loadSave(saveID)
+	if err != nil {
+		return false
+	}
+	for _, snapshot := range save.Snapshots {
+		if snapshot.DatasetID == datasetID {
+			return true
+		}
+	}
+	return false
+}
+
+// indexSaveSnapshots records the snapshot datasets of a stored save in datasetSaveIndex,
+// dropping datasets the save no longer holds. Callers hold muSaves.
+func indexSaveSnapshots(save Save) {
+	if datasetSaveIndex == nil {
+		return // Built from storage on first use
+	}
+	unindexSave(save.ID)
+	for _, snapshot := range save.Snapshots {
+		datasetSaveIndex[snapshot.DatasetID] = save.ID
+	}
+}
+
+// unindexSave drops the snapshot datasets of a save from datasetSaveIndex. Callers hold muSaves.
+func unindexSave(saveID string) {
+	for datasetID, indexedSaveID := range datasetSaveIndex {
+		if indexedSaveID == saveID {
+			delete(datasetSaveIndex, datasetID)
+		}
+	}
+}
+
+// resetDatasetSaveIndex discards datasetSaveIndex after save records were written directly to
+// storage, as a restore does, so it is rebuilt on next use.
+func resetDatasetSaveIndex() {
+	muSaves.Lock()
+	defer muSaves.Unlock()
+	datasetSaveIndex = nil
+}
+
+// ListSaves returns all saves ordered by name.
+func ListSaves() ([]Save, error) {
+	ids, err := ListDatasets()
+	if err != nil {
+		return nil, err
+	}
+
+	saves := make([]Save, 0)
+	for _, id := range ids {
+		if !strings.HasPrefix(id, saveRecordPrefix) {
+			continue
+		}
+		save, err := loadSave(strings.TrimPrefix(id, saveRecordPrefix))
+		if err != nil {
+			LogWarn("Skipping unreadable save record %s: %v", sanitizeForLogging(id), err)
+			continue
+		}
+		saves = append(saves, save)
+	}
+	sort.Slice(saves, func(i, j int) bool {
+		if saves[i].Name != saves[j].Name {
+			return saves[i].Name < saves[j].Name
+		}
+		return saves[i].ID < saves[j].ID
+	})
+	return saves, nil
+}
+
+// CreateSave creates an empty save.
+func CreateSave(request SaveRequest) (Save, error) {
+	name := strings.TrimSpace(request.Name)
+	description := strings.TrimSpace(request.Description)
+	switch {
+	case name == "":
+		return Save{}, apperrors.ErrSaveNameEmpty
+	case utf8.RuneCountInString(name) > maxDatasetNameLength:
+		return Save{}, apperrors.WrapErrDatasetMetadataTooLong("name", maxDatasetNameLength)
+	case utf8.RuneCountInString(description) > maxDatasetDescriptionLength:
+		return Save{}, apperrors.WrapErrDatasetMetadataTooLong("description", maxDatasetDescriptionLength)
+	}
+
+	now := time.Now().UTC()
+	save := Save{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Snapshots:   []SaveSnapshot{},
+	}
+	if err := storeSave(save); err != nil {
+		return Save{}, err
+	}
+	return save, nil
+}
+
+// DeleteSave deletes a save. Its snapshot datasets are kept.
+func DeleteSave(saveID string) error {
+	muSaves.Lock()
+	defer muSaves.Unlock()
+
+	if _, err := loadSave(saveID); err != nil {
+		return err
+	}
+	if err := storage.Delete(saveRecordID(saveID)); err != nil {
+		return err
+	}
+	unindexSave(saveID)
+	return nil
+}
+
+// AddSaveSnapshot links a dataset to a save at an in-game date. Linking a dataset the save
+// already holds moves it to the new date. A dataset belongs to at most one save.
+func AddSaveSnapshot(saveID string, request SaveSnapshotRequest) (Save, error) {
+	gameDate, err := normalizeGameDate(request.GameDate)
+	if err != nil {
+		return Save{}, err
+	}
+	if _, found := GetDatasetMetadata(request.DatasetID); !found {
+		return Save{}, apperrors.WrapErrDatasetNotFound(request.DatasetID)
+	}
+
+	muSaves.Lock()
+	defer muSaves.Unlock()
+
+	save, err := loadSave(saveID)
+	if err != nil {
+		return Save{}, err
+	}
+	holderID, err := saveIDForDataset(request.DatasetID)
+	if err != nil {
+		return Save{}, err
+	}
+	if holderID != "" && holderID != saveID {
+		return Save{}, apperrors.WrapErrDatasetInAnotherSave(request.DatasetID, holderID)
+	}
+
+	now := time.Now().UTC()
+	snapshots := make([]SaveSnapshot, 0, len(save.Snapshots)+1)
+	for _, snapshot := range save.Snapshots {
+		if snapshot.DatasetID != request.DatasetID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	snapshots = append(snapshots, SaveSnapshot{DatasetID: request.DatasetID, GameDate: gameDate, AddedAt: now})
+	sortSaveSnapshots(snapshots)
+
+	save.Snapshots = snapshots
+	save.UpdatedAt = now
+	if err := storeSave(save); err != nil {
+		return Save{}, err
+	}
+	indexSaveSnapshots(save)
+	return save, nil
+}
+
+// RemoveSaveSnapshot unlinks a dataset from a save without deleting the dataset.
+func RemoveSaveSnapshot(saveID, datasetID string) (Save, error) {
+	muSaves.Lock()
+	defer muSaves.Unlock()
+
+	save, err := loadSave(saveID)
+	if err != nil {
+		return Save{}, err
+	}
+	snapshots := make([]SaveSnapshot, 0, len(save.Snapshots))
+	for _, snapshot := range save.Snapshots {
+		if snapshot.DatasetID != datasetID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	if len(snapshots) == len(save.Snapshots) {
+		return Save{}, apperrors.WrapErrDatasetNotFound(datasetID)
+	}
+
+	save.Snapshots = snapshots
+	save.UpdatedAt = time.Now().UTC()
+	if err := storeSave(save); err != nil {
+		return Save{}, err
+	}
+	indexSaveSnapshots(save)
+	return save, nil
+}
+
+// GetLatestSaveSnapshot returns the most recent snapshot of a save whose dataset still exists.
+func GetLatestSaveSnapshot(saveID string) (LatestSaveSnapshotResponse, error) {
+	save, err := loadSave(saveID)
+	if err != nil {
+		return LatestSaveSnapshotResponse{}, err
+	}
+
+	for i := len(save.Snapshots) - 1; i >= 0; i-- {
+		snapshot := save.Snapshots[i]
+		if summary, found := getDatasetSummary(snapshot.DatasetID); found {
+			return LatestSaveSnapshotResponse{SaveID: save.ID, Snapshot: snapshot, Dataset: summary}, nil
+		}
+	}
+	return LatestSaveSnapshotResponse{}, apperrors.ErrSaveHasNoSnapshots
+}
+
+// unlinkDatasetFromSaves removes a deleted dataset from the save holding it.
+func unlinkDatasetFromSaves(datasetID string) {
+	muSaves.Lock()
+	defer muSaves.Unlock()
+
+	saveID, err := saveIDForDataset(datasetID)
+	if err != nil {
+		LogWarn("Failed to list saves while deleting dataset %s: %v", sanitizeForLogging(datasetID), err)
+		return
+	}
+	if saveID == "" {
+		return
+	}
+	save, err := loadSave(saveID)
+	if err != nil {
+		LogWarn("Failed to load save %s while deleting dataset %s: %v", saveID, sanitizeForLogging(datasetID), err)
+		return
+	}
+
+	snapshots := make([]SaveSnapshot, 0, len(save.Snapshots))
+	for _, snapshot := range save.Snapshots {
+		if snapshot.DatasetID != datasetID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	save.Snapshots = snapshots
+	save.UpdatedAt = time.Now().UTC()
+	if err := storeSave(save); err != nil {
+		LogWarn("Failed to unlink dataset %s from save %s: %v", sanitizeForLogging(datasetID), save.ID, err)
+		return
+	}
+	indexSaveSnapshots(save)
+}
+
+// saveRetentionExclusions returns the save records and snapshot datasets that automatic
+// cleanup must keep, so a save's history does not age out from under it.
+func saveRetentionExclusions() []string {
+	saves, err := ListSaves()
+	if err != nil {
+		LogWarn("Failed to list saves for cleanup: %v", err)
+		return nil
+	}
+
+	var exclusions []string
+	for i := range saves {
+		exclusions = append(exclusions, saveRecordID(saves[i].ID))
+		for _, snapshot := range saves[i].Snapshots {
+			exclusions = append(exclusions, snapshot.DatasetID)
+		}
+	}
+	return exclusions
+}
+
+// savesHandler serves the save timeline API:
+//
+//	GET    /api/saves                                lists saves
+//	POST   /api/saves                                creates a save
+//	GET    /api/saves/{saveId}                       returns a save with its snapshots
+//	DELETE /api/saves/{saveId}                       deletes the save, keeping its datasets
+//	GET    /api/saves/{saveId}/snapshots             lists snapshots in in-game order
+//	POST   /api/saves/{saveId}/snapshots             links a dataset at an in-game date
+//	DELETE /api/saves/{saveId}/snapshots/{datasetId} unlinks a dataset
+//	GET    /api/saves/{saveId}/latest                returns the most recent snapshot
+func savesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.saves")
+	defer span.End()
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/saves"), "/")
+	SetSpanAttributes(ctx, attribute.String("http.method", r.Method))
+
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			saves, err := ListSaves()
+			if err != nil {
+				RecordError(ctx, err, "Failed to list saves")
+				http.Error(w, "Failed to list saves", http.StatusInternalServerError)
+				return
+			}
+			writeDatasetsResponse(ctx, w, r, saves)
+		case http.MethodPost:
+			handleCreateSave(ctx, w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	pathParts := strings.Split(path, "/")
+	saveID := pathParts[0]
+	if err := validateID(saveID, maxDatasetIDLength); err != nil {
+		http.Error(w, "Invalid save ID", http.StatusBadRequest)
+		return
+	}
+	SetSpanAttributes(ctx, attribute.String("save.id", saveID))
+
+	switch {
+	case len(pathParts) == 1:
+		handleSave(ctx, w, r, saveID)
+	case len(pathParts) == 2 && pathParts[1] == "snapshots":
+		handleSaveSnapshots(ctx, w, r, saveID)
+	case len(pathParts) == 3 && pathParts[1] == "snapshots":
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handleRemoveSaveSnapshot(ctx, w, r, saveID, pathParts[2])
+	case len(pathParts) == 2 && pathParts[1] == "latest":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		latest, err := GetLatestSaveSnapshot(saveID)
+		if err != nil {
+			writeSaveError(ctx, w, err)
+			return
+		}
+		writeDatasetsResponse(ctx, w, r, latest)
+	default:
+		http.Error(w, "Unknown save resource", http.StatusNotFound)
+	}
+}
+
+// handleSave returns or deletes one save.
+func handleSave(ctx context.Context, w http.ResponseWriter, r *http.Request, saveID string) {
+	switch r.Method {
+	case http.MethodGet:
+		save, err := loadSave(saveID)
+		if err != nil {
+			writeSaveError(ctx, w, err)
+			return
+		}
+		writeDatasetsResponse(ctx, w, r, save)
+
+	case http.MethodDelete:
+		if err := DeleteSave(saveID); err != nil {
+			writeSaveError(ctx, w, err)
+			return
+		}
+		logInfo(ctx, "Save deleted", "save_id", saveID)
+		setCORSHeaders(w, r)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSaveSnapshots lists a save's snapshots or links a dataset to it.
+func handleSaveSnapshots(ctx context.Context, w http.ResponseWriter, r *http.Request, saveID string) {
+	switch r.Method {
+	case http.MethodGet:
+		save, err := loadSave(saveID)
+		if err != nil {
+			writeSaveError(ctx, w, err)
+			return
+		}
+		writeDatasetsResponse(ctx, w, r, save.Snapshots)
+
+	case http.MethodPost:
+		handleAddSaveSnapshot(ctx, w, r, saveID)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRemoveSaveSnapshot unlinks a dataset from a save.
+func handleRemoveSaveSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, saveID, datasetID string) {
+	if err := validateID(datasetID, maxDatasetIDLength); err != nil {
+		http.Error(w, "Invalid dataset ID", http.StatusBadRequest)
+		return
+	}
+
+	save, err := RemoveSaveSnapshot(saveID, datasetID)
+	if err != nil {
+		writeSaveError(ctx, w, err)
+		return
+	}
+
+	logInfo(ctx, "Snapshot removed from save", "save_id", saveID, "dataset_id", datasetID)
+	writeDatasetsResponse(ctx, w, r, save)
+}
+
+// handleCreateSave creates a save from the request body.
+func handleCreateSave(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var request SaveRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDatasetMetadataBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		http.Error(w, "Invalid save: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	save, err := CreateSave(request)
+	if err != nil {
+		writeSaveError(ctx, w, err)
+		return
+	}
+
+	logInfo(ctx, "Save created", "save_id", save.ID)
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(save); err != nil {
+		logError(ctx, "Error encoding save response", "error", err)
+	}
+}
+
+// handleAddSaveSnapshot links the dataset in the request body to a save.
+func handleAddSaveSnapshot(ctx context.Context, w http.ResponseWriter, r *http.Request, saveID string) {
+	var request SaveSnapshotRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDatasetMetadataBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		http.Error(w, "Invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateID(request.DatasetID, maxDatasetIDLength); err != nil || isReservedDatasetID(request.DatasetID) {
+		http.Error(w, "Invalid dataset ID", http.StatusBadRequest)
+		return
+	}
+
+	save, err := AddSaveSnapshot(saveID, request)
+	if err != nil {
+		writeSaveError(ctx, w, err)
+		return
+	}
+
+	logInfo(ctx, "Snapshot added to save", "save_id", saveID, "dataset_id", request.DatasetID, "game_date", request.GameDate)
+	writeDatasetsResponse(ctx, w, r, save)
+}
+
+// writeSaveError maps a save timeline error to its HTTP status.
+func writeSaveError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrSaveNotFound):
+		http.Error(w, "Save not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrDatasetNotFound):
+		http.Error(w, "Dataset not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrSaveHasNoSnapshots):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrDatasetInAnotherSave):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, apperrors.ErrSaveNameEmpty),
+		errors.Is(err, apperrors.ErrInvalidGameDate),
+		errors.Is(err, apperrors.ErrDatasetMetadataTooLong):
+		http.Error(w, "Invalid save: "+err.Error(), http.StatusBadRequest)
+	default:
+		RecordError(ctx, err, "Failed to update save")
+		http.Error(w, "Failed to update save", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func requestSaves(t *testing.T, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	savesHandler(w, req)
+	return w
+}
+
+func TestNormalizeGameDate(t *testing.T) {
+	if got, err := normalizeGameDate(" 2026-01-31 "); err != nil || got != "2026-01-31" {
+		t.Errorf("normalizeGameDate = %q, %v", got, err)
+	}
+	for _, invalid := range []string{"", "31/01/2026", "2026-02-30", "2026-1-5"} {
+		if _, err := normalizeGameDate(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSaveTimeline(t *testing.T) {
+	InitStore()
+
+	datasetIDs := []string{"save-test-aug", "save-test-jan", "save-test-jul"}
+	for _, id := range datasetIDs {
+		if err := storeDatasetData(id, DatasetData{
+			Players:        []Player{{UID: 1, Name: "Timeline Player", Club: "Timeline FC"}},
+			CurrencySymbol: "£",
+			Metadata:       &DatasetMetadata{Name: id, PlayerCount: 1},
+		}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		t.Cleanup(func() { _ = DeleteDataset(id) })
+	}
+
+	w := requestSaves(t, http.MethodPost, "/api/saves", `{"name": "Career save"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var save Save
+	if err := json.Unmarshal(w.Body.Bytes(), &save); err != nil {
+		t.Fatalf("Failed to parse save: %v", err)
+	}
+	t.Cleanup(func() { _ = DeleteSave(save.ID) })
+
+	if w := requestSaves(t, http.MethodGet, "/api/saves/"+save.ID+"/latest", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an empty save, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Linked out of order; the timeline follows the in-game dates
+	for id, gameDate := range map[string]string{
+		"save-test-aug": "2026-08-31",
+		"save-test-jan": "2026-01-31",
+		"save-test-jul": "2026-07-01",
+	} {
+		body := `{"datasetId": "` + id + `", "gameDate": "` + gameDate + `"}`
+		if w := requestSaves(t, http.MethodPost, "/api/saves/"+save.ID+"/snapshots", body); w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d linking %s, got %d: %s", http.StatusOK, id, w.Code, w.Body.String())
+		}
+	}
+
+	w = requestSaves(t, http.MethodGet, "/api/saves/"+save.ID+"/snapshots", "")
+	var snapshots []SaveSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshots); err != nil {
+		t.Fatalf("Failed to parse snapshots: %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].DatasetID != "save-test-jan" ||
+		snapshots[1].DatasetID != "save-test-jul" || snapshots[2].DatasetID != "save-test-aug" {
+		t.Errorf("Snapshots not in in-game order: %+v", snapshots)
+	}
+
+	w = requestSaves(t, http.MethodGet, "/api/saves/"+save.ID+"/latest", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var latest LatestSaveSnapshotResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &latest); err != nil {
+		t.Fatalf("Failed to parse latest snapshot: %v", err)
+	}
+	if latest.Snapshot.DatasetID != "save-test-aug" || latest.Dataset.PlayerCount != 1 {
+		t.Errorf("Unexpected latest snapshot: %+v", latest)
+	}
+
+	// Deleting the latest dataset falls back to the previous snapshot
+	if err := DeleteDataset("save-test-aug"); err != nil {
+		t.Fatalf("Failed to delete dataset: %v", err)
+	}
+	if latest, err := GetLatestSaveSnapshot(save.ID); err != nil || latest.Snapshot.DatasetID != "save-test-jul" {
+		t.Errorf("GetLatestSaveSnapshot after deletion = %+v, %v", latest.Snapshot, err)
+	}
+	if stored, _ := loadSave(save.ID); len(stored.Snapshots) != 2 {
+		t.Errorf("Deleted dataset still linked: %+v", stored.Snapshots)
+	}
+	if exclusions := strings.Join(saveRetentionExclusions(), ","); !strings.Contains(exclusions, "save-test-jan") ||
+		!strings.Contains(exclusions, saveRecordID(save.ID)) {
+		t.Errorf("Cleanup exclusions miss the save: %s", exclusions)
+	}
+
+	other, err := CreateSave(SaveRequest{Name: "Other save"})
+	if err != nil {
+		t.Fatalf("Failed to create save: %v", err)
+	}
+	t.Cleanup(func() { _ = DeleteSave(other.ID) })
+	body := `{"datasetId": "save-test-jan", "gameDate": "2027-01-31"}`
+	if w := requestSaves(t, http.MethodPost, "/api/saves/"+other.ID+"/snapshots", body); w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d linking a dataset of another save, got %d", http.StatusConflict, w.Code)
+	}
+	body = `{"datasetId": "save-test-jan", "gameDate": "January 2027"}`
+	if w := requestSaves(t, http.MethodPost, "/api/saves/"+save.ID+"/snapshots", body); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid date, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if w := requestSaves(t, http.MethodDelete, "/api/saves/"+save.ID+"/snapshots/save-test-jul", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d unlinking a snapshot, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if _, _, found := GetPlayerData("save-test-jul"); !found {
+		t.Error("Unlinking a snapshot deleted its dataset")
+	}
+
+	if w := requestDatasets(t, http.MethodGet, "/api/datasets/"+saveRecordID(save.ID)); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d reading a save record as a dataset, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := requestSaves(t, http.MethodDelete, "/api/saves/"+save.ID, ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d deleting the save, got %d", http.StatusNoContent, w.Code)
+	}
+	if w := requestSaves(t, http.MethodGet, "/api/saves/"+save.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d after deletion, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := requestSaves(t, http.MethodPost, "/api/saves", `{"name": "  "}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty name, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// saveReadCountingStorage is a MockStorage that counts listings and save record reads.
+type saveReadCountingStorage struct {
+	*MockStorage
+	lists, saveReads int
+}
+
+func (s *saveReadCountingStorage) List() ([]string, error) {
+	s.lists++
+	return s.MockStorage.List()
+}
+
+func (s *saveReadCountingStorage) Retrieve(datasetID string) (DatasetData, error) {
+	if strings.HasPrefix(datasetID, saveRecordPrefix) {
+		s.saveReads++
+	}
+	return s.MockStorage.Retrieve(datasetID)
+}
+
+func TestSaveIndexAvoidsScanningSaves(t *testing.T) {
+	originalStorage := storage
+	defer func() { storage = originalStorage }()
+	counting := &saveReadCountingStorage{MockStorage: CreateMockStorage()}
+	storage = counting
+	resetDatasetSaveIndex()
+	defer resetDatasetSaveIndex()
+
+	for _, id := range []string{"index-test-linked", "index-test-free"} {
+		if err := storeDatasetData(id, DatasetData{Players: []Player{{UID: 1, Name: "Indexed"}}, CurrencySymbol: "£"}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		defer forgetDatasetDetails(id)
+	}
+	saves := make([]Save, 3)
+	for i := range saves {
+		save, err := CreateSave(SaveRequest{Name: "Indexed save"})
+		if err != nil {
+			t.Fatalf("Failed to create save: %v", err)
+		}
+		saves[i] = save
+	}
+	if _, err := AddSaveSnapshot(saves[1].ID, SaveSnapshotRequest{DatasetID: "index-test-linked", GameDate: "2026-01-31"}); err != nil {
+		t.Fatalf("Failed to link dataset: %v", err)
+	}
+
+	counting.lists, counting.saveReads = 0, 0
+	if err := DeleteDataset("index-test-free"); err != nil {
+		t.Fatalf("Failed to delete dataset: %v", err)
+	}
+	if counting.lists != 0 || counting.saveReads != 0 {
+		t.Errorf("Deleting an unlinked dataset listed storage %d times and read %d saves", counting.lists, counting.saveReads)
+	}
+
+	if err := DeleteDataset("index-test-linked"); err != nil {
+		t.Fatalf("Failed to delete dataset: %v", err)
+	}
+	if counting.lists != 0 || counting.saveReads > 2 {
+		t.Errorf("Deleting a linked dataset listed storage %d times and read %d saves", counting.lists, counting.saveReads)
+	}
+	if stored, _ := loadSave(saves[1].ID); len(stored.Snapshots) != 0 {
+		t.Errorf("Deleted dataset still linked: %+v", stored.Snapshots)
+	}
+
+	// An index entry whose save record was changed elsewhere is noticed when it is used
+	if err := storeDatasetData("index-test-moved", DatasetData{Players: []Player{{UID: 1, Name: "Indexed"}}, CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Failed to store dataset: %v", err)
+	}
+	defer forgetDatasetDetails("index-test-moved")
+	if _, err := AddSaveSnapshot(saves[2].ID, SaveSnapshotRequest{DatasetID: "index-test-moved", GameDate: "2026-01-31"}); err != nil {
+		t.Fatalf("Failed to link dataset: %v", err)
+	}
+	if err := storeSave(Save{ID: saves[2].ID, Name: "Indexed save", Snapshots: []SaveSnapshot{}}); err != nil {
+		t.Fatalf("Failed to store save: %v", err)
+	}
+	if _, err := AddSaveSnapshot(saves[0].ID, SaveSnapshotRequest{DatasetID: "index-test-moved", GameDate: "2026-01-31"}); err != nil {
+		t.Errorf("Expected a dataset unlinked elsewhere to be linkable, got %v", err)
+	}
+}
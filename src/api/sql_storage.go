@@ -0,0 +1,467 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "api/errors"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, registered as "sqlite"
+)
+
+// sqlStorageSchema normalizes datasets into a player table with one indexed column per
+// filterable field and an attribute table with one row per player attribute. The details
+// column keeps each player's full JSON so Retrieve returns exactly what was stored.
+const sqlStorageSchema = `
+CREATE TABLE IF NOT EXISTS datasets (
+	id              TEXT PRIMARY KEY,
+	currency_symbol TEXT NOT NULL DEFAULT '',
+	base_currency   TEXT NOT NULL DEFAULT '',
+	export_profile  TEXT NOT NULL DEFAULT '',
+	metadata        TEXT NOT NULL DEFAULT '',
+	cache_data      TEXT NOT NULL DEFAULT '',
+	player_count    INTEGER NOT NULL DEFAULT 0,
+	stored_at       INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS players (
+	dataset_id      TEXT NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+	row_index       INTEGER NOT NULL,
+	uid             INTEGER NOT NULL,
+	name            TEXT NOT NULL,
+	club            TEXT NOT NULL,
+	division        TEXT NOT NULL,
+	nationality     TEXT NOT NULL,
+	position_groups TEXT NOT NULL,
+	age             INTEGER,
+	overall         INTEGER NOT NULL,
+	transfer_value  INTEGER NOT NULL,
+	wage            INTEGER NOT NULL,
+	details         TEXT NOT NULL,
+	PRIMARY KEY (dataset_id, row_index)
+);
+CREATE INDEX IF NOT EXISTS idx_players_uid ON players(dataset_id, uid);
+CREATE INDEX IF NOT EXISTS idx_players_club ON players(dataset_id, club);
+CREATE INDEX IF NOT EXISTS idx_players_division ON players(dataset_id, division);
+CREATE INDEX IF NOT EXISTS idx_players_age ON players(dataset_id, age);
+CREATE INDEX IF NOT EXISTS idx_players_overall ON players(dataset_id, overall);
+CREATE INDEX IF NOT EXISTS idx_players_transfer_value ON players(dataset_id, transfer_value);
+
+CREATE TABLE IF NOT EXISTS player_attributes (
+	dataset_id TEXT NOT NULL,
+	row_index  INTEGER NOT NULL,
+	attribute  TEXT NOT NULL,
+	value      INTEGER NOT NULL,
+	PRIMARY KEY (dataset_id, row_index, attribute),
+	FOREIGN KEY (dataset_id, row_index) REFERENCES players(dataset_id, row_index) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_player_attributes_value ON player_attributes(dataset_id, attribute, value);
+`
+
+
+// SQLStorage stores datasets in an embedded SQLite database
+type SQLStorage struct {
+	db *sql.DB
+}
+
+// CreateSQLStorage opens or creates the SQLite database at path and its schema
+func CreateSQLStorage(path string) (*SQLStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids busy errors between our own writes
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqlStorageSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create database schema: %w", err)
+	}
+	return &SQLStorage{db: db}, nil
+}
+
+// Close closes the database
+func (s *SQLStorage) Close() error {
+	return s.db.Close()
+}
+
+// sqlPlayerAge returns the age stored in the player table, or nil when it is unknown.
+func sqlPlayerAge(player *Player) interface{} {
+	if player.AgeYears > 0 {
+		return player.AgeYears
+	}
+	if age, err := strconv.Atoi(strings.TrimSpace(player.Age)); err == nil {
+		return age
+	}
+	return nil
+}
+
+// sqlStoredPlayer is a player row as stored, read back so Store only writes what changed.
+type sqlStoredPlayer struct {
+	details    string
+	attributes map[string]int
+}
+
+// Store saves a dataset, replacing any dataset stored under the same ID. Players are matched
+// to the stored rows by position: unchanged rows are left alone, changed rows are updated in
+// place and their attribute rows are only rewritten when the attributes changed.
+func (s *SQLStorage) Store(datasetID string, data DatasetData) error {
+	if err := validateID(datasetID, maxDatasetIDLength); err != nil {
+		return fmt.Errorf("invalid dataset ID: %w", err)
+	}
+
+	var metadata []byte
+	if data.Metadata != nil {
+		var err error
+		if metadata, err = json.Marshal(data.Metadata); err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`INSERT INTO datasets (id, currency_symbol, base_currency, export_profile, metadata, cache_data, player_count, stored_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET currency_symbol = excluded.currency_symbol, base_currency = excluded.base_currency,
+			export_profile = excluded.export_profile, metadata = excluded.metadata, cache_data = excluded.cache_data,
+			player_count = excluded.player_count, stored_at = excluded.stored_at`,
+		datasetID, data.CurrencySymbol, data.BaseCurrency, data.ExportProfile, string(metadata), data.CacheData,
+		len(data.Players), time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to store dataset: %w", err)
+	}
+
+	stored, err := readStoredPlayers(tx, datasetID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM players WHERE dataset_id = ? AND row_index >= ?`, datasetID, len(data.Players)); err != nil {
+		return fmt.Errorf("failed to remove dropped players: %w", err)
+	}
+
+	insertPlayer, err := tx.Prepare(`INSERT INTO players (dataset_id, row_index, uid, name, club, division, nationality,
+		position_groups, age, overall, transfer_value, wage, details) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare player insert: %w", err)
+	}
+	defer func() { _ = insertPlayer.Close() }()
+	updatePlayer, err := tx.Prepare(`UPDATE players SET uid = ?, name = ?, club = ?, division = ?, nationality = ?,
+		position_groups = ?, age = ?, overall = ?, transfer_value = ?, wage = ?, details = ? WHERE dataset_id = ? AND row_index = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare player update: %w", err)
+	}
+	defer func() { _ = updatePlayer.Close() }()
+	insertAttribute, err := tx.Prepare(`INSERT INTO player_attributes (dataset_id, row_index, attribute, value) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare attribute insert: %w", err)
+	}
+	defer func() { _ = insertAttribute.Close() }()
+	deleteAttributes, err := tx.Prepare(`DELETE FROM player_attributes WHERE dataset_id = ? AND row_index = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare attribute delete: %w", err)
+	}
+	defer func() { _ = deleteAttributes.Close() }()
+
+	for i := range data.Players {
+		player := &data.Players[i]
+		details, err := json.Marshal(player)
+		if err != nil {
+			return fmt.Errorf("failed to marshal player %d: %w", player.UID, err)
+		}
+		previous, exists := stored[i]
+		if exists && previous.details == string(details) {
+			continue
+		}
+
+		// Groups are wrapped in commas so a group can be matched with LIKE '%,Defenders,%'
+		positionGroups := "," + strings.Join(player.PositionGroups, ",") + ","
+		columns := []interface{}{player.UID, player.Name, player.Club, player.Division, player.Nationality,
+			positionGroups, sqlPlayerAge(player), player.Overall, playerTransferValueEstimate(player),
+			player.WageAmount, string(details)}
+		if exists {
+			if _, err := updatePlayer.Exec(append(columns, datasetID, i)...); err != nil {
+				return fmt.Errorf("failed to update player %d: %w", player.UID, err)
+			}
+			if maps.Equal(previous.attributes, player.NumericAttributes) {
+				continue
+			}
+			if _, err := deleteAttributes.Exec(datasetID, i); err != nil {
+				return fmt.Errorf("failed to replace attributes of player %d: %w", player.UID, err)
+			}
+		} else if _, err := insertPlayer.Exec(append([]interface{}{datasetID, i}, columns...)...); err != nil {
+			return fmt.Errorf("failed to insert player %d: %w", player.UID, err)
+		}
+		for attribute, value := range player.NumericAttributes {
+			if _, err := insertAttribute.Exec(datasetID, i, attribute, value); err != nil {
+				return fmt.Errorf("failed to insert attribute %s of player %d: %w", attribute, player.UID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit dataset: %w", err)
+	}
+	return nil
+}
+
+// readStoredPlayers returns the stored player rows of a dataset by row index.
+func readStoredPlayers(tx *sql.Tx, datasetID string) (map[int]sqlStoredPlayer, error) {
+	rows, err := tx.Query(`SELECT row_index, details FROM players WHERE dataset_id = ?`, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stored players: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	stored := make(map[int]sqlStoredPlayer)
+	for rows.Next() {
+		var index int
+		var details string
+		if err := rows.Scan(&index, &details); err != nil {
+			return nil, fmt.Errorf("failed to read stored players: %w", err)
+		}
+		// The attribute rows mirror the attributes in details, so they need not be read
+		var player struct {
+			NumericAttributes map[string]int `json:"numericAttributes"`
+		}
+		if err := json.Unmarshal([]byte(details), &player); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored player: %w", err)
+		}
+		stored[index] = sqlStoredPlayer{details: details, attributes: player.NumericAttributes}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stored players: %w", err)
+	}
+	return stored, nil
+}
+
+// Retrieve retrieves a dataset with its players in their stored order
+func (s *SQLStorage) Retrieve(datasetID string) (DatasetData, error) {
+	var data DatasetData
+	var metadata string
+	err := s.db.QueryRow(`SELECT currency_symbol, base_currency, export_profile, metadata, cache_data FROM datasets WHERE id = ?`,
+		datasetID).Scan(&data.CurrencySymbol, &data.BaseCurrency, &data.ExportProfile, &metadata, &data.CacheData)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DatasetData{}, apperrors.WrapErrDatasetNotFound(sanitizeForLogging(datasetID))
+	}
+	if err != nil {
+		return DatasetData{}, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	if metadata != "" {
+		data.Metadata = &DatasetMetadata{}
+		if err := json.Unmarshal([]byte(metadata), data.Metadata); err != nil {
+			return DatasetData{}, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	data.Players, err = s.queryPlayerDetails(`SELECT details FROM players WHERE dataset_id = ? ORDER BY row_index`, datasetID)
+	if err != nil {
+		return DatasetData{}, err
+	}
+	return data, nil
+}
+
+// queryPlayerDetails runs a query selecting the details column and decodes the players.
+func (s *SQLStorage) queryPlayerDetails(query string, args ...interface{}) ([]Player, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query players: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	players := make([]Player, 0)
+	for rows.Next() {
+		var details string
+		if err := rows.Scan(&details); err != nil {
+			return nil, fmt.Errorf("failed to read player: %w", err)
+		}
+		var player Player
+		if err := json.Unmarshal([]byte(details), &player); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal player: %w", err)
+		}
+		players = append(players, player)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query players: %w", err)
+	}
+	return players, nil
+}
+
+// Delete removes a dataset with its players and attributes
+func (s *SQLStorage) Delete(datasetID string) error {
+	if _, err := s.db.Exec(`DELETE FROM datasets WHERE id = ?`, datasetID); err != nil {
+		return fmt.Errorf("failed to delete dataset: %w", err)
+	}
+	return nil
+}
+
+// List returns all dataset IDs stored in the database
+func (s *SQLStorage) List() ([]string, error) {
+	rows, err := s.db.Query(`SELECT id FROM datasets ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list datasets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to list datasets: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DatasetSize returns the bytes of a dataset's stored player records
+func (s *SQLStorage) DatasetSize(datasetID string) (int64, error) {
+	var size sql.NullInt64
+	err := s.db.QueryRow(`SELECT LENGTH(d.metadata) + LENGTH(d.cache_data) +
+		(SELECT COALESCE(SUM(LENGTH(p.details)), 0) FROM players p WHERE p.dataset_id = d.id)
+		FROM datasets d WHERE d.id = ?`, datasetID).Scan(&size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, apperrors.WrapErrDatasetNotFound(sanitizeForLogging(datasetID))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dataset size: %w", err)
+	}
+	return size.Int64, nil
+}
+
+// CleanupOldDatasets removes datasets stored longer ago than maxAge
+func (s *SQLStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
+	rows, err := s.db.Query(`SELECT id FROM datasets WHERE stored_at < ?`, time.Now().Add(-maxAge).Unix())
+	if err != nil {
+		return fmt.Errorf("failed to find old datasets: %w", err)
+	}
+	var expired []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to find old datasets: %w", err)
+		}
+		expired = append(expired, id)
+	}
+	_ = rows.Close()
+
+	excludeSet := make(map[string]bool, len(excludeDatasets))
+	for _, dataset := range excludeDatasets {
+		excludeSet[dataset] = true
+	}
+
+	var deletedCount int
+	for _, id := range expired {
+		if excludeSet[id] {
+			continue
+		}
+		if err := s.Delete(id); err != nil {
+			LogWarn("Failed to delete old dataset %s: %v", sanitizeForLogging(id), err)
+			continue
+		}
+		deletedCount++
+	}
+	if deletedCount > 0 {
+		LogDebug("Cleaned up %d old datasets from SQL storage", deletedCount)
+	}
+	return nil
+}
+
+// QueryPlayers returns the players matching query, filtered and sorted by indexed queries
+func (s *SQLStorage) QueryPlayers(datasetID string, query PlayerQuery) ([]Player, error) {
+	// The sort join's placeholder precedes the WHERE placeholders in the statement, so its
+	// argument is collected separately
+	var join string
+	var joinArgs []interface{}
+	conditions := []string{"p.dataset_id = ?"}
+	conditionArgs := []interface{}{datasetID}
+	addCondition := func(condition string, value interface{}) {
+		conditions = append(conditions, condition)
+		conditionArgs = append(conditionArgs, value)
+	}
+
+	if len(query.UIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.UIDs)), ", ")
+		conditions = append(conditions, "p.uid IN ("+placeholders+")")
+		for _, uid := range query.UIDs {
+			conditionArgs = append(conditionArgs, uid)
+		}
+	}
+	if query.MinAge > 0 {
+		addCondition("p.age >= ?", query.MinAge)
+	}
+	if query.MaxAge > 0 {
+		addCondition("p.age <= ?", query.MaxAge)
+	}
+	if query.MinTransferValue > 0 {
+		addCondition("p.transfer_value >= ?", query.MinTransferValue)
+	}
+	if query.MaxTransferValue > 0 {
+		addCondition("p.transfer_value <= ?", query.MaxTransferValue)
+	}
+	if query.MaxWage > 0 {
+		addCondition("p.wage <= ?", query.MaxWage)
+	}
+
+	orderBy := "p.row_index"
+	if query.SortBy != "" {
+		direction := "ASC"
+		if query.Descending {
+			direction = "DESC"
+		}
+		// Ties and missing values are ordered as playerSort orders them: missing values last in
+		// either direction, then by UID and name
+		join = " LEFT JOIN player_attributes s ON s.dataset_id = p.dataset_id AND s.row_index = p.row_index AND s.attribute = ?"
+		joinArgs = append(joinArgs, query.SortBy)
+		orderBy = "s.value IS NULL, s.value " + direction + ", p.uid, p.name, p.row_index"
+	}
+
+	statement := "SELECT p.details FROM players p" + join +
+		" WHERE " + strings.Join(conditions, " AND ") + " ORDER BY " + orderBy
+	return s.queryPlayerDetails(statement, append(joinArgs, conditionArgs...)...)
+}
+
+// HasAttribute reports whether any player of a dataset has an attribute.
+func (s *SQLStorage) HasAttribute(datasetID, attribute string) (bool, error) {
+	var found bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM player_attributes WHERE dataset_id = ? AND attribute = ?)`,
+		datasetID, attribute).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up attribute %s: %w", attribute, err)
+	}
+	return found, nil
+}
+
+// PlayerByUID returns one player of a dataset by UID. UIDs shared by several players are an
+// ErrAmbiguousPlayer error, as findPlayer reports them.
+func (s *SQLStorage) PlayerByUID(datasetID string, uid int64) (Player, error) {
+	players, err := s.queryPlayerDetails(`SELECT details FROM players WHERE dataset_id = ? AND uid = ? ORDER BY row_index`,
+		datasetID, uid)
+	if err != nil {
+		return Player{}, err
+	}
+	switch len(players) {
+	case 0:
+		return Player{}, apperrors.WrapErrPlayerNotFound(uid)
+	case 1:
+		return players[0], nil
+	default:
+		return Player{}, apperrors.WrapErrAmbiguousPlayer(fmt.Sprintf("UID %d", uid), len(players))
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "api/errors"
+)
+
+func createTestSQLStorage(t *testing.T) *SQLStorage {
+	t.Helper()
+	storage, err := CreateSQLStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create SQL storage: %v", err)
+	}
+	t.Cleanup(func() { _ = storage.Close() })
+	return storage
+}
+
+func sqlTestPlayers() []Player {
+	return []Player{
+		{
+			UID: 10, Name: "Keeper", Club: "North FC", Division: "League One", Age: "31", Overall: 70,
+			PositionGroups: []string{"Goalkeepers"}, TransferValueAmount: 500000, WageAmount: 4000,
+			NumericAttributes: map[string]int{"Ref": 15, "Han": 14, "Pac": 6},
+		},
+		{
+			UID: 20, Name: "Winger", Club: "North FC", Division: "League One", Age: "19", Overall: 74,
+			PositionGroups: []string{"Midfielders", "Attackers"}, TransferValueAmount: 2000000, WageAmount: 6000,
+			NumericAttributes:      map[string]int{"Pac": 17, "Dri": 15, "Fin": 11},
+			RoleSpecificOveralls:   []RoleOverallScore{{RoleName: "Winger - Attack", Score: 76}},
+			PerformancePercentiles: map[string]map[string]float64{"Attackers": {"Goals": 88}},
+		},
+		{
+			UID: 30, Name: "Striker", Club: "South FC", Division: "League One", Age: "24", Overall: 78,
+			PositionGroups: []string{"Attackers"}, TransferValueAmount: 4000000, WageAmount: 9000,
+			NumericAttributes: map[string]int{"Pac": 14, "Dri": 12, "Fin": 16},
+		},
+	}
+}
+
+func TestSQLStorageRoundTrip(t *testing.T) {
+	storage := createTestSQLStorage(t)
+
+	data := DatasetData{
+		Players:        sqlTestPlayers(),
+		CurrencySymbol: "£",
+		BaseCurrency:   "GBP",
+		ExportProfile:  "fm24-attributes",
+		Metadata:       &DatasetMetadata{Name: "League One", PlayerCount: 3, UploadedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := storage.Store("sql-round-trip", data); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	// Storing again replaces the dataset rather than appending to it
+	if err := storage.Store("sql-round-trip", data); err != nil {
+		t.Fatalf("Second store failed: %v", err)
+	}
+
+	retrieved, err := storage.Retrieve("sql-round-trip")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if !reflect.DeepEqual(retrieved, data) {
+		t.Errorf("Round trip changed the dataset:\ngot  %+v\nwant %+v", retrieved, data)
+	}
+
+	if err := storage.Store("cache_sql_test", DatasetData{Players: []Player{}, CacheData: `{"k":1}`}); err != nil {
+		t.Fatalf("Failed to store cache entry: %v", err)
+	}
+	if cached, err := storage.Retrieve("cache_sql_test"); err != nil || cached.CacheData != `{"k":1}` {
+		t.Errorf("Cache entry round trip = %+v, %v", cached, err)
+	}
+
+	ids, err := storage.List()
+	if err != nil || !reflect.DeepEqual(ids, []string{"cache_sql_test", "sql-round-trip"}) {
+		t.Errorf("List = %v, %v", ids, err)
+	}
+	if size, err := datasetSize(storage, "sql-round-trip"); err != nil || size <= 0 {
+		t.Errorf("datasetSize = %d, %v", size, err)
+	}
+
+	if err := storage.Delete("sql-round-trip"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := storage.Retrieve("sql-round-trip"); !errors.Is(err, apperrors.ErrDatasetNotFound) {
+		t.Errorf("Expected ErrDatasetNotFound after deletion, got %v", err)
+	}
+	var orphans int
+	if err := storage.db.QueryRow(`SELECT (SELECT COUNT(*) FROM players) + (SELECT COUNT(*) FROM player_attributes)`).Scan(&orphans); err != nil || orphans != 0 {
+		t.Errorf("Deletion left %d player and attribute rows (%v)", orphans, err)
+	}
+}
+
+func TestSQLStorageQueries(t *testing.T) {
+	storage := createTestSQLStorage(t)
+	if err := storage.Store("sql-queries", DatasetData{Players: sqlTestPlayers(), CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	names := func(players []Player) []string {
+		result := make([]string, len(players))
+		for i := range players {
+			result[i] = players[i].Name
+		}
+		return result
+	}
+	for _, tc := range []struct {
+		name  string
+		query PlayerQuery
+		want  []string
+	}{
+		{"uids", PlayerQuery{UIDs: []int64{30, 10, 99}}, []string{"Keeper", "Striker"}},
+		{"age and value", PlayerQuery{MaxAge: 25, MinTransferValue: 1000000}, []string{"Winger", "Striker"}},
+		{"wage", PlayerQuery{MaxWage: 6000}, []string{"Keeper", "Winger"}},
+		{"attribute sort", PlayerQuery{SortBy: "Pac", Descending: true}, []string{"Winger", "Striker", "Keeper"}},
+		{"missing attribute last", PlayerQuery{SortBy: "Fin"}, []string{"Winger", "Striker", "Keeper"}},
+		{"missing attribute last descending", PlayerQuery{SortBy: "Ref", Descending: true}, []string{"Keeper", "Winger", "Striker"}},
+	} {
+		players, err := storage.QueryPlayers("sql-queries", tc.query)
+		if err != nil {
+			t.Fatalf("%s: QueryPlayers failed: %v", tc.name, err)
+		}
+		if got := names(players); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if player, err := storage.PlayerByUID("sql-queries", 20); err != nil || player.Name != "Winger" {
+		t.Errorf("PlayerByUID = %+v, %v", player, err)
+	}
+	if _, err := storage.PlayerByUID("sql-queries", 99); !errors.Is(err, apperrors.ErrPlayerNotFound) {
+		t.Errorf("Expected ErrPlayerNotFound, got %v", err)
+	}
+	if found, err := storage.HasAttribute("sql-queries", "Ref"); err != nil || !found {
+		t.Errorf("HasAttribute(Ref) = %v, %v", found, err)
+	}
+	if found, err := storage.HasAttribute("sql-queries", "Han "); err != nil || found {
+		t.Errorf("HasAttribute(\"Han \") = %v, %v", found, err)
+	}
+
+	duplicated := append(sqlTestPlayers(), Player{UID: 20, Name: "Namesake"})
+	if err := storage.Store("sql-queries", DatasetData{Players: duplicated, CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if _, err := storage.PlayerByUID("sql-queries", 20); !errors.Is(err, apperrors.ErrAmbiguousPlayer) {
+		t.Errorf("Expected ErrAmbiguousPlayer for a shared UID, got %v", err)
+	}
+}
+
+func TestSQLStorageStoreUpdatesChangedRows(t *testing.T) {
+	storage := createTestSQLStorage(t)
+	players := sqlTestPlayers()
+	if err := storage.Store("sql-incremental", DatasetData{Players: players, CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	attributeRowIDs := func() map[string]int64 {
+		rows, err := storage.db.Query(`SELECT row_index, attribute, rowid FROM player_attributes WHERE dataset_id = ?`, "sql-incremental")
+		if err != nil {
+			t.Fatalf("Failed to read attribute rows: %v", err)
+		}
+		defer func() { _ = rows.Close() }()
+		ids := make(map[string]int64)
+		for rows.Next() {
+			var index int
+			var attribute string
+			var rowID int64
+			if err := rows.Scan(&index, &attribute, &rowID); err != nil {
+				t.Fatalf("Failed to read attribute rows: %v", err)
+			}
+			ids[fmt.Sprintf("%d:%s", index, attribute)] = rowID
+		}
+		return ids
+	}
+	before := attributeRowIDs()
+
+	// The keeper only gains percentiles, the winger's pace changes and the striker is dropped
+	updated := sqlTestPlayers()[:2]
+	updated[0].PerformancePercentiles = map[string]map[string]float64{"Global": {"Saves": 90}}
+	updated[1].NumericAttributes = map[string]int{"Pac": 18, "Dri": 15, "Fin": 11}
+	if err := storage.Store("sql-incremental", DatasetData{Players: updated, CurrencySymbol: "£"}); err != nil {
+		t.Fatalf("Second store failed: %v", err)
+	}
+
+	retrieved, err := storage.Retrieve("sql-incremental")
+	if err != nil || !reflect.DeepEqual(retrieved.Players, updated) {
+		t.Errorf("Retrieve after an update = %+v, %v", retrieved.Players, err)
+	}
+	after := attributeRowIDs()
+	if after["0:Ref"] != before["0:Ref"] {
+		t.Error("Attribute rows of a player with unchanged attributes were rewritten")
+	}
+	if _, found := after["2:Fin"]; found || len(after) != 6 {
+		t.Errorf("Expected the dropped player's attribute rows to be removed, got %v", after)
+	}
+	var pace int
+	if err := storage.db.QueryRow(`SELECT value FROM player_attributes WHERE dataset_id = ? AND row_index = 1 AND attribute = 'Pac'`,
+		"sql-incremental").Scan(&pace); err != nil || pace != 18 {
+		t.Errorf("Expected the changed attribute row to hold 18, got %d, %v", pace, err)
+	}
+}
+
+func TestSQLStorageCleanup(t *testing.T) {
+	storage := createTestSQLStorage(t)
+	for _, id := range []string{"sql-old", "sql-kept"} {
+		if err := storage.Store(id, DatasetData{Players: sqlTestPlayers()[:1], CurrencySymbol: "£"}); err != nil {
+			t.Fatalf("Store failed: %v", err)
+		}
+	}
+	if _, err := storage.db.Exec(`UPDATE datasets SET stored_at = ?`, time.Now().Add(-48*time.Hour).Unix()); err != nil {
+		t.Fatalf("Failed to age datasets: %v", err)
+	}
+
+	if err := storage.CleanupOldDatasets(24*time.Hour, []string{"sql-kept"}); err != nil {
+		t.Fatalf("CleanupOldDatasets failed: %v", err)
+	}
+	if ids, _ := storage.List(); !reflect.DeepEqual(ids, []string{"sql-kept"}) {
+		t.Errorf("Expected only the excluded dataset to remain, got %v", ids)
+	}
+}
+
+func TestInitializeBaseStorageSQL(t *testing.T) {
+	t.Setenv("STORAGE_BACKEND", "sqlite")
+	t.Setenv("SQL_STORAGE_PATH", filepath.Join(t.TempDir(), "fmdash.db"))
+	t.Setenv("USE_PROTOBUF", "true")
+
+	backend := InitializeStorage(context.Background())
+	sqlStorage, ok := backend.(*SQLStorage)
+	if !ok {
+		t.Fatalf("Expected SQL storage without a protobuf wrapper, got %T", backend)
+	}
+	_ = sqlStorage.Close()
+}
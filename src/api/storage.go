@@ -10,6 +10,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -66,11 +67,41 @@ type StorageInterface interface {
 	CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error
 }
 
+// DatasetSizer is implemented by backends that can report how many bytes a dataset occupies
+// without loading it. Backends that keep datasets only in memory do not implement it.
+type DatasetSizer interface {
+	DatasetSize(datasetID string) (int64, error)
+}
+
+// PlayerQuery describes a filtered, sorted list of a dataset's players. Zero values leave a
+// filter unset.
+type PlayerQuery struct {
+	UIDs             []int64 // Players with any of these UIDs
+	MinAge           int
+	MaxAge           int
+	MinTransferValue int64
+	MaxTransferValue int64
+	MaxWage          int64
+	SortBy           string // Attribute name; players without it come last, as the player list sorts them
+	Descending       bool
+}
+
+// PlayerQuerier is implemented by backends that can filter, sort and look up players without
+// loading the whole dataset into memory.
+type PlayerQuerier interface {
+	QueryPlayers(datasetID string, query PlayerQuery) ([]Player, error)
+	PlayerByUID(datasetID string, uid int64) (Player, error)
+	HasAttribute(datasetID, attribute string) (bool, error)
+}
+
 // DatasetData represents a dataset containing player information
 type DatasetData struct {
-	Players        []Player `json:"players"`
-	CurrencySymbol string   `json:"currency_symbol"`
-	CacheData      string   `json:"cache_data,omitempty"`
+	Players        []Player         `json:"players"`
+	CurrencySymbol string           `json:"currency_symbol"`
+	BaseCurrency   string           `json:"base_currency,omitempty"`  // ISO code of CurrencySymbol at upload time
+	ExportProfile  string           `json:"export_profile,omitempty"` // Detected FM export profile key, e.g. "fm24-stats"
+	Metadata       *DatasetMetadata `json:"metadata,omitempty"`
+	CacheData      string           `json:"cache_data,omitempty"`
 }
 
 // InMemoryStorage provides in-memory storage for datasets
@@ -488,13 +519,15 @@ func (s *S3Storage) deleteSync(datasetID string) error {
 		return s.fallback.Delete(datasetID)
 	}
 
-	objectName := fmt.Sprintf("datasets/%s.json", datasetID)
 	ctx := context.Background()
 
-	err := s.client.RemoveObject(ctx, s.bucketName, objectName, minio.RemoveObjectOptions{})
-	if err != nil {
-		log.Printf("Warning: Failed to delete from S3: %v. Using fallback storage.", err)
-		return s.fallback.Delete(datasetID)
+	// Datasets are stored compressed, but older ones may still be plain JSON
+	for _, objectName := range []string{fmt.Sprintf("datasets/%s.json.gz", datasetID), fmt.Sprintf("datasets/%s.json", datasetID)} {
+		err := s.client.RemoveObject(ctx, s.bucketName, objectName, minio.RemoveObjectOptions{})
+		if err != nil {
+			log.Printf("Warning: Failed to delete from S3: %v. Using fallback storage.", err)
+			return s.fallback.Delete(datasetID)
+		}
 	}
 
 	if err := s.fallback.Delete(datasetID); err != nil {
@@ -518,15 +551,24 @@ func (s *S3Storage) List() ([]string, error) {
 	})
 
 	var ids []string
+	seen := make(map[string]bool)
 	for object := range objectCh {
 		if object.Err != nil {
 			log.Printf("Warning: Error listing S3 objects: %v. Using fallback storage.", object.Err)
 			return s.fallback.List()
 		}
 
-		if strings.HasSuffix(object.Key, ".json") {
-			id := strings.TrimPrefix(object.Key, "datasets/")
+		id := strings.TrimPrefix(object.Key, "datasets/")
+		switch {
+		case strings.HasSuffix(id, ".json.gz"):
+			id = strings.TrimSuffix(id, ".json.gz")
+		case strings.HasSuffix(id, ".json"):
 			id = strings.TrimSuffix(id, ".json")
+		default:
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
 			ids = append(ids, id)
 		}
 	}
@@ -535,6 +577,23 @@ func (s *S3Storage) List() ([]string, error) {
 	return ids, nil
 }
 
+// DatasetSize returns the size of a dataset's object in S3, preferring the compressed object
+func (s *S3Storage) DatasetSize(datasetID string) (int64, error) {
+	if s.client == nil {
+		return datasetSize(s.fallback, datasetID)
+	}
+
+	ctx := context.Background()
+	info, err := s.client.StatObject(ctx, s.bucketName, fmt.Sprintf("datasets/%s.json.gz", datasetID), minio.StatObjectOptions{})
+	if err != nil {
+		info, err = s.client.StatObject(ctx, s.bucketName, fmt.Sprintf("datasets/%s.json", datasetID), minio.StatObjectOptions{})
+		if err != nil {
+			return 0, apperrors.WrapErrDatasetNotFound(datasetID)
+		}
+	}
+	return info.Size, nil
+}
+
 // CleanupOldDatasets removes datasets older than the specified duration from S3
 func (s *S3Storage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
 	if s.client == nil {
@@ -900,6 +959,27 @@ func (s *LocalFileStorage) List() ([]string, error) {
 	return ids, nil
 }
 
+// DatasetSize returns the size of a dataset's file, preferring the compressed file
+func (s *LocalFileStorage) DatasetSize(datasetID string) (int64, error) {
+	if err := validateID(datasetID, 100); err != nil {
+		return 0, fmt.Errorf("invalid dataset ID: %w", err)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, filename := range []string{fmt.Sprintf("%s.json.gz", datasetID), fmt.Sprintf("%s.json", datasetID)} {
+		filePath, err := validateAndJoinPath(s.datasetDir, filename)
+		if err != nil {
+			return 0, fmt.Errorf("invalid file path for dataset: %w", err)
+		}
+		if info, err := os.Stat(filePath); err == nil {
+			return info.Size(), nil
+		}
+	}
+	return 0, apperrors.WrapErrDatasetNotFound(datasetID)
+}
+
 // CleanupOldDatasets removes datasets older than the specified duration from local files
 func (s *LocalFileStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
 	s.mutex.Lock()
@@ -1067,6 +1147,11 @@ func (s *HybridStorage) List() ([]string, error) {
 	return allIDs, nil
 }
 
+// DatasetSize returns the size of the dataset in local file storage
+func (s *HybridStorage) DatasetSize(datasetID string) (int64, error) {
+	return datasetSize(s.local, datasetID)
+}
+
 // CleanupOldDatasets removes datasets older than the specified duration from hybrid storage
 func (s *HybridStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets []string) error {
 	// Cleanup both memory and local storage
@@ -1077,6 +1162,15 @@ func (s *HybridStorage) CleanupOldDatasets(maxAge time.Duration, excludeDatasets
 	return s.local.CleanupOldDatasets(maxAge, excludeDatasets)
 }
 
+// datasetSize returns a dataset's stored size when backend implements DatasetSizer
+func datasetSize(backend StorageInterface, datasetID string) (int64, error) {
+	sizer, ok := backend.(DatasetSizer)
+	if !ok {
+		return 0, apperrors.ErrDatasetSizeUnavailable
+	}
+	return sizer.DatasetSize(datasetID)
+}
+
 // InitializeStorage creates and returns the appropriate storage implementation
 //
 //nolint:ireturn // StorageInterface is the intended return type for this factory function
@@ -1090,6 +1184,13 @@ func InitializeStorage(ctx context.Context) StorageInterface {
 	// Initialize the base storage backend
 	baseStorage := initializeBaseStorage(ctx)
 
+	// The SQL backend keeps players in queryable tables; the protobuf wrapper would store each
+	// dataset as a single encoded row
+	if _, isSQL := baseStorage.(*SQLStorage); isSQL && config.UseProtobuf {
+		logInfo(ctx, "Protobuf serialization is not applied to SQL storage")
+		config.UseProtobuf = false
+	}
+
 	// Apply protobuf wrapper if enabled and available
 	if config.UseProtobuf {
 		protobufStorage, err := createProtobufStorageWithFallback(ctx, baseStorage)
@@ -1226,7 +1327,7 @@ func testProtobufStorage(ctx context.Context, _ StorageInterface) error {
 	return nil
 }
 
-// initializeBaseStorage creates the underlying storage backend (S3, hybrid, or in-memory)
+// initializeBaseStorage creates the underlying storage backend (SQL, S3, hybrid, or in-memory)
 //nolint:ireturn // This function is designed to return different storage implementations
 func initializeBaseStorage(ctx context.Context) StorageInterface {
 	logDebug(ctx, "Initializing base storage backend")
@@ -1234,6 +1335,35 @@ func initializeBaseStorage(ctx context.Context) StorageInterface {
 
 	inMemory := CreateInMemoryStorage()
 
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND"))); backend {
+	case "sqlite", "sql":
+		// Use configurable database path, default to fmdash.db in the datasets directory
+		databasePath := os.Getenv("SQL_STORAGE_PATH")
+		if databasePath == "" {
+			datasetDir := os.Getenv("DATASETS_DIR")
+			if datasetDir == "" {
+				datasetDir = "./datasets"
+			}
+			databasePath = filepath.Join(datasetDir, "fmdash.db")
+		}
+
+		sqlStorage, err := CreateSQLStorage(databasePath)
+		if err != nil {
+			logError(ctx, "Failed to initialize SQL storage, falling back to the default backend",
+				"error", err, "database_path", databasePath)
+			break
+		}
+
+		logInfo(ctx, "Base storage initialization completed",
+			"storage_type", "sql",
+			"database_path", databasePath,
+			"duration_ms", time.Since(start).Milliseconds())
+		return sqlStorage
+	case "", "auto":
+	default:
+		logWarn(ctx, "Unknown STORAGE_BACKEND value, using the default backend", "storage_backend", backend)
+	}
+
 	s3Endpoint := os.Getenv("S3_ENDPOINT")
 	if s3Endpoint == "" {
 		logInfo(ctx, "No S3 endpoint configured, using hybrid storage", "storage_type", "hybrid")
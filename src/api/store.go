@@ -33,21 +33,26 @@ func InitStore() {
 	storage = InitializeStorage(ctx)
 }
 
-// StoreDataset stores player data using the storage interface
+// StoreDataset stores player data using the storage interface, keeping the dataset's upload details
 func StoreDataset(datasetID string, players []Player, currencySymbol string) error {
+	return storeDatasetData(datasetID, withDatasetDetails(datasetID, DatasetData{Players: players, CurrencySymbol: currencySymbol}))
+}
+
+// storeDatasetData stores a dataset with any upload details, such as its export profile,
+// filling in the base currency from the currency symbol when it is not set.
+func storeDatasetData(datasetID string, data DatasetData) error {
 	ctx := context.Background()
 	ctx, span := StartSpan(ctx, "store.dataset")
 	defer span.End()
 
 	SetSpanAttributes(ctx,
 		attribute.String("dataset.id", datasetID),
-		attribute.Int("dataset.player_count", len(players)),
-		attribute.String("dataset.currency", currencySymbol),
+		attribute.Int("dataset.player_count", len(data.Players)),
+		attribute.String("dataset.currency", data.CurrencySymbol),
 	)
 
-	data := DatasetData{
-		Players:        players,
-		CurrencySymbol: currencySymbol,
+	if data.BaseCurrency == "" {
+		data.BaseCurrency = currencyCodeForSymbol(data.CurrencySymbol)
 	}
 
 	err := storage.Store(datasetID, data)
@@ -55,14 +60,19 @@ func StoreDataset(datasetID string, players []Player, currencySymbol string) err
 		RecordError(ctx, err, "Failed to store dataset")
 		return err
 	}
+	// Restores store cache entries and save records here too; those have no details
+	if !isReservedDatasetID(datasetID) {
+		rememberDatasetDetails(datasetID, data)
+		storeDatasetDetailsRecord(datasetID)
+	}
 
 	// Invalidate related cache entries when dataset is updated
 	invalidateDatasetCache(datasetID)
 
 	RecordBusinessOperation(ctx, "dataset_store", true, map[string]interface{}{
 		"dataset_id":   datasetID,
-		"player_count": len(players),
-		"currency":     currencySymbol,
+		"player_count": len(data.Players),
+		"currency":     data.CurrencySymbol,
 	})
 
 	return nil
@@ -88,9 +98,9 @@ func StoreDatasetAsync(datasetID string, players []Player, currencySymbol string
 
 		startTime := time.Now()
 
-		data := DatasetData{
-			Players:        playersCopy,
-			CurrencySymbol: currencySymbol,
+		data := withDatasetDetails(datasetID, DatasetData{Players: playersCopy, CurrencySymbol: currencySymbol})
+		if data.BaseCurrency == "" {
+			data.BaseCurrency = currencyCodeForSymbol(currencySymbol)
 		}
 
 		err := storage.Store(datasetID, data)
@@ -101,6 +111,8 @@ func StoreDatasetAsync(datasetID string, players []Player, currencySymbol string
 			LogWarn("Error storing dataset %s asynchronously: %v", sanitizeForLogging(datasetID), err)
 			return
 		}
+		rememberDatasetDetails(datasetID, data)
+		storeDatasetDetailsRecord(datasetID)
 
 		// Invalidate related cache entries when dataset is updated
 		invalidateDatasetCache(datasetID)
@@ -135,6 +147,9 @@ func RetrieveDataset(datasetID string) ([]Player, string, error) {
 		RecordError(ctx, err, "Failed to retrieve dataset")
 		return nil, "", err
 	}
+	if _, found := cachedDatasetDetails(datasetID); !found && data.CacheData == "" {
+		rememberDatasetDetails(datasetID, data)
+	}
 
 	SetSpanAttributes(ctx,
 		attribute.Int("dataset.player_count", len(data.Players)),
@@ -149,22 +164,42 @@ func RetrieveDataset(datasetID string) ([]Player, string, error) {
 	return data.Players, data.CurrencySymbol, nil
 }
 
-// DeleteDataset deletes player data using the storage interface
+// DeleteDataset deletes player data using the storage interface, along with the in-memory
+// copy, cached views and duplicate-upload mapping that would otherwise keep serving it
 func DeleteDataset(datasetID string) error {
 	err := storage.Delete(datasetID)
 	if err != nil {
 		return err
 	}
 
+	storeMutex.Lock()
+	delete(playerDataStore, datasetID)
+	storeMutex.Unlock()
+
 	// Remove the duplicate mapping after successful deletion
 	removeDuplicateMapping(datasetID)
+	forgetDatasetDetails(datasetID)
+	deleteDatasetDetailsRecord(datasetID)
+	invalidateDatasetCache(datasetID)
+	unlinkDatasetFromSaves(datasetID)
 
 	return nil
 }
 
-// ListDatasets lists all available dataset IDs
+// ListDatasets lists all available dataset IDs, leaving out the details records stored
+// alongside each dataset
 func ListDatasets() ([]string, error) {
-	return storage.List()
+	ids, err := storage.List()
+	if err != nil {
+		return nil, err
+	}
+	datasetIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !strings.HasPrefix(id, datasetDetailsRecordPrefix) {
+			datasetIDs = append(datasetIDs, id)
+		}
+	}
+	return datasetIDs, nil
 }
 
 // CleanupOldDatasets removes datasets older than the specified duration, excluding specified datasets
@@ -216,6 +251,7 @@ func runCleanup() {
 
 	// Define datasets to exclude from cleanup
 	excludeDatasets := []string{"demo", "1e0c8dcd-f6b8-4874-a72e-a2a3bdf20038"}
+	excludeDatasets = append(excludeDatasets, saveRetentionExclusions()...)
 
 	// Get configured retention period
 	maxAge := getRetentionPeriod()
@@ -230,6 +266,7 @@ func runCleanup() {
 
 	// Clean up stale duplicate mappings
 	cleanupStaleDuplicateMappings()
+	cleanupStaleDatasetDetails()
 }
 
 // Legacy functions for backward compatibility
@@ -309,6 +346,18 @@ func SetPlayerData(datasetID string, players []Player, currencySymbol string) {
 
 // SetPlayerDataAsync stores player data in both legacy store (immediately) and new storage (asynchronously)
 func SetPlayerDataAsync(datasetID string, players []Player, currencySymbol string) {
+	SetDatasetAsync(datasetID, withDatasetDetails(datasetID, DatasetData{Players: players, CurrencySymbol: currencySymbol}))
+}
+
+// SetDatasetAsync is SetPlayerDataAsync for callers that also record upload details, such as
+// the detected export profile and dataset metadata, alongside the players.
+func SetDatasetAsync(datasetID string, data DatasetData) {
+	players := data.Players
+	currencySymbol := data.CurrencySymbol
+	if data.BaseCurrency == "" {
+		data.BaseCurrency = currencyCodeForSymbol(currencySymbol)
+	}
+
 	ctx := context.Background()
 	ctx, span := StartSpan(ctx, "store.set_player_data_async")
 	defer span.End()
@@ -331,17 +380,13 @@ func SetPlayerDataAsync(datasetID string, players []Player, currencySymbol strin
 		CurrencySymbol: currencySymbol,
 	}
 	storeMutex.Unlock()
+	rememberDatasetDetails(datasetID, data)
 
 	// Store in new storage system asynchronously (potentially slow S3/disk operation)
 	AddSpanEvent(ctx, "store.new_storage_async_queued")
 
 	// Serialize the data immediately to avoid race conditions during async storage
 	// This way the goroutine only works with immutable JSON data
-	data := DatasetData{
-		Players:        players,
-		CurrencySymbol: currencySymbol,
-	}
-
 	serializedData, err := json.Marshal(data)
 	if err != nil {
 		RecordError(ctx, err, "Failed to serialize data for async storage")
@@ -370,7 +415,13 @@ func SetPlayerDataAsync(datasetID string, players []Player, currencySymbol strin
 			return
 		}
 
-		if err := StoreDataset(datasetID, deserializedData.Players, deserializedData.CurrencySymbol); err != nil {
+		// Pick up metadata edits made while the store was queued
+		if details, found := cachedDatasetDetails(datasetID); found {
+			deserializedData.ExportProfile = details.ExportProfile
+			deserializedData.Metadata = details.Metadata
+		}
+
+		if err := storeDatasetData(datasetID, deserializedData); err != nil {
 			RecordError(asyncCtx, err, "Failed to store in new storage system asynchronously")
 			LogWarn("Error storing dataset %s to persistent storage asynchronously: %v", sanitizeForLogging(datasetID), err)
 			return
@@ -394,6 +445,7 @@ func invalidateDatasetCache(datasetID string) {
 		fmt.Sprintf("teams_%s_*", datasetID), // Note: This is a pattern, we'll need to iterate through keys
 		fmt.Sprintf("players_%s", datasetID),
 		fmt.Sprintf("percentiles:%s:*", datasetID), // Percentile cache entries
+		fmt.Sprintf("cohort:%s:*", datasetID),      // Percentile cohort entries
 		fmt.Sprintf("filtered:%s:*", datasetID),    // Filtered result cache entries
 	}
 
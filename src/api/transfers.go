@@ -0,0 +1,271 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// PlayerMove is a player whose club or division differs between two datasets, with their value
+// and weekly wage at both times.
+type PlayerMove struct {
+	UID               int64  `json:"uid"`
+	Name              string `json:"name"`
+	Position          string `json:"position"`
+	Age               string `json:"age"`
+	FromClub          string `json:"fromClub"`
+	ToClub            string `json:"toClub"`
+	FromDivision      string `json:"fromDivision"`
+	ToDivision        string `json:"toDivision"`
+	ClubChanged       bool   `json:"clubChanged"` // False when only the club's division changed
+	TransferValueFrom int64  `json:"transferValueFrom"`
+	TransferValueTo   int64  `json:"transferValueTo"`
+	WageFrom          int64  `json:"wageFrom"`
+	WageTo            int64  `json:"wageTo"`
+}
+
+// PlayerLedgerEntry is a player present in only one of two datasets: a regen or newly scouted
+// player, or one who retired or left scouting range.
+type PlayerLedgerEntry struct {
+	UID           int64  `json:"uid"`
+	Name          string `json:"name"`
+	Position      string `json:"position"`
+	Age           string `json:"age"`
+	Club          string `json:"club"`
+	Division      string `json:"division"`
+	TransferValue int64  `json:"transferValue"`
+	Wage          int64  `json:"wage"`
+}
+
+// TeamTransferSummary is one club's in and out activity between two datasets. Values are
+// summed transfer values and weekly wages, since exports do not record fees.
+type TeamTransferSummary struct {
+	Club        string `json:"club"`
+	Division    string `json:"division"`
+	MovesIn     int    `json:"movesIn"`
+	MovesOut    int    `json:"movesOut"`
+	NewPlayers  int    `json:"newPlayers"`
+	Disappeared int    `json:"disappeared"`
+	ValueIn     int64  `json:"valueIn"`
+	ValueOut    int64  `json:"valueOut"`
+	WagesIn     int64  `json:"wagesIn"`
+	WagesOut    int64  `json:"wagesOut"`
+}
+
+// TransferLedgerResponse lists the player movement between two datasets of the same save.
+type TransferLedgerResponse struct {
+	FromDatasetID  string                `json:"fromDatasetId"`
+	ToDatasetID    string                `json:"toDatasetId"`
+	CurrencySymbol string                `json:"currencySymbol"`
+	Moves          []PlayerMove          `json:"moves"`
+	NewPlayers     []PlayerLedgerEntry   `json:"newPlayers"`
+	Disappeared    []PlayerLedgerEntry   `json:"disappeared"`
+	Teams          []TeamTransferSummary `json:"teams"`
+}
+
+// createPlayerLedgerEntry describes a player present in only one dataset.
+func createPlayerLedgerEntry(player *Player) PlayerLedgerEntry {
+	return PlayerLedgerEntry{
+		UID:           player.UID,
+		Name:          player.Name,
+		Position:      player.Position,
+		Age:           player.Age,
+		Club:          player.Club,
+		Division:      player.Division,
+		TransferValue: playerTransferValueEstimate(player),
+		Wage:          player.WageAmount,
+	}
+}
+
+// teamLedger accumulates the per-club summaries of a transfer ledger.
+type teamLedger map[string]*TeamTransferSummary
+
+func (l teamLedger) team(club, division string) *TeamTransferSummary {
+	summary, found := l[club]
+	if !found {
+		summary = &TeamTransferSummary{Club: club, Division: division}
+		l[club] = summary
+	}
+	return summary
+}
+
+// sorted returns the clubs with the most activity first. Players without a club, such as free
+// agents, are not summarized.
+func (l teamLedger) sorted() []TeamTransferSummary {
+	teams := make([]TeamTransferSummary, 0, len(l))
+	for club, summary := range l {
+		if club != "" {
+			teams = append(teams, *summary)
+		}
+	}
+	activity := func(t *TeamTransferSummary) int { return t.MovesIn + t.MovesOut + t.NewPlayers + t.Disappeared }
+	sort.Slice(teams, func(i, j int) bool {
+		if a, b := activity(&teams[i]), activity(&teams[j]); a != b {
+			return a > b
+		}
+		return teams[i].Club < teams[j].Club
+	})
+	return teams
+}
+
+// buildTransferLedger joins two datasets by UID and lists the players who moved, appeared or
+// disappeared. A non-empty club restricts the ledger to players who joined or left that club.
+func buildTransferLedger(pair snapshotPair, currency, club string) (TransferLedgerResponse, error) {
+	pair, err := pair.inCurrency(currency)
+	if err != nil {
+		return TransferLedgerResponse{}, err
+	}
+	fromByUID := playersByUID(pair.FromPlayers)
+	toByUID := playersByUID(pair.ToPlayers)
+	involves := func(clubs ...string) bool {
+		if club == "" {
+			return true
+		}
+		for _, c := range clubs {
+			if strings.EqualFold(c, club) {
+				return true
+			}
+		}
+		return false
+	}
+
+	response := TransferLedgerResponse{
+		CurrencySymbol: pair.CurrencySymbol,
+		Moves:          make([]PlayerMove, 0),
+		NewPlayers:     make([]PlayerLedgerEntry, 0),
+		Disappeared:    make([]PlayerLedgerEntry, 0),
+	}
+	teams := make(teamLedger)
+
+	for i := range pair.ToPlayers {
+		to := &pair.ToPlayers[i]
+		if to.UID == 0 {
+			continue
+		}
+		from, found := fromByUID[to.UID]
+		if !found {
+			if !involves(to.Club) {
+				continue
+			}
+			entry := createPlayerLedgerEntry(to)
+			response.NewPlayers = append(response.NewPlayers, entry)
+			team := teams.team(to.Club, to.Division)
+			team.NewPlayers++
+			team.ValueIn += entry.TransferValue
+			team.WagesIn += entry.Wage
+			continue
+		}
+		if (from.Club == to.Club && from.Division == to.Division) || !involves(from.Club, to.Club) {
+			continue
+		}
+
+		move := PlayerMove{
+			UID:               to.UID,
+			Name:              to.Name,
+			Position:          to.Position,
+			Age:               to.Age,
+			FromClub:          from.Club,
+			ToClub:            to.Club,
+			FromDivision:      from.Division,
+			ToDivision:        to.Division,
+			ClubChanged:       from.Club != to.Club,
+			TransferValueFrom: playerTransferValueEstimate(from),
+			TransferValueTo:   playerTransferValueEstimate(to),
+			WageFrom:          from.WageAmount,
+			WageTo:            to.WageAmount,
+		}
+		response.Moves = append(response.Moves, move)
+		if move.ClubChanged {
+			out := teams.team(from.Club, from.Division)
+			out.MovesOut++
+			out.ValueOut += move.TransferValueFrom
+			out.WagesOut += move.WageFrom
+			in := teams.team(to.Club, to.Division)
+			in.MovesIn++
+			in.ValueIn += move.TransferValueTo
+			in.WagesIn += move.WageTo
+		}
+	}
+
+	for i := range pair.FromPlayers {
+		from := &pair.FromPlayers[i]
+		if from.UID == 0 || toByUID[from.UID] != nil || !involves(from.Club) {
+			continue
+		}
+		entry := createPlayerLedgerEntry(from)
+		response.Disappeared = append(response.Disappeared, entry)
+		team := teams.team(from.Club, from.Division)
+		team.Disappeared++
+		team.ValueOut += entry.TransferValue
+		team.WagesOut += entry.Wage
+	}
+
+	// The most valuable business first
+	sort.SliceStable(response.Moves, func(i, j int) bool {
+		return response.Moves[i].TransferValueTo > response.Moves[j].TransferValueTo
+	})
+	byValue := func(entries []PlayerLedgerEntry) {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].TransferValue > entries[j].TransferValue })
+	}
+	byValue(response.NewPlayers)
+	byValue(response.Disappeared)
+
+	response.Teams = teams.sorted()
+	if club != "" {
+		// Only the requested club's summary; counterparties are listed on the moves
+		filtered := make([]TeamTransferSummary, 0, 1)
+		for _, team := range response.Teams {
+			if strings.EqualFold(team.Club, club) {
+				filtered = append(filtered, team)
+			}
+		}
+		response.Teams = filtered
+	}
+	return response, nil
+}
+
+// transfersHandler builds a transfer ledger between two datasets of the same save:
+//
+//	GET /api/transfers/{fromDatasetId}/{toDatasetId}?club=&currency=
+func transfersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx, span := StartSpan(ctx, "api.transfers")
+	defer span.End()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fromID, toID, ok := parseSnapshotPairPath(w, r, "/api/transfers")
+	if !ok {
+		return
+	}
+	SetSpanAttributes(ctx,
+		attribute.String("dataset.from_id", fromID),
+		attribute.String("dataset.to_id", toID),
+	)
+
+	pair, ok := loadSnapshotPair(w, fromID, toID)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	response, err := buildTransferLedger(pair, query.Get("currency"), strings.TrimSpace(query.Get("club")))
+	if err != nil {
+		logWarn(ctx, "Transfer ledger currency conversion rejected", "from_dataset_id", fromID, "to_dataset_id", toID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response.FromDatasetID, response.ToDatasetID = fromID, toID
+
+	SetSpanAttributes(ctx,
+		attribute.Int("transfers.moves", len(response.Moves)),
+		attribute.Int("transfers.new_players", len(response.NewPlayers)),
+		attribute.Int("transfers.disappeared", len(response.Disappeared)),
+	)
+	writeDatasetsResponse(ctx, w, r, response)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func transferTestPlayer(uid int64, name, club, division string, value, wage int64) Player {
+	return Player{UID: uid, Name: name, Club: club, Division: division, TransferValueAmount: value, WageAmount: wage}
+}
+
+func TestBuildTransferLedger(t *testing.T) {
+	pair := snapshotPair{
+		FromPlayers: []Player{
+			transferTestPlayer(1, "Signing", "Rival FC", "Premier League", 5000000, 20000),
+			transferTestPlayer(2, "Stayer", "Rival FC", "Premier League", 1000000, 5000),
+			transferTestPlayer(3, "Retiree", "Rival FC", "Premier League", 100000, 8000),
+			transferTestPlayer(4, "Relegated", "Other FC", "Premier League", 2000000, 10000),
+			transferTestPlayer(0, "No UID", "Rival FC", "Premier League", 0, 0),
+		},
+		FromSymbol: "£",
+		ToPlayers: []Player{
+			transferTestPlayer(1, "Signing", "Big FC", "Premier League", 7000000, 40000),
+			transferTestPlayer(2, "Stayer", "Rival FC", "Premier League", 1200000, 5000),
+			transferTestPlayer(4, "Relegated", "Other FC", "Championship", 1500000, 10000),
+			transferTestPlayer(5, "Regen", "Rival FC", "Premier League", 50000, 500),
+		},
+		ToSymbol: "£",
+	}
+
+	ledger, err := buildTransferLedger(pair, "", "")
+	if err != nil {
+		t.Fatalf("buildTransferLedger failed: %v", err)
+	}
+	if len(ledger.Moves) != 2 || ledger.Moves[0].UID != 1 || !ledger.Moves[0].ClubChanged || ledger.Moves[1].ClubChanged {
+		t.Fatalf("Unexpected moves: %+v", ledger.Moves)
+	}
+	if move := ledger.Moves[0]; move.WageFrom != 20000 || move.WageTo != 40000 || move.TransferValueFrom != 5000000 {
+		t.Errorf("Move lacks value and wage at both times: %+v", move)
+	}
+	if len(ledger.NewPlayers) != 1 || ledger.NewPlayers[0].UID != 5 {
+		t.Errorf("Unexpected new players: %+v", ledger.NewPlayers)
+	}
+	if len(ledger.Disappeared) != 1 || ledger.Disappeared[0].UID != 3 {
+		t.Errorf("Unexpected disappeared players: %+v", ledger.Disappeared)
+	}
+
+	teams := make(map[string]TeamTransferSummary)
+	for _, team := range ledger.Teams {
+		teams[team.Club] = team
+	}
+	rival := teams["Rival FC"]
+	if rival.MovesOut != 1 || rival.NewPlayers != 1 || rival.Disappeared != 1 || rival.ValueOut != 5100000 || rival.WagesIn != 500 {
+		t.Errorf("Unexpected Rival FC summary: %+v", rival)
+	}
+	if big := teams["Big FC"]; big.MovesIn != 1 || big.ValueIn != 7000000 {
+		t.Errorf("Unexpected Big FC summary: %+v", big)
+	}
+	if ledger.Teams[0].Club != "Rival FC" {
+		t.Errorf("Expected the busiest club first, got %s", ledger.Teams[0].Club)
+	}
+
+	ledger, _ = buildTransferLedger(pair, "", "big fc")
+	if len(ledger.Moves) != 1 || len(ledger.NewPlayers) != 0 || len(ledger.Disappeared) != 0 ||
+		len(ledger.Teams) != 1 || ledger.Teams[0].Club != "Big FC" {
+		t.Errorf("Expected only Big FC business, got %+v", ledger)
+	}
+}
+
+func TestTransfersHandler(t *testing.T) {
+	InitStore()
+
+	for id, players := range map[string][]Player{
+		"transfers-test-summer": {transferTestPlayer(11, "Winger", "Rival FC", "Premier League", 3000000, 15000)},
+		"transfers-test-winter": {transferTestPlayer(11, "Winger", "Big FC", "Premier League", 3500000, 30000)},
+	} {
+		if err := storeDatasetData(id, DatasetData{Players: players, CurrencySymbol: "£"}); err != nil {
+			t.Fatalf("Failed to store %s: %v", id, err)
+		}
+		t.Cleanup(func() { _ = DeleteDataset(id) })
+	}
+
+	w := httptest.NewRecorder()
+	transfersHandler(w, httptest.NewRequest(http.MethodGet, "/api/transfers/transfers-test-summer/transfers-test-winter?club=Rival%20FC", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var ledger TransferLedgerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &ledger); err != nil {
+		t.Fatalf("Failed to parse ledger: %v", err)
+	}
+	if ledger.ToDatasetID != "transfers-test-winter" || len(ledger.Moves) != 1 || ledger.Moves[0].ToClub != "Big FC" {
+		t.Errorf("Unexpected ledger: %+v", ledger)
+	}
+
+	w = httptest.NewRecorder()
+	transfersHandler(w, httptest.NewRequest(http.MethodGet, "/api/transfers/transfers-test-summer/missing-dataset", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing dataset, got %d", http.StatusNotFound, w.Code)
+	}
+}
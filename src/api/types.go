@@ -6,6 +6,8 @@ package main
 type RoleOverallScore struct {
 	RoleName string `json:"roleName"`
 	Score    int    `json:"score"`
+	MinScore int    `json:"minScore"` // Score with masked attributes at their lower bound
+	MaxScore int    `json:"maxScore"` // Score with masked attributes at their upper bound
 }
 
 // Player holds all the information and calculated statistics for a football player.
@@ -48,7 +50,26 @@ type Player struct {
 	BestRoleOverall         string                        `json:"bestRoleOverall"`
 	RoleSpecificOveralls    []RoleOverallScore            `json:"roleSpecificOveralls"`
 	TransferValueAmount     int64                         `json:"transferValueAmount"`
-	WageAmount              int64                         `json:"wageAmount"`
+	WageAmount              int64                         `json:"wageAmount"`           // Normalized to a weekly wage
+	WagePeriod              WagePeriod                    `json:"wagePeriod,omitempty"` // Period the export displayed
+	TransferValueMin        int64                         `json:"transferValueMin,omitempty"`
+	TransferValueMax        int64                         `json:"transferValueMax,omitempty"`
+	TransferValueEstimate   int64                         `json:"transferValueEstimate,omitempty"`
+	AgeYears                int                           `json:"ageYears,omitempty"`
+	HeightCm                int                           `json:"heightCm,omitempty"`
+	WeightKg                int                           `json:"weightKg,omitempty"`
+	PreferredFoot           string                        `json:"preferredFoot,omitempty"`
+	LeftFoot                int                           `json:"leftFoot,omitempty"`
+	RightFoot               int                           `json:"rightFoot,omitempty"`
+	ContractExpiry          string                        `json:"contractExpiry,omitempty"` // YYYY-MM-DD
+	DateOfBirth             string                        `json:"dateOfBirth,omitempty"`    // YYYY-MM-DD
+	AttributeMin            map[string]int                `json:"attributeMin,omitempty"`   // Lower bounds of masked attributes
+	AttributeMax            map[string]int                `json:"attributeMax,omitempty"`   // Upper bounds of masked attributes
+	OverallMin              int                           `json:"overallMin"`               // Pessimistic Overall
+	OverallMax              int                           `json:"overallMax"`               // Optimistic Overall
+	StatMin                 map[string]int                `json:"statMin,omitempty"`        // Pessimistic FIFA stats, masked players only
+	StatMax                 map[string]int                `json:"statMax,omitempty"`        // Optimistic FIFA stats, masked players only
+	RatingConfidence        string                        `json:"ratingConfidence,omitempty"`
 }
 
 // PlayerParseResult is used by worker goroutines to return a parsed player or an error.
@@ -59,15 +80,20 @@ type PlayerParseResult struct {
 
 // UploadResponse is the JSON response sent after a successful file upload and parse.
 type UploadResponse struct {
-	DatasetID              string `json:"datasetId"`
-	Message                string `json:"message"`
-	DetectedCurrencySymbol string `json:"detectedCurrencySymbol,omitempty"`
+	DatasetID              string       `json:"datasetId"`
+	Message                string       `json:"message"`
+	DetectedCurrencySymbol string       `json:"detectedCurrencySymbol,omitempty"`
+	ParseReport            *ParseReport `json:"parseReport,omitempty"`
+	MergeReport            *MergeReport `json:"mergeReport,omitempty"`
 }
 
 // PlayerDataWithCurrency is the JSON response for fetching player data, including the currency.
 type PlayerDataWithCurrency struct {
-	Players        []Player `json:"players"`
-	CurrencySymbol string   `json:"currencySymbol"`
+	Players        []Player         `json:"players"`
+	CurrencySymbol string           `json:"currencySymbol"`
+	BaseCurrency   string           `json:"baseCurrency,omitempty"`
+	ExportProfile  string           `json:"exportProfile,omitempty"`
+	Metadata       *DatasetMetadata `json:"metadata,omitempty"`
 }
 
 // --- END: Struct Definitions ---
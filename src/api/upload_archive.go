@@ -0,0 +1,317 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	apperrors "api/errors"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// UploadCompression identifies how an uploaded file is compressed or archived.
+type UploadCompression int
+
+const (
+	// UploadCompressionNone is a plain HTML, CSV or TSV export.
+	UploadCompressionNone UploadCompression = iota
+	// UploadCompressionGzip is a single gzip-compressed export, e.g. squad.html.gz.
+	UploadCompressionGzip
+	// UploadCompressionZstd is a single zstd-compressed export, e.g. squad.html.zst.
+	UploadCompressionZstd
+	// UploadCompressionZip is a zip archive holding one or more exports.
+	UploadCompressionZip
+)
+
+// Leading bytes identifying each compressed format. Extensions are not trusted, since
+// browsers and chat tools rename and re-compress files freely.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+)
+
+// compressionMagicLength is how many leading bytes detectUploadCompression needs.
+const compressionMagicLength = 4
+
+// maxDecompressionRatio caps how far a compressed upload may expand. FM exports are
+// repetitive HTML that compresses about 10x; zip bombs expand by thousands.
+const maxDecompressionRatio = 100
+
+// decompressionRatioGrace is how many decompressed bytes are allowed before the ratio is
+// enforced, so tiny but highly compressible files are not rejected.
+const decompressionRatioGrace = 1 << 20
+
+// zstdMaxDecoderMemory bounds the zstd window a crafted frame can make the decoder allocate.
+const zstdMaxDecoderMemory = 64 << 20
+
+// compressedUploadExtensions are stripped from the filename to recover the export's own extension.
+var compressedUploadExtensions = []string{".gz", ".gzip", ".zst", ".zstd"}
+
+// String returns the lower-case name of the compression, used in logs and metrics.
+func (c UploadCompression) String() string {
+	switch c {
+	case UploadCompressionGzip:
+		return "gzip"
+	case UploadCompressionZstd:
+		return "zstd"
+	case UploadCompressionZip:
+		return "zip"
+	default:
+		return "none"
+	}
+}
+
+// detectUploadCompression identifies a compressed upload from its first bytes.
+func detectUploadCompression(head []byte) UploadCompression {
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		return UploadCompressionZip
+	case bytes.HasPrefix(head, zstdMagic):
+		return UploadCompressionZstd
+	case bytes.HasPrefix(head, gzipMagic):
+		return UploadCompressionGzip
+	}
+	return UploadCompressionNone
+}
+
+// decompressedUploadFilename strips a compression extension, so "squad.html.gz" is parsed
+// as "squad.html". Names without an inner extension fall back to content sniffing.
+func decompressedUploadFilename(filename string) string {
+	extension := strings.ToLower(filepath.Ext(filename))
+	for _, compressed := range compressedUploadExtensions {
+		if extension == compressed {
+			return strings.TrimSuffix(filename, filepath.Ext(filename))
+		}
+	}
+	return filename
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	source    io.Reader
+	bytesRead int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.source.Read(p)
+	c.bytesRead += int64(n)
+	return n, err
+}
+
+// decompressingReader decompresses a gzip or zstd upload, failing reads once the output
+// outgrows maxDecompressionRatio times the compressed bytes consumed. The decompressed size
+// itself is limited by the uploadStreamReader wrapped around it.
+type decompressingReader struct {
+	compressed    *countingReader
+	decompressed  io.Reader
+	close         func()
+	bytesOut      int64
+	ratioExceeded bool
+}
+
+// createDecompressingReader starts decompressing source in the given format.
+func createDecompressingReader(compression UploadCompression, source io.Reader) (*decompressingReader, error) {
+	compressed := &countingReader{source: source}
+	reader := &decompressingReader{compressed: compressed}
+
+	switch compression {
+	case UploadCompressionGzip:
+		gzipReader, err := gzip.NewReader(compressed)
+		if err != nil {
+			return nil, err
+		}
+		reader.decompressed = gzipReader
+		reader.close = func() {
+			if closeErr := gzipReader.Close(); closeErr != nil {
+				log.Printf("Failed to close gzip reader: %v", closeErr)
+			}
+		}
+	case UploadCompressionZstd:
+		zstdReader, err := zstd.NewReader(compressed,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(zstdMaxDecoderMemory))
+		if err != nil {
+			return nil, err
+		}
+		reader.decompressed = zstdReader
+		reader.close = zstdReader.Close
+	default:
+		return nil, apperrors.ErrUnsupportedFileFormat
+	}
+	return reader, nil
+}
+
+// Read implements io.Reader, enforcing the compression ratio as the output grows.
+func (d *decompressingReader) Read(p []byte) (int, error) {
+	if d.ratioExceeded {
+		return 0, apperrors.WrapErrCompressionRatioExceeded(maxDecompressionRatio)
+	}
+
+	n, err := d.decompressed.Read(p)
+	d.bytesOut += int64(n)
+	if d.bytesOut > decompressionRatioGrace && d.bytesOut > d.compressed.bytesRead*maxDecompressionRatio {
+		d.ratioExceeded = true
+		return 0, apperrors.WrapErrCompressionRatioExceeded(maxDecompressionRatio)
+	}
+	return n, err
+}
+
+// RatioExceeded reports whether decompression was stopped by the ratio cap. It is safe
+// to call on a nil reader, which stands for an uncompressed upload.
+func (d *decompressingReader) RatioExceeded() bool {
+	return d != nil && d.ratioExceeded
+}
+
+// Close releases the decompressor. It is safe to call on a nil reader.
+func (d *decompressingReader) Close() {
+	if d != nil && d.close != nil {
+		d.close()
+	}
+}
+
+// rejectCompressionBomb builds the error returned for uploads that expand too far.
+func rejectCompressionBomb(ctx context.Context, filename string) *apperrors.AppError {
+	logWarn(ctx, "Upload rejected: Compression ratio exceeds limit",
+		"filename", filename,
+		"max_ratio", maxDecompressionRatio)
+	SetSpanAttributes(ctx, attribute.String("upload.rejection_reason", "compression_ratio_exceeded"))
+	appErr := apperrors.CreateFileTooLargeError(getMaxUploadSize())
+	appErr.Message = "File rejected: " + apperrors.WrapErrCompressionRatioExceeded(maxDecompressionRatio).Error() + "."
+	return appErr
+}
+
+// parseUploadSource parses an uploaded file into one export, or into one export per member
+// when it is a zip archive. Gzip and zstd files are decompressed by parseUploadStream, so
+// every member and every compressed file goes through the same parse pipeline.
+func parseUploadSource(ctx context.Context, filename string, source io.Reader, estimatedFileSize int64, job *UploadJob) ([]*parsedUpload, *apperrors.AppError) {
+	bufferedSource := bufio.NewReaderSize(source, optimalChunkSize)
+
+	// Short files return io.EOF alongside the partial data, which is all the check needs
+	head, _ := bufferedSource.Peek(compressionMagicLength)
+	if detectUploadCompression(head) != UploadCompressionZip {
+		parsed, appErr := parseUploadStream(ctx, filename, bufferedSource, estimatedFileSize, job)
+		if appErr != nil {
+			return nil, appErr
+		}
+		return []*parsedUpload{parsed}, nil
+	}
+
+	SetSpanAttributes(ctx, attribute.String("file.compression", UploadCompressionZip.String()))
+
+	// Zip members are located through the central directory at the end of the archive,
+	// so the archive is spooled to disk instead of being parsed as it streams in.
+	archivePath, archiveSize, err := spoolUploadToTempFile(job.TrackReader(bufferedSource))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUploadTooLarge) {
+			return nil, rejectOversizedUpload(ctx, filename)
+		}
+		RecordError(ctx, err, "Failed to spool zip upload")
+		return nil, apperrors.CreateBadRequestError("Error reading the file: " + err.Error())
+	}
+	defer func() {
+		if removeErr := os.Remove(archivePath); removeErr != nil {
+			log.Printf("Failed to remove temporary upload file %s: %v", archivePath, removeErr)
+		}
+	}()
+
+	return parseZipUpload(ctx, filename, archivePath, archiveSize, job)
+}
+
+// parseZipUpload parses every export in a spooled zip archive. The members' declared sizes
+// together are checked against the size limit, and each member's compression ratio, before
+// anything is decompressed. archive/zip fails reads that run past a member's declared size,
+// and the bytes actually read are counted against the same limit across all members.
+func parseZipUpload(ctx context.Context, filename, archivePath string, archiveSize int64, job *UploadJob) ([]*parsedUpload, *apperrors.AppError) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		RecordError(ctx, err, "Failed to open zip upload")
+		return nil, apperrors.CreateBadRequestError("Error reading the zip archive: " + err.Error())
+	}
+	defer func() {
+		if closeErr := archive.Close(); closeErr != nil {
+			RecordError(ctx, closeErr, "Failed to close zip upload")
+		}
+	}()
+
+	members := zipExportMembers(archive.File)
+	if len(members) == 0 {
+		return nil, apperrors.CreateBadRequestError(apperrors.ErrArchiveNoExports.Error() + ".")
+	}
+	if len(members) > maxMergeUploadFiles {
+		return nil, apperrors.CreateBadRequestError(apperrors.WrapErrArchiveTooManyExports(maxMergeUploadFiles).Error() + ".")
+	}
+
+	logDebug(ctx, "Zip upload opened",
+		"filename", filename,
+		"archive_size_bytes", archiveSize,
+		"exports", len(members))
+
+	maxUploadSize := getMaxUploadSize()
+	var declaredSize uint64
+	for _, member := range members {
+		uncompressedSize := member.UncompressedSize64
+		if uncompressedSize > decompressionRatioGrace && uncompressedSize > member.CompressedSize64*maxDecompressionRatio {
+			return nil, rejectCompressionBomb(ctx, path.Base(member.Name))
+		}
+		declaredSize += uncompressedSize
+		if uncompressedSize > uint64(maxUploadSize) || declaredSize > uint64(maxUploadSize) {
+			return nil, rejectOversizedUpload(ctx, filename)
+		}
+	}
+
+	files := make([]*parsedUpload, 0, len(members))
+	remaining := maxUploadSize
+	for _, member := range members {
+		memberName := path.Base(member.Name)
+		memberReader, err := member.Open()
+		if err != nil {
+			RecordError(ctx, err, "Failed to open zip member")
+			return nil, apperrors.CreateBadRequestError("Error reading " + memberName + " from the zip archive: " + err.Error())
+		}
+		// One byte past the remaining allowance tells an archive that reached the limit from
+		// one that exceeded it
+		budget := &io.LimitedReader{R: memberReader, N: remaining + 1}
+		parsed, appErr := parseUploadStream(ctx, memberName, budget, int64(member.UncompressedSize64), job) // #nosec G115 -- bounded by maxUploadSize above
+		if closeErr := memberReader.Close(); closeErr != nil {
+			RecordError(ctx, closeErr, "Failed to close zip member")
+		}
+		if budget.N == 0 {
+			return nil, rejectOversizedUpload(ctx, filename)
+		}
+		if appErr != nil {
+			return nil, appErr
+		}
+		remaining = budget.N - 1
+		files = append(files, parsed)
+	}
+	return files, nil
+}
+
+// zipExportMembers returns the archive members that look like player exports, skipping
+// directories and the metadata files macOS and Windows add when zipping a folder.
+func zipExportMembers(members []*zip.File) []*zip.File {
+	exports := make([]*zip.File, 0, len(members))
+	for _, member := range members {
+		if member.FileInfo().IsDir() || strings.HasPrefix(member.Name, "__MACOSX/") {
+			continue
+		}
+		name := path.Base(member.Name)
+		if strings.HasPrefix(name, ".") || strings.EqualFold(name, "desktop.ini") || strings.EqualFold(name, "thumbs.db") {
+			continue
+		}
+		exports = append(exports, member)
+	}
+	return exports
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apperrors "api/errors"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const archiveTestAttributesView = "Name,UID,Club,Position,Fin,Pac,Dri\n" +
+	"Archive Forward,910001,Archive FC,ST (C),16,15,14\n" +
+	"Archive Back,910002,Archive FC,D (R),8,14,10\n"
+
+const archiveTestStatsView = "Name,UID,Club,Mins,Gls\n" +
+	"Archive Forward,910001,Archive FC,2700,21\n"
+
+func gzipForTest(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zipForTest(t *testing.T, members map[string][]byte, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range order {
+		memberWriter, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip member %s: %v", name, err)
+		}
+		if _, err := memberWriter.Write(members[name]); err != nil {
+			t.Fatalf("Failed to write zip member %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectUploadCompression(t *testing.T) {
+	tests := []struct {
+		head []byte
+		want UploadCompression
+	}{
+		{gzipForTest(t, []byte("Name\n")), UploadCompressionGzip},
+		{zstdMagic, UploadCompressionZstd},
+		{zipForTest(t, map[string][]byte{"a.csv": []byte("Name\n")}, "a.csv"), UploadCompressionZip},
+		{[]byte("<html>"), UploadCompressionNone},
+		{[]byte("P"), UploadCompressionNone},
+	}
+	for _, tt := range tests {
+		if got := detectUploadCompression(tt.head); got != tt.want {
+			t.Errorf("detectUploadCompression(%q) = %s, want %s", tt.head[:1], got, tt.want)
+		}
+	}
+
+	for input, want := range map[string]string{"squad.html.gz": "squad.html", "stats.csv.ZST": "stats.csv", "export.gz": "export", "view.html": "view.html"} {
+		if got := decompressedUploadFilename(input); got != want {
+			t.Errorf("decompressedUploadFilename(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDecompressingReaderRatioCap(t *testing.T) {
+	bomb := gzipForTest(t, make([]byte, 8*decompressionRatioGrace))
+	reader, err := createDecompressingReader(UploadCompressionGzip, bytes.NewReader(bomb))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	defer reader.Close()
+
+	if _, err := io.Copy(io.Discard, reader); !errors.Is(err, apperrors.ErrCompressionRatioExceeded) {
+		t.Fatalf("Expected ErrCompressionRatioExceeded, got %v", err)
+	}
+	if !reader.RatioExceeded() {
+		t.Error("RatioExceeded() = false after the cap was hit")
+	}
+
+	export := gzipForTest(t, []byte(archiveTestAttributesView))
+	reader, err = createDecompressingReader(UploadCompressionGzip, bytes.NewReader(export))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	defer reader.Close()
+	if decompressed, err := io.ReadAll(reader); err != nil || string(decompressed) != archiveTestAttributesView {
+		t.Errorf("Expected the export back unchanged, got %q, %v", decompressed, err)
+	}
+}
+
+func TestCompressedUploads(t *testing.T) {
+	InitStore()
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd encoder: %v", err)
+	}
+	zstdExport := encoder.EncodeAll([]byte(archiveTestAttributesView), nil)
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Failed to close zstd encoder: %v", err)
+	}
+
+	for name, content := range map[string][]byte{
+		"attributes.csv.gz":  gzipForTest(t, []byte(archiveTestAttributesView)),
+		"attributes.csv.zst": zstdExport,
+	} {
+		t.Run(name, func(t *testing.T) {
+			response := postMergeUpload(t, "/api/upload", map[string]string{name: string(content)})
+			players, _, found := GetPlayerData(response.DatasetID)
+			if !found || len(players) != 2 || players[0].NumericAttributes["Fin"] != 16 {
+				t.Errorf("Expected two parsed players from %s, got %+v", name, players)
+			}
+		})
+	}
+
+	t.Run("zip with several views", func(t *testing.T) {
+		archive := zipForTest(t, map[string][]byte{
+			"views/attributes.csv":       []byte(archiveTestAttributesView),
+			"views/stats.csv":            []byte(archiveTestStatsView),
+			"__MACOSX/views/._stats.csv": []byte("resource fork"),
+			"views/.DS_Store":            []byte("finder metadata"),
+		}, "views/attributes.csv", "views/stats.csv", "__MACOSX/views/._stats.csv", "views/.DS_Store")
+
+		response := postMergeUpload(t, "/api/upload", map[string]string{"save.zip": string(archive)})
+		if response.MergeReport == nil || response.MergeReport.FilesMerged != 2 || response.MergeReport.PlayersMatched != 1 {
+			t.Fatalf("Expected both views to be merged, got %+v", response.MergeReport)
+		}
+		players, _, _ := GetPlayerData(response.DatasetID)
+		if len(players) != 2 || players[0].Attributes["Gls"] != "21" {
+			t.Errorf("Expected attributes and stats on one player, got %+v", players)
+		}
+	})
+}
+
+func TestCompressedUploadRejectsBombs(t *testing.T) {
+	InitStore()
+
+	padding := bytes.Repeat([]byte(" "), 4*decompressionRatioGrace)
+	bomb := append([]byte(archiveTestAttributesView), padding...)
+
+	for name, content := range map[string][]byte{
+		"bomb.csv.gz": gzipForTest(t, bomb),
+		"bomb.zip":    zipForTest(t, map[string][]byte{"bomb.csv": bomb}, "bomb.csv"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			body, contentType := buildMergeUploadBody(t, map[string]string{name: string(content)})
+			req := httptest.NewRequest(http.MethodPost, "/api/upload", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			uploadHandler(w, req)
+
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("Expected status %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Each member fits the 1 MB limit on its own, but the three together do not
+	t.Run("split.zip", func(t *testing.T) {
+		t.Setenv("MAX_UPLOAD_SIZE", "1")
+		member := []byte(archiveTestAttributesView + hex.EncodeToString(bytes.Repeat([]byte("split"), 40<<10)))
+		split := zipForTest(t, map[string][]byte{"a.csv": member, "b.csv": member, "c.csv": member}, "a.csv", "b.csv", "c.csv")
+
+		body, contentType := buildMergeUploadBody(t, map[string]string{"split.zip": string(split)})
+		req := httptest.NewRequest(http.MethodPost, "/api/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		uploadHandler(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+		}
+	})
+
+	empty := zipForTest(t, map[string][]byte{"__MACOSX/._a.csv": []byte("x")}, "__MACOSX/._a.csv")
+	body, contentType := buildMergeUploadBody(t, map[string]string{"empty.zip": string(empty)})
+	req := httptest.NewRequest(http.MethodPost, "/api/upload", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	uploadHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an archive without exports, got %d", http.StatusBadRequest, w.Code)
+	}
+}
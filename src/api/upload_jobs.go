@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	apperrors "api/errors"
+)
+
+// UploadPhase names the pipeline stage an asynchronous upload job is currently in.
+type UploadPhase string
+
+const (
+	UploadPhaseQueued      UploadPhase = "queued"
+	UploadPhaseTokenizing  UploadPhase = "tokenizing"
+	UploadPhaseParsingRows UploadPhase = "parsing_rows"
+	UploadPhaseCalculating UploadPhase = "calculating"
+	UploadPhaseStoring     UploadPhase = "storing"
+	UploadPhasePercentiles UploadPhase = "percentiles"
+	UploadPhaseCompleted   UploadPhase = "completed"
+	UploadPhaseFailed      UploadPhase = "failed"
+)
+
+// uploadJobRetention is how long finished jobs stay queryable before they are pruned.
+const uploadJobRetention = 1 * time.Hour
+
+// UploadJob tracks a single upload running in the background. Row counters reuse
+// WorkerStats and are updated atomically from the results collector; everything
+// else is guarded by mu. All methods are safe to call on a nil job so the
+// synchronous upload path can share the same pipeline without tracking.
+type UploadJob struct {
+	ID         string
+	Filename   string
+	BytesTotal int64
+	CreatedAt  time.Time
+
+	bytesRead int64
+	stats     WorkerStats
+
+	mu         sync.RWMutex
+	phase      UploadPhase
+	finishedAt time.Time
+	errMessage string
+	result     *UploadResponse
+}
+
+// UploadJobStatus is the JSON snapshot returned by the job status endpoint.
+type UploadJobStatus struct {
+	JobID                  string       `json:"jobId"`
+	Filename               string       `json:"filename"`
+	Phase                  UploadPhase  `json:"phase"`
+	Progress               int          `json:"progress"`
+	BytesRead              int64        `json:"bytesRead"`
+	BytesTotal             int64        `json:"bytesTotal"`
+	RowsProcessed          int64        `json:"rowsProcessed"`
+	RowErrors              int64        `json:"rowErrors"`
+	ElapsedMs              int64        `json:"elapsedMs"`
+	Error                  string       `json:"error,omitempty"`
+	DatasetID              string       `json:"datasetId,omitempty"`
+	Message                string       `json:"message,omitempty"`
+	DetectedCurrencySymbol string       `json:"detectedCurrencySymbol,omitempty"`
+	ParseReport            *ParseReport `json:"parseReport,omitempty"`
+	MergeReport            *MergeReport `json:"mergeReport,omitempty"`
+}
+
+// UploadJobResponse is returned by /api/upload when the upload is accepted as a background job.
+type UploadJobResponse struct {
+	JobID     string      `json:"jobId"`
+	Phase     UploadPhase `json:"phase"`
+	StatusURL string      `json:"statusUrl"`
+	Message   string      `json:"message"`
+}
+
+var (
+	uploadJobs      = make(map[string]*UploadJob)
+	uploadJobsMutex sync.RWMutex
+)
+
+// CreateUploadJob registers a new queued job and prunes finished jobs past their retention.
+func CreateUploadJob(filename string, bytesTotal int64) *UploadJob {
+	job := &UploadJob{
+		ID:         uuid.New().String(),
+		Filename:   filename,
+		BytesTotal: bytesTotal,
+		CreatedAt:  time.Now(),
+		phase:      UploadPhaseQueued,
+	}
+
+	uploadJobsMutex.Lock()
+	defer uploadJobsMutex.Unlock()
+	for id, existing := range uploadJobs {
+		if existing.finishedBefore(time.Now().Add(-uploadJobRetention)) {
+			delete(uploadJobs, id)
+		}
+	}
+	uploadJobs[job.ID] = job
+	return job
+}
+
+// GetUploadJob looks up a job by ID.
+func GetUploadJob(jobID string) (*UploadJob, bool) {
+	uploadJobsMutex.RLock()
+	defer uploadJobsMutex.RUnlock()
+	job, found := uploadJobs[jobID]
+	return job, found
+}
+
+// SetPhase moves the job to the given pipeline phase.
+func (j *UploadJob) SetPhase(phase UploadPhase) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.phase == UploadPhaseFailed || j.phase == UploadPhaseCompleted {
+		return
+	}
+	j.phase = phase
+}
+
+// RecordRow counts a row result coming out of the parser workers.
+func (j *UploadJob) RecordRow(err error) {
+	if j == nil {
+		return
+	}
+	if err != nil {
+		atomic.AddInt64(&j.stats.ErrorCount, 1)
+		return
+	}
+	atomic.AddInt64(&j.stats.ProcessedCount, 1)
+}
+
+// TrackReader wraps r so the bytes consumed by the parser are reported as progress.
+func (j *UploadJob) TrackReader(r io.Reader) io.Reader {
+	if j == nil {
+		return r
+	}
+	return &uploadJobProgressReader{source: r, job: j}
+}
+
+// SetResult records the dataset produced by the job; the job keeps running until Complete.
+func (j *UploadJob) SetResult(response UploadResponse) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.result = &response
+}
+
+// Complete marks the job as finished successfully.
+func (j *UploadJob) Complete() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.phase = UploadPhaseCompleted
+	j.finishedAt = time.Now()
+}
+
+// Fail marks the job as failed with a user-facing message.
+func (j *UploadJob) Fail(message string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.phase = UploadPhaseFailed
+	j.errMessage = message
+	j.finishedAt = time.Now()
+}
+
+// Status returns a consistent snapshot of the job for the status endpoint.
+func (j *UploadJob) Status() UploadJobStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	status := UploadJobStatus{
+		JobID:         j.ID,
+		Filename:      j.Filename,
+		Phase:         j.phase,
+		BytesRead:     atomic.LoadInt64(&j.bytesRead),
+		BytesTotal:    j.BytesTotal,
+		RowsProcessed: atomic.LoadInt64(&j.stats.ProcessedCount),
+		RowErrors:     atomic.LoadInt64(&j.stats.ErrorCount),
+		Error:         j.errMessage,
+	}
+
+	end := j.finishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	status.ElapsedMs = end.Sub(j.CreatedAt).Milliseconds()
+	status.Progress = uploadPhaseProgress(j.phase, status.BytesRead, status.BytesTotal)
+
+	if j.result != nil {
+		status.DatasetID = j.result.DatasetID
+		status.Message = j.result.Message
+		status.DetectedCurrencySymbol = j.result.DetectedCurrencySymbol
+		status.ParseReport = j.result.ParseReport
+		status.MergeReport = j.result.MergeReport
+	}
+	return status
+}
+
+func (j *UploadJob) finishedBefore(cutoff time.Time) bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return !j.finishedAt.IsZero() && j.finishedAt.Before(cutoff)
+}
+
+// uploadPhaseProgress maps a phase to an overall percentage. Reading the file dominates
+// the runtime, so tokenizing and row parsing share the first 80% in proportion to the
+// bytes consumed and the remaining phases take fixed steps.
+func uploadPhaseProgress(phase UploadPhase, bytesRead, bytesTotal int64) int {
+	switch phase {
+	case UploadPhaseQueued:
+		return 0
+	case UploadPhaseTokenizing, UploadPhaseParsingRows:
+		if bytesTotal <= 0 {
+			return 0
+		}
+		progress := int(bytesRead * 80 / bytesTotal)
+		if progress > 80 {
+			progress = 80
+		}
+		return progress
+	case UploadPhaseCalculating:
+		return 85
+	case UploadPhaseStoring:
+		return 90
+	case UploadPhasePercentiles:
+		return 95
+	default:
+		return 100
+	}
+}
+
+// uploadJobProgressReader counts bytes for the status endpoint, which reads them from another goroutine.
+type uploadJobProgressReader struct {
+	source io.Reader
+	job    *UploadJob
+}
+
+func (p *uploadJobProgressReader) Read(buf []byte) (int, error) {
+	n, err := p.source.Read(buf)
+	atomic.AddInt64(&p.job.bytesRead, int64(n))
+	return n, err
+}
+
+// isAsyncUploadRequested reports whether the client asked for a background job via ?async=true.
+func isAsyncUploadRequested(r *http.Request) bool {
+	async, err := strconv.ParseBool(r.URL.Query().Get("async"))
+	return err == nil && async
+}
+
+// spoolUploadToTempFile copies the uploaded file part to a temporary file so the request can
+// return before processing finishes. The copy is streamed and bounded by the upload limit.
+func spoolUploadToTempFile(source io.Reader) (string, int64, error) {
+	tempFile, err := os.CreateTemp("", "fm-upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+
+	stream := createUploadStreamReader(source, getMaxUploadSize())
+	_, copyErr := io.Copy(tempFile, stream)
+	closeErr := tempFile.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		if removeErr := os.Remove(tempFile.Name()); removeErr != nil {
+			log.Printf("Failed to remove temporary upload file %s: %v", tempFile.Name(), removeErr)
+		}
+		return "", 0, copyErr
+	}
+	return tempFile.Name(), stream.BytesRead(), nil
+}
+
+// runUploadJob processes a spooled upload in the background and removes the temporary file when done.
+func runUploadJob(job *UploadJob, tempPath string) {
+	defer func() {
+		if removeErr := os.Remove(tempPath); removeErr != nil {
+			log.Printf("Failed to remove temporary upload file %s: %v", tempPath, removeErr)
+		}
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			LogCritical("Upload job %s PANICKED: %v", job.ID, r)
+			job.Fail("Internal error while processing the upload.")
+		}
+	}()
+
+	ctx, span := StartSpan(context.Background(), "upload.job")
+	defer span.End()
+	SetSpanAttributes(ctx,
+		attribute.String("upload.job_id", job.ID),
+		attribute.String("file.name", job.Filename),
+		attribute.Int64("file.size", job.BytesTotal),
+	)
+
+	file, err := os.Open(tempPath) // #nosec G304 -- path comes from os.CreateTemp above
+	if err != nil {
+		RecordError(ctx, err, "Failed to open spooled upload")
+		job.Fail("Error reading the uploaded file.")
+		return
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			RecordError(ctx, closeErr, "Failed to close spooled upload")
+		}
+	}()
+
+	response, duplicate, appErr := processUploadStream(ctx, job.Filename, file, job.BytesTotal, job)
+	if appErr != nil {
+		job.Fail(appErr.Message)
+		return
+	}
+	job.SetResult(response)
+
+	if !duplicate {
+		calculateUploadPercentiles(response.DatasetID, job)
+	}
+	job.Complete()
+
+	logInfo(ctx, "Upload job completed",
+		"job_id", job.ID,
+		"dataset_id", response.DatasetID,
+		"duration_ms", time.Since(job.CreatedAt).Milliseconds())
+}
+
+// startAsyncUpload spools the file part and hands it to a background job, answering 202 with the job ID.
+func startAsyncUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, filename string, source io.Reader) {
+	tempPath, size, err := spoolUploadToTempFile(source)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUploadTooLarge) {
+			appErr := rejectOversizedUpload(ctx, filename)
+			http.Error(w, appErr.Message, appErr.HTTPStatus)
+			return
+		}
+		RecordError(ctx, err, "Failed to spool upload for background processing")
+		http.Error(w, "Error reading the file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job := CreateUploadJob(filename, size)
+	SetSpanAttributes(ctx, attribute.String("upload.job_id", job.ID))
+	logInfo(ctx, "Upload accepted as background job",
+		"job_id", job.ID,
+		"filename", filename,
+		"size_bytes", size)
+
+	go runUploadJob(job, tempPath)
+
+	w.Header().Set("Content-Type", "application/json")
+	setCORSHeaders(w, r)
+	w.WriteHeader(http.StatusAccepted)
+	response := UploadJobResponse{
+		JobID:     job.ID,
+		Phase:     UploadPhaseQueued,
+		StatusURL: "/api/upload-jobs/" + job.ID,
+		Message:   "File accepted for background processing.",
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		RecordError(ctx, err, "Failed to encode upload job response")
+	}
+}
+
+// uploadJobStatusHandler handles GET /api/upload-jobs/{jobId}, reporting phase, progress and row counts.
+func uploadJobStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/upload-jobs/"), "/")
+	if len(pathParts) == 0 || pathParts[0] == "" {
+		http.Error(w, "Job ID is missing in the request path", http.StatusBadRequest)
+		return
+	}
+	jobID := pathParts[0]
+
+	job, found := GetUploadJob(jobID)
+	if !found {
+		logDebug(ctx, "Upload job not found", "job_id", jobID)
+		http.Error(w, "Upload job not found for the given ID.", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	setCORSHeaders(w, r)
+	if err := json.NewEncoder(w).Encode(job.Status()); err != nil {
+		RecordError(ctx, err, "Failed to encode upload job status")
+		http.Error(w, "Error encoding response: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestAsyncUploadJobCompletes(t *testing.T) {
+	InitStore()
+
+	// Unique UID keeps the file from matching an earlier upload's hash
+	csvContent := "Name,Position,Club,Age,Transfer Value,Wage,Division,UID\n" +
+		"Async Player,GK,Async FC,25,£1M,£10K,Test League," + uuid.New().String() + "\n" +
+		",,,,,,,\n" +
+		"Second Player,ST (C),Async FC,21,£2M,£20K,Test League,123\n"
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	fileWriter, err := writer.CreateFormFile(uploadFormField, "async_test.csv")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	if _, err := fileWriter.Write([]byte(csvContent)); err != nil {
+		t.Fatalf("Failed to write test CSV: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/upload?async=true", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	uploadHandler(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+
+	var accepted UploadJobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &accepted); err != nil {
+		t.Fatalf("Failed to parse job response: %v", err)
+	}
+	if accepted.JobID == "" || accepted.StatusURL != "/api/upload-jobs/"+accepted.JobID {
+		t.Fatalf("Unexpected job response: %+v", accepted)
+	}
+
+	var status UploadJobStatus
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		statusReq := httptest.NewRequest("GET", accepted.StatusURL, http.NoBody)
+		statusW := httptest.NewRecorder()
+		uploadJobStatusHandler(statusW, statusReq)
+		if statusW.Code != http.StatusOK {
+			t.Fatalf("Status request failed with %d: %s", statusW.Code, statusW.Body.String())
+		}
+		if err := json.Unmarshal(statusW.Body.Bytes(), &status); err != nil {
+			t.Fatalf("Failed to parse job status: %v", err)
+		}
+		if status.Phase == UploadPhaseCompleted || status.Phase == UploadPhaseFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job did not finish in time, last status: %+v", status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if status.Phase != UploadPhaseCompleted {
+		t.Fatalf("Expected job to complete, got %+v", status)
+	}
+	if status.DatasetID == "" {
+		t.Error("Completed job has no dataset ID")
+	}
+	if status.RowsProcessed != 2 {
+		t.Errorf("RowsProcessed = %d, want 2", status.RowsProcessed)
+	}
+	if status.ParseReport == nil || status.ParseReport.RowsParsed != 2 {
+		t.Errorf("Expected a parse report with 2 parsed rows, got %+v", status.ParseReport)
+	}
+	if status.Progress != 100 {
+		t.Errorf("Progress = %d, want 100", status.Progress)
+	}
+	if status.BytesRead != int64(len(csvContent)) || status.BytesTotal != int64(len(csvContent)) {
+		t.Errorf("BytesRead/BytesTotal = %d/%d, want %d", status.BytesRead, status.BytesTotal, len(csvContent))
+	}
+	if _, _, found := GetPlayerData(status.DatasetID); !found {
+		t.Errorf("Dataset %s from completed job not found in storage", status.DatasetID)
+	}
+}
+
+func TestUploadJobStatusHandlerNotFound(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/upload-jobs/does-not-exist", http.NoBody)
+	w := httptest.NewRecorder()
+	uploadJobStatusHandler(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestUploadPhaseProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		phase      UploadPhase
+		bytesRead  int64
+		bytesTotal int64
+		want       int
+	}{
+		{name: "queued", phase: UploadPhaseQueued, bytesTotal: 100, want: 0},
+		{name: "half parsed", phase: UploadPhaseParsingRows, bytesRead: 50, bytesTotal: 100, want: 40},
+		{name: "unknown size", phase: UploadPhaseTokenizing, bytesRead: 50, want: 0},
+		{name: "overread capped", phase: UploadPhaseParsingRows, bytesRead: 200, bytesTotal: 100, want: 80},
+		{name: "storing", phase: UploadPhaseStoring, want: 90},
+		{name: "failed", phase: UploadPhaseFailed, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uploadPhaseProgress(tt.phase, tt.bytesRead, tt.bytesTotal); got != tt.want {
+				t.Errorf("uploadPhaseProgress() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"mime/multipart"
+
+	apperrors "api/errors"
+)
+
+// multipartOverheadAllowance is the slack granted on top of getMaxUploadSize() for the
+// multipart envelope (boundaries, part headers and small form fields) around the file.
+const multipartOverheadAllowance = 1 << 20
+
+// uploadFormField is the multipart field name the frontend sends the export under.
+const uploadFormField = "playerFile"
+
+// uploadStreamReader wraps an uploaded file part so that every byte the parser
+// consumes is hashed for duplicate detection and counted against the upload limit.
+// Nothing is buffered beyond what the downstream reader asks for.
+type uploadStreamReader struct {
+	source    io.Reader
+	hasher    hash.Hash
+	limit     int64
+	bytesRead int64
+	exceeded  bool
+}
+
+// createUploadStreamReader wraps source, failing reads once more than limit bytes have been seen.
+func createUploadStreamReader(source io.Reader, limit int64) *uploadStreamReader {
+	return &uploadStreamReader{
+		source: source,
+		hasher: sha256.New(),
+		limit:  limit,
+	}
+}
+
+// Read implements io.Reader, hashing and counting the bytes as they pass through.
+func (u *uploadStreamReader) Read(p []byte) (int, error) {
+	if u.exceeded {
+		return 0, apperrors.ErrUploadTooLarge
+	}
+
+	n, err := u.source.Read(p)
+	if n > 0 {
+		u.bytesRead += int64(n)
+		if u.bytesRead > u.limit {
+			u.exceeded = true
+			return 0, apperrors.ErrUploadTooLarge
+		}
+		// hash.Hash.Write never returns an error
+		_, _ = u.hasher.Write(p[:n])
+	}
+	return n, err
+}
+
+// Drain consumes whatever the parser left unread so the hash covers the whole file.
+func (u *uploadStreamReader) Drain() error {
+	_, err := io.Copy(io.Discard, u)
+	return err
+}
+
+// Sum returns the hex-encoded SHA-256 of the bytes read so far, the key used by fileHashToDatasetMap.
+func (u *uploadStreamReader) Sum() string {
+	return hex.EncodeToString(u.hasher.Sum(nil))
+}
+
+// BytesRead returns the number of file bytes streamed so far.
+func (u *uploadStreamReader) BytesRead() int64 {
+	return u.bytesRead
+}
+
+// Exceeded reports whether the stream was cut off for exceeding its size limit.
+func (u *uploadStreamReader) Exceeded() bool {
+	return u.exceeded
+}
+
+// nextUploadFilePart advances a multipart reader to the part carrying the uploaded file,
+// discarding any other form fields it passes on the way.
+func nextUploadFilePart(mr *multipart.Reader, fieldName string) (*multipart.Part, error) {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, apperrors.WrapErrUploadFieldMissing(fieldName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == fieldName && part.FileName() != "" {
+			return part, nil
+		}
+		if _, err := io.Copy(io.Discard, part); err != nil {
+			return nil, err
+		}
+		if closeErr := part.Close(); closeErr != nil {
+			return nil, closeErr
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	apperrors "api/errors"
+)
+
+func TestUploadStreamReaderHashesWholeStream(t *testing.T) {
+	content := strings.Repeat("<tr><td>Player</td></tr>\n", 2000)
+	stream := createUploadStreamReader(strings.NewReader(content), int64(len(content)))
+
+	// Read only part of the stream, as a parser stopping at </table> would
+	head := make([]byte, 100)
+	if _, err := io.ReadFull(stream, head); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if err := stream.Drain(); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+
+	expected := sha256.Sum256([]byte(content))
+	if got := stream.Sum(); got != hex.EncodeToString(expected[:]) {
+		t.Errorf("Sum() = %s, want %s", got, hex.EncodeToString(expected[:]))
+	}
+	if stream.BytesRead() != int64(len(content)) {
+		t.Errorf("BytesRead() = %d, want %d", stream.BytesRead(), len(content))
+	}
+	if stream.Exceeded() {
+		t.Error("Exceeded() = true for a stream exactly at the limit")
+	}
+}
+
+func TestUploadStreamReaderEnforcesLimit(t *testing.T) {
+	content := strings.Repeat("x", 10000)
+	stream := createUploadStreamReader(strings.NewReader(content), 4096)
+
+	_, err := io.Copy(io.Discard, stream)
+	if !errors.Is(err, apperrors.ErrUploadTooLarge) {
+		t.Fatalf("expected ErrUploadTooLarge, got %v", err)
+	}
+	if !stream.Exceeded() {
+		t.Error("Exceeded() = false after crossing the limit")
+	}
+
+	// Further reads keep failing rather than resuming the stream
+	if _, err := stream.Read(make([]byte, 10)); !errors.Is(err, apperrors.ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge on subsequent read, got %v", err)
+	}
+}
+
+func TestNextUploadFilePart(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      map[string]string
+		fileField   string
+		wantErr     bool
+		wantContent string
+	}{
+		{
+			name:        "file after other fields",
+			fields:      map[string]string{"note": "hello"},
+			fileField:   uploadFormField,
+			wantContent: "Name,Age\nTest,20\n",
+		},
+		{
+			name:      "file under wrong field",
+			fields:    map[string]string{"note": "hello"},
+			fileField: "otherFile",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			for key, value := range tt.fields {
+				if err := writer.WriteField(key, value); err != nil {
+					t.Fatalf("failed to write field: %v", err)
+				}
+			}
+			part, err := writer.CreateFormFile(tt.fileField, "players.csv")
+			if err != nil {
+				t.Fatalf("failed to create form file: %v", err)
+			}
+			if _, err := part.Write([]byte("Name,Age\nTest,20\n")); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("failed to close writer: %v", err)
+			}
+
+			filePart, err := nextUploadFilePart(multipart.NewReader(&body, writer.Boundary()), uploadFormField)
+			if tt.wantErr {
+				if !errors.Is(err, apperrors.ErrUploadFieldMissing) {
+					t.Fatalf("expected ErrUploadFieldMissing, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filePart.FileName() != "players.csv" {
+				t.Errorf("FileName() = %q, want %q", filePart.FileName(), "players.csv")
+			}
+			content, err := io.ReadAll(filePart)
+			if err != nil {
+				t.Fatalf("failed to read part: %v", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
@@ -67,6 +67,7 @@ export function usePercentileRetry(player, datasetId, selectedComparisonGroup) {
 
     try {
       const requestPayload = {
+        playerUid: player.value.uid,
         playerName: player.value.name,
         divisionFilter: 'all', // Default to global percentiles
         targetDivision: null
@@ -41,6 +41,42 @@ export default {
     }
   },
 
+  async mergePlayerFiles(files, datasetId = null) {
+    if (!files || files.length === 0) {
+      return Promise.reject(new Error('At least one file is required.'))
+    }
+    const formData = new FormData()
+    for (const file of files) {
+      formData.append('playerFile', file)
+    }
+    const query = datasetId ? `datasetId=${encodeURIComponent(datasetId)}` : 'mode=merge'
+    const response = await fetch(`${API_ENDPOINT}/api/upload?${query}`, {
+      method: 'POST',
+      body: formData
+    })
+    if (!response.ok) {
+      const errorText = await response.text()
+      throw new Error(
+        `API Error merging files: ${response.status} - ${errorText || response.statusText}`
+      )
+    }
+    return await response.json()
+  },
+
+  async getUploadJobStatus(jobId) {
+    if (!jobId) {
+      return Promise.reject(new Error('Job ID is required.'))
+    }
+    const response = await fetch(`${API_ENDPOINT}/api/upload-jobs/${encodeURIComponent(jobId)}`)
+    if (!response.ok) {
+      const errorText = await response.text()
+      throw new Error(
+        `API Error fetching upload job: ${response.status} - ${errorText || response.statusText}`
+      )
+    }
+    return await response.json()
+  },
+
   async getPlayersByDatasetId(
     datasetId,
     position = null,